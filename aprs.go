@@ -0,0 +1,226 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * aprs.go talkkonnects function to send position beacons to aprs-is with smart beaconing on speed and course change
+ */
+
+package talkkonnect
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAPRSPort        = "14580"
+	defaultAPRSSymbolTable = "/"
+	defaultAPRSSymbol      = ">"
+	aprsToCall             = "APZTKK"
+	aprsReceiveOnlyPass    = "-1"
+	metresPerFoot          = 0.3048
+)
+
+func aprsCallsign() string {
+	if Config.Global.Hardware.Traccar.Protocol.Aprs.SSID > 0 {
+		return fmt.Sprintf("%v-%v", strings.ToUpper(Config.Global.Hardware.Traccar.Protocol.Aprs.Callsign), Config.Global.Hardware.Traccar.Protocol.Aprs.SSID)
+	}
+	return strings.ToUpper(Config.Global.Hardware.Traccar.Protocol.Aprs.Callsign)
+}
+
+// aprsBeacon sends a position to aprs-is every interval, or when smart beaconing says the speed or course changed enough
+func aprsBeacon() {
+	interval := traccarInterval()
+	if Config.Global.Hardware.Traccar.Protocol.Aprs.SmartBeaconing.Enabled {
+		interval = time.Second
+	}
+	subscriber := gnssSubscribe("aprs", interval)
+
+	var (
+		conn     net.Conn
+		lastFix  GNSSDataStruct
+		lastSent time.Time
+	)
+
+	for fix := range subscriber.C {
+		if !aprsBeaconDue(fix, lastFix, lastSent) {
+			continue
+		}
+
+		if conn == nil {
+			var err error
+			if conn, err = aprsConnect(); err != nil {
+				log.Println("error: APRS-IS Connection Failed ", err)
+				traccarDisplayStatus("TRACK ERR1", fix)
+				continue
+			}
+		}
+
+		packet := aprsPositionPacket(fix)
+		conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		if _, err := fmt.Fprint(conn, packet+"\r\n"); err != nil {
+			log.Println("error: APRS-IS Beacon Failed ", err)
+			conn.Close()
+			conn = nil
+			traccarDisplayStatus("TRACK ERR1", fix)
+			continue
+		}
+
+		log.Println("debug: APRS-IS Beacon Sent ", packet)
+		lastFix, lastSent = fix, time.Now()
+		traccarDisplayStatus("TRACK OK", fix)
+	}
+}
+
+// aprsBeaconDue is smart beaconing, slow rate below the slow speed, a rate rising with speed up to the fast rate
+// and a corner beacon when the course changes by more than the turn threshold, which shrinks as speed goes up
+func aprsBeaconDue(fix GNSSDataStruct, lastFix GNSSDataStruct, lastSent time.Time) bool {
+	if lastSent.IsZero() {
+		return true
+	}
+	elapsed := time.Since(lastSent)
+
+	smart := Config.Global.Hardware.Traccar.Protocol.Aprs.SmartBeaconing
+	if !smart.Enabled {
+		return elapsed >= traccarInterval()
+	}
+
+	speed := fix.Speed * 1.852
+	if speed < smart.SlowSpeed {
+		return elapsed >= time.Duration(smart.SlowRateSecs)*time.Second
+	}
+
+	rate := time.Duration(smart.FastRateSecs) * time.Second
+	if speed < smart.FastSpeed {
+		rate = time.Duration(float64(rate) * smart.FastSpeed / speed)
+	}
+	if elapsed >= rate {
+		return true
+	}
+
+	turn := math.Abs(fix.Course - lastFix.Course)
+	if turn > 180 {
+		turn = 360 - turn
+	}
+	return turn > smart.MinTurnAngle+smart.TurnSlope/speed && elapsed >= time.Duration(smart.MinTurnSecs)*time.Second
+}
+
+// aprsConnect logs in to the aprs-is server, a receive only passcode is used when none is set
+func aprsConnect() (net.Conn, error) {
+	aprs := Config.Global.Hardware.Traccar.Protocol.Aprs
+	port := aprs.Port
+	if len(port) == 0 {
+		port = defaultAPRSPort
+	}
+	passcode := aprs.Passcode
+	if len(passcode) == 0 {
+		passcode = aprsReceiveOnlyPass
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(aprs.Server, port), 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	reader := bufio.NewReader(conn)
+	banner, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	log.Println("debug: APRS-IS Server ", strings.TrimSpace(banner))
+
+	if _, err := fmt.Fprintf(conn, "user %v pass %v vers talkkonnect %v\r\n", aprsCallsign(), passcode, talkkonnectVersion); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	response = strings.TrimSpace(response)
+	if strings.Contains(response, "unverified") {
+		log.Println("warn: APRS-IS Login Unverified Beacons Will Not Be Gated ", response)
+	} else {
+		log.Println("info: APRS-IS Login ", response)
+	}
+	conn.SetDeadline(time.Time{})
+
+	// the server keeps sending comments and keepalives that are not needed, closing when it hangs up makes the next beacon reconnect
+	go func() {
+		io.Copy(ioutil.Discard, reader)
+		conn.Close()
+	}()
+	return conn, nil
+}
+
+// aprsPositionPacket builds an uncompressed position report with course, speed and altitude
+func aprsPositionPacket(fix GNSSDataStruct) string {
+	aprs := Config.Global.Hardware.Traccar.Protocol.Aprs
+	table := aprs.SymbolTable
+	if len(table) == 0 {
+		table = defaultAPRSSymbolTable
+	}
+	symbol := aprs.Symbol
+	if len(symbol) == 0 {
+		symbol = defaultAPRSSymbol
+	}
+
+	course := int(math.Round(fix.Course))
+	if course <= 0 || course > 360 {
+		course = 360
+	}
+
+	return fmt.Sprintf("%v>%v,TCPIP*:!%v%v%v%v%03d/%03d/A=%06d%v",
+		aprsCallsign(), aprsToCall,
+		aprsCoordinate(fix.Lattitude, 2, "N", "S"), table[:1],
+		aprsCoordinate(fix.Longitude, 3, "E", "W"), symbol[:1],
+		course, int(math.Round(fix.Speed)), int(math.Round(fix.Altitude/metresPerFoot)), aprs.Comment)
+}
+
+// aprsCoordinate formats degrees as ddmm.mmN or dddmm.mmE
+func aprsCoordinate(value float64, degreeDigits int, positive string, negative string) string {
+	hemisphere := positive
+	if value < 0 {
+		hemisphere = negative
+	}
+	value = math.Abs(value)
+	degrees := math.Floor(value)
+	minutes := math.Round((value-degrees)*60*100) / 100
+	if minutes >= 60 {
+		degrees++
+		minutes -= 60
+	}
+	return fmt.Sprintf("%0*d%05.2f%v", degreeDigits, int(degrees), minutes, hemisphere)
+}
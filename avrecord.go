@@ -145,6 +145,7 @@ func audiorecordtraffic() {
 		cmd.Dir = Config.Global.Hardware.AudioRecordFunction.RecordSavePath
 		err := cmd.Start()
 		check(err)
+		recordingState("Traffic", true)
 		done := make(chan struct{})
 
 		time.Sleep(time.Duration(Config.Global.Hardware.AudioRecordFunction.RecordTimeout) * time.Second) // let sox record for a time, then send kill signal
@@ -166,6 +167,7 @@ func audiorecordtraffic() {
 		}()
 		cmd.Process.Kill()
 		<-done
+		recordingState("Traffic", false)
 
 	} else { // if AudioRecordTimeout is zero? Just keep recording until there is disk space on media.
 
@@ -177,6 +179,7 @@ func audiorecordtraffic() {
 		cmd.Dir = Config.Global.Hardware.AudioRecordFunction.RecordSavePath
 		err := cmd.Start()
 		check(err)
+		recordingState("Traffic", true)
 		time.Sleep(2 * time.Second)
 
 		emptydirchk, err := dirIsEmpty(Config.Global.Hardware.AudioRecordFunction.RecordSavePath) // If sox didn't start recording for wrong parameters or any reason...  No  file.
@@ -251,6 +254,7 @@ func audiorecordambient() {
 		cmd.Dir = Config.Global.Hardware.AudioRecordFunction.RecordSavePath // save audio recording
 		err := cmd.Start()
 		check(err)
+		recordingState("Mic", true)
 		done := make(chan struct{})
 		time.Sleep(time.Duration(Config.Global.Hardware.AudioRecordFunction.RecordMicTimeout) * time.Second) // let sox record for a time, then signal kill
 
@@ -271,6 +275,7 @@ func audiorecordambient() {
 			log.Println("info: sox Stopped Recording Traffic to", Config.Global.Hardware.AudioRecordFunction.RecordSavePath)
 		}()
 		cmd.Process.Kill()
+		recordingState("Mic", false)
 	} else {
 		audrecfile := time.Now().Format("20060102150405") + "." + Config.Global.Hardware.AudioRecordFunction.RecordFileFormat // mp3, wav
 
@@ -280,6 +285,7 @@ func audiorecordambient() {
 		cmd.Dir = Config.Global.Hardware.AudioRecordFunction.RecordSavePath // save audio recording to dir
 		err := cmd.Start()
 		check(err)
+		recordingState("Mic", true)
 
 		emptydirchk, err := dirIsEmpty(Config.Global.Hardware.AudioRecordFunction.RecordSavePath) // If sox didn't start recording for wrong parameters or any reason...  No file.
 
@@ -354,6 +360,7 @@ func audiorecordcombo() {
 		cmd.Dir = Config.Global.Hardware.AudioRecordFunction.RecordSavePath
 		err := cmd.Start()
 		check(err)
+		recordingState("Combo", true)
 		done := make(chan struct{})
 
 		time.Sleep(time.Duration(Config.Global.Hardware.AudioRecordFunction.RecordTimeout) * time.Second) // let sox record for a time, then send kill signal
@@ -376,6 +383,7 @@ func audiorecordcombo() {
 		}()
 		cmd.Process.Kill()
 		<-done
+		recordingState("Combo", false)
 
 	} else { // if AudioRecordTimeout is zero? Just keep recording until there is disk space on media.
 
@@ -387,6 +395,7 @@ func audiorecordcombo() {
 		cmd.Dir = Config.Global.Hardware.AudioRecordFunction.RecordSavePath
 		err := cmd.Start()
 		check(err)
+		recordingState("Combo", true)
 		time.Sleep(2 * time.Second)
 
 		emptydirchk, err := dirIsEmpty(Config.Global.Hardware.AudioRecordFunction.RecordSavePath) // If sox didn't start recording for wrong parameters or any reason...  No files.
@@ -414,3 +423,27 @@ func audiorecordcombo() {
 		}
 	}
 }
+
+var recordingActive bool
+
+// AudioRecordStop stops any running sox recording
+func AudioRecordStop() {
+	_, err := exec.Command("sh", "-c", "killall -SIGINT sox").Output()
+	if err != nil {
+		log.Println("debug: No sox Instance is Running to Stop")
+	} else {
+		log.Println("info: sox Recording Stopped")
+	}
+	recordingState("", false)
+}
+
+// recordingState shows an active recording on the led patterns and the recording display widget
+func recordingState(mode string, active bool) {
+	recordingActive = active
+	ledPatternState("recording", active)
+	if active {
+		displayWidgetSet("recording", mode+" Recording")
+	} else {
+		displayWidgetSet("recording", "")
+	}
+}
@@ -0,0 +1,344 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * chatcommand.go talkkonnects function to take commands from authorised users in mumble private messages
+ */
+
+package talkkonnect
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/talkkonnect/gumble/gumble"
+	"github.com/talkkonnect/volume-go"
+)
+
+const defaultChatCommandPrefix = "!tk"
+
+var chatCommands = []string{"help", "status", "channel", "volume", "record", "announce", "voicetarget", "gps", "where", "reboot"}
+
+var (
+	chatCommandGroups      = map[string]map[uint32]bool{}
+	chatCommandGroupsMutex sync.Mutex
+)
+
+func chatCommandPrefix() string {
+	if len(Config.Global.Software.RemoteControl.Mumble.Prefix) > 0 {
+		return Config.Global.Software.RemoteControl.Mumble.Prefix
+	}
+	return defaultChatCommandPrefix
+}
+
+// chatCommand handles a private message starting with the prefix and reports whether it was one, so it is not
+// shown or spoken as an ordinary message
+func (b *Talkkonnect) chatCommand(e *gumble.TextMessageEvent) bool {
+	if !Config.Global.Software.RemoteControl.Mumble.Enabled || e.Sender == nil || len(e.Users) == 0 {
+		return false
+	}
+
+	text := strings.TrimSpace(esc(e.Message))
+	prefix := chatCommandPrefix()
+	if !chatCommandHasPrefix(text, prefix) {
+		return false
+	}
+
+	if !chatCommandAuthorised(e.Sender) {
+		log.Printf("warn: Chat Command From Unauthorised User %v Ignored\n", e.Sender.Name)
+		e.Sender.Send("not authorised")
+		if len(Config.Global.Software.RemoteControl.Mumble.Authorise.Group) > 0 {
+			b.chatCommandRequestGroups()
+		}
+		return true
+	}
+
+	fields := strings.Fields(text[len(prefix):])
+	action := "help"
+	if len(fields) > 0 {
+		action = strings.ToLower(fields[0])
+		fields = fields[1:]
+	}
+
+	if !chatCommandEnabled(action) {
+		e.Sender.Send(fmt.Sprintf("command %v not available, send %v help", action, prefix))
+		return true
+	}
+
+	log.Printf("info: Chat Command %v %v From %v\n", action, strings.Join(fields, " "), e.Sender.Name)
+	if action == "where" {
+		// where waits for the comments it asks the server for, which this event handler would hold back
+		sender := e.Sender
+		go func() {
+			sender.Send(b.chatCommandRun(action, fields))
+		}()
+		return true
+	}
+	e.Sender.Send(b.chatCommandRun(action, fields))
+	return true
+}
+
+// chatCommandHasPrefix is true when the text starts with the prefix on its own, so !tkfoo is not taken for !tk foo
+func chatCommandHasPrefix(text string, prefix string) bool {
+	if !strings.HasPrefix(strings.ToLower(text), strings.ToLower(prefix)) {
+		return false
+	}
+	rest := text[len(prefix):]
+	return len(rest) == 0 || unicode.IsSpace([]rune(rest)[0])
+}
+
+func chatCommandEnabled(action string) bool {
+	if action == "help" {
+		return true
+	}
+	for _, command := range Config.Global.Software.RemoteControl.Mumble.Command {
+		if command.Action == action {
+			return command.Enabled
+		}
+	}
+	return false
+}
+
+// chatCommandAuthorised checks the sender against the registered names, certificate hashes and groups in the config
+func chatCommandAuthorised(sender *gumble.User) bool {
+	authorise := Config.Global.Software.RemoteControl.Mumble.Authorise
+
+	if sender.IsRegistered() {
+		for _, name := range authorise.User {
+			if strings.EqualFold(name, sender.Name) {
+				return true
+			}
+		}
+	}
+
+	for _, hash := range authorise.CertHash {
+		if len(sender.Hash) > 0 && strings.EqualFold(hash, sender.Hash) {
+			return true
+		}
+	}
+
+	if sender.IsRegistered() {
+		chatCommandGroupsMutex.Lock()
+		defer chatCommandGroupsMutex.Unlock()
+		for _, group := range authorise.Group {
+			if chatCommandGroups[group][sender.UserID] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// chatCommandRequestGroups asks the server for the root channel acl, the groups arrive in OnACL. The server only
+// answers when this account may edit the acl
+func (b *Talkkonnect) chatCommandRequestGroups() {
+	if !IsConnected || len(Config.Global.Software.RemoteControl.Mumble.Authorise.Group) == 0 {
+		return
+	}
+	if root := b.Client.Channels[0]; root != nil {
+		root.RequestACL()
+	}
+}
+
+func chatCommandUpdateGroups(acl *gumble.ACL) {
+	chatCommandGroupsMutex.Lock()
+	defer chatCommandGroupsMutex.Unlock()
+
+	for _, group := range acl.Groups {
+		members := map[uint32]bool{}
+		for id := range group.UsersInherited {
+			members[id] = true
+		}
+		for id := range group.UsersAdd {
+			members[id] = true
+		}
+		for id := range group.UsersRemove {
+			delete(members, id)
+		}
+		chatCommandGroups[group.Name] = members
+		log.Printf("debug: Chat Command Group %v Has %v Members\n", group.Name, len(members))
+	}
+}
+
+func (b *Talkkonnect) chatCommandRun(action string, args []string) string {
+	switch action {
+	case "help":
+		var available []string
+		for _, command := range chatCommands {
+			if chatCommandEnabled(command) {
+				available = append(available, command)
+			}
+		}
+		return "commands: " + strings.Join(available, ", ")
+
+	case "status":
+		status := fmt.Sprintf("%v on %v channel %v with %v users", b.Client.Self.Name, b.Name, b.Client.Self.Channel.Name, len(b.Client.Self.Channel.Users))
+		if current, err := volume.GetVolume(Config.Global.Software.Settings.OutputVolControlDevice); err == nil {
+			status += fmt.Sprintf(", volume %v%%", current)
+		}
+		if b.IsTransmitting {
+			status += ", transmitting"
+		}
+		if recordingActive {
+			status += ", recording"
+		}
+		return status
+
+	case "channel":
+		if len(args) == 0 {
+			return "channel " + b.Client.Self.Channel.Name
+		}
+		name := strings.Join(args, " ")
+		if b.Client.Channels.Find(name) == nil {
+			return "channel " + name + " not found"
+		}
+		b.ChangeChannel(name)
+		return "joined channel " + name
+
+	case "volume":
+		if len(args) > 0 {
+			switch args[0] {
+			case "up":
+				b.cmdVolumeUp()
+			case "down":
+				b.cmdVolumeDown()
+			default:
+				level, err := strconv.Atoi(args[0])
+				if err != nil || level < 0 || level > 100 {
+					return "volume must be up, down or 0 to 100"
+				}
+				if current, err := volume.GetVolume(Config.Global.Software.Settings.OutputVolControlDevice); err == nil {
+					if err := volume.IncreaseVolume(level-current, Config.Global.Software.Settings.OutputVolControlDevice); err != nil {
+						return "volume change failed " + err.Error()
+					}
+				}
+			}
+		}
+		current, err := volume.GetVolume(Config.Global.Software.Settings.OutputVolControlDevice)
+		if err != nil {
+			return "volume unknown " + err.Error()
+		}
+		return fmt.Sprintf("volume %v%%", current)
+
+	case "record":
+		if len(args) == 0 {
+			if recordingActive {
+				return "recording"
+			}
+			return "not recording"
+		}
+		switch args[0] {
+		case "start":
+			switch Config.Global.Hardware.AudioRecordFunction.RecordMode {
+			case "traffic":
+				b.cmdAudioTrafficRecord()
+			case "ambient":
+				b.cmdAudioMicRecord()
+			case "combo":
+				b.cmdAudioMicTrafficRecord()
+			default:
+				return "recording not configured"
+			}
+			return "recording started"
+		case "stop":
+			AudioRecordStop()
+			return "recording stopped"
+		}
+		return "record must be start or stop"
+
+	case "announce":
+		if len(args) == 0 {
+			return "announce needs a message"
+		}
+		go b.TTSPlayerMessage(strings.Join(args, " "), true, false)
+		return "announcing " + strings.Join(args, " ")
+
+	case "voicetarget":
+		if len(args) == 0 {
+			return "voicetarget needs a target number or name"
+		}
+		target, found := findVoiceTarget(strings.Join(args, " "))
+		if !found {
+			return "voicetarget " + strings.Join(args, " ") + " not found"
+		}
+		b.cmdSendVoiceTargets(target)
+		return fmt.Sprintf("voicetarget %v set", target)
+
+	case "gps":
+		if !Config.Global.Hardware.GPS.Enabled {
+			return "gps not enabled"
+		}
+		fix, fresh := gnssLatest()
+		if fix.Lattitude == 0 && fix.Longitude == 0 {
+			return "gps has no fix"
+		}
+		position := fmt.Sprintf("lat %.6f lon %.6f http://www.google.com/maps/place/%.6f,%.6f", fix.Lattitude, fix.Longitude, fix.Lattitude, fix.Longitude)
+		if !fresh {
+			position += fmt.Sprintf(" (stale, %v old)", fix.age().Round(time.Second))
+		}
+		return position
+
+	case "where":
+		users, err := b.positionShareReport()
+		if err != nil {
+			return "where failed, " + err.Error()
+		}
+		return strings.Replace(positionShareText(users), "\n", "<br/>", -1)
+
+	case "reboot":
+		go func() {
+			if err := exec.Command("reboot").Run(); err != nil {
+				log.Println("error: Chat Command Reboot Failed ", err)
+			}
+		}()
+		return "rebooting"
+	}
+	return "command " + action + " not defined"
+}
+
+// findVoiceTarget looks up a voice target of the current account by its number or name
+func findVoiceTarget(target string) (uint32, bool) {
+	var index int
+	for _, account := range Config.Accounts.Account {
+		if !account.Default {
+			continue
+		}
+		if index == AccountIndex {
+			for _, id := range account.Voicetargets.ID {
+				if strconv.Itoa(int(id.Value)) == target || strings.EqualFold(id.Name, target) {
+					return id.Value, true
+				}
+			}
+		}
+		index++
+	}
+	return 0, false
+}
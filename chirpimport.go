@@ -0,0 +1,422 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * chirpimport.go talkkonnects function to import radio channel plans from chirp csv exports
+ */
+
+package talkkonnect
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// the sa818, sa868 and dra818 come as vhf or uhf variants, without a range in the config the vhf one is assumed
+const (
+	defaultChirpMinFreq  = 134.0
+	defaultChirpMaxFreq  = 174.0
+	chirpMaxUploadBytes  = 1 << 20
+	chirpDefaultSquelch  = 1
+	chirpDefaultVolume   = 8
+	chirpChannelsIndent  = "          "
+	chirpFrequencyFormat = "%.4f"
+)
+
+type chirpRejectStruct struct {
+	Row      int
+	Location string
+	Reason   string
+}
+
+type chirpImportStruct struct {
+	Channels []radioChannelsStruct
+	Skipped  []string
+	Rejected []chirpRejectStruct
+}
+
+// chirpImport reads a chirp csv export, the columns are found by their header names as older chirp versions have fewer of them
+func chirpImport(reader io.Reader, minFreq float64, maxFreq float64) (chirpImportStruct, error) {
+	var result chirpImportStruct
+
+	records, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		return result, err
+	}
+	if len(records) < 2 {
+		return result, errors.New("no channels found, expected a chirp csv export with a header row")
+	}
+
+	columns := map[string]int{}
+	for index, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = index
+	}
+	for _, name := range []string{"location", "name", "frequency", "duplex", "offset", "tone", "rtonefreq", "ctonefreq", "dtcscode", "mode"} {
+		if _, found := columns[name]; !found {
+			return result, fmt.Errorf("column %v missing, expected a chirp csv export", name)
+		}
+	}
+	field := func(record []string, name string) string {
+		if index, found := columns[name]; found && index < len(record) {
+			return strings.TrimSpace(record[index])
+		}
+		return ""
+	}
+
+	seen := map[string]bool{}
+	for row, record := range records[1:] {
+		location := field(record, "location")
+		channel, err := chirpChannel(field, record, minFreq, maxFreq)
+		if err == nil && seen[channel.ID] {
+			err = fmt.Errorf("location %v used twice", location)
+		}
+		if err != nil {
+			// rows count from 1 with the header as row 1, like a spreadsheet shows them
+			result.Rejected = append(result.Rejected, chirpRejectStruct{row + 2, location, err.Error()})
+			continue
+		}
+		seen[channel.ID] = true
+		result.Channels = append(result.Channels, channel)
+		if strings.EqualFold(field(record, "skip"), "S") {
+			result.Skipped = append(result.Skipped, channel.ID)
+		}
+	}
+	return result, nil
+}
+
+// chirpChannel converts one csv row, a zero minFreq or maxFreq leaves that end of the range unchecked
+func chirpChannel(field func([]string, string) string, record []string, minFreq float64, maxFreq float64) (radioChannelsStruct, error) {
+	var channel radioChannelsStruct
+
+	location, err := strconv.Atoi(field(record, "location"))
+	if err != nil || location < 0 {
+		return channel, fmt.Errorf("location %v not a number", field(record, "location"))
+	}
+	channel.ID = fmt.Sprintf("%02d", location)
+	channel.Name = field(record, "name")
+	if len(channel.Name) == 0 {
+		channel.Name = "chirp-" + channel.ID
+	}
+
+	switch mode := field(record, "mode"); mode {
+	case "FM":
+		channel.Bandwidth = 1
+	case "NFM":
+		channel.Bandwidth = 0
+	default:
+		return channel, fmt.Errorf("mode %v not supported, only FM and NFM", mode)
+	}
+
+	rxfreq, err := strconv.ParseFloat(field(record, "frequency"), 64)
+	if err != nil || rxfreq <= 0 {
+		return channel, fmt.Errorf("frequency %v not valid", field(record, "frequency"))
+	}
+	txfreq := rxfreq
+	offset, _ := strconv.ParseFloat(field(record, "offset"), 64)
+	switch duplex := field(record, "duplex"); duplex {
+	case "":
+	case "+":
+		txfreq = rxfreq + offset
+	case "-":
+		txfreq = rxfreq - offset
+	case "split":
+		txfreq = offset
+	default:
+		return channel, fmt.Errorf("duplex %v not supported", duplex)
+	}
+	for _, freq := range []float64{rxfreq, txfreq} {
+		if (minFreq > 0 && freq < minFreq) || (maxFreq > 0 && freq > maxFreq) {
+			return channel, fmt.Errorf("frequency "+chirpFrequencyFormat+"MHz outside the module range "+chirpFrequencyFormat+" to "+chirpFrequencyFormat+"MHz", freq, minFreq, maxFreq)
+		}
+	}
+	channel.Rxfreq = float32(rxfreq)
+	channel.Txfreq = float32(txfreq)
+
+	// the module has one tone for transmit and receive so tone and tsql both end up as the ctcss tone
+	switch tone := field(record, "tone"); tone {
+	case "":
+	case "Tone", "TSQL":
+		name := "rtonefreq"
+		if tone == "TSQL" {
+			name = "ctonefreq"
+		}
+		channel.Ctcsstone, err = chirpCTCSS(field(record, name))
+		if err != nil {
+			return channel, err
+		}
+	case "DTCS":
+		if polarity := field(record, "dtcspolarity"); len(polarity) > 0 && polarity != "NN" {
+			return channel, fmt.Errorf("dcs polarity %v not supported, only NN", polarity)
+		}
+		channel.Dcstone, err = chirpDCS(field(record, "dtcscode"))
+		if err != nil {
+			return channel, err
+		}
+	default:
+		return channel, fmt.Errorf("tone mode %v not supported", tone)
+	}
+
+	switch power := strings.ToLower(field(record, "power")); {
+	case strings.HasPrefix(power, "low"):
+		channel.TXPower = "low"
+	case strings.HasPrefix(power, "high"):
+		channel.TXPower = "high"
+	}
+	channel.Squelch = chirpDefaultSquelch
+	channel.Volume = chirpDefaultVolume
+	return channel, nil
+}
+
+// chirpCTCSS gives the sa818 ctcss number of a tone in Hz
+func chirpCTCSS(text string) (int, error) {
+	tone, err := strconv.ParseFloat(text, 64)
+	if err == nil {
+		for number, hz := range ctcssTones {
+			if number > 0 && math.Abs(hz-tone) < 0.05 {
+				return number, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("ctcss tone %v not one the module supports", text)
+}
+
+// chirpDCS checks the code is octal as chirp writes them, 023 goes into the config as 23
+func chirpDCS(text string) (int, error) {
+	if _, err := strconv.ParseUint(text, 8, 16); err != nil || len(text) > 3 {
+		return 0, fmt.Errorf("dcs code %v not valid", text)
+	}
+	return strconv.Atoi(text)
+}
+
+// chirpChannelsXML renders the channels element as it sits inside the sa818 section of the config
+func chirpChannelsXML(channels []radioChannelsStruct) string {
+	var text bytes.Buffer
+	text.WriteString(chirpChannelsIndent + "<channels>\n")
+	for _, channel := range channels {
+		var name bytes.Buffer
+		xml.EscapeText(&name, []byte(channel.Name))
+		fmt.Fprintf(&text, "%v  <channel id=\"%v\" name=\"%v\" enabled=\"true\">\n", chirpChannelsIndent, channel.ID, name.String())
+		for _, element := range [][2]string{
+			{"bandwidth", strconv.Itoa(channel.Bandwidth)},
+			{"rxfreq", fmt.Sprintf(chirpFrequencyFormat, channel.Rxfreq)},
+			{"txfreq", fmt.Sprintf(chirpFrequencyFormat, channel.Txfreq)},
+			{"squelch", strconv.Itoa(channel.Squelch)},
+			{"ctcsstone", strconv.Itoa(channel.Ctcsstone)},
+			{"dcstone", strconv.Itoa(channel.Dcstone)},
+			{"predeemph", strconv.Itoa(channel.Predeemph)},
+			{"highpass", strconv.Itoa(channel.Highpass)},
+			{"lowpass", strconv.Itoa(channel.Lowpass)},
+			{"volume", strconv.Itoa(channel.Volume)},
+			{"txpower", channel.TXPower},
+		} {
+			if len(element[1]) > 0 {
+				fmt.Fprintf(&text, "%v    <%v>%v</%v>\n", chirpChannelsIndent, element[0], element[1], element[0])
+			}
+		}
+		text.WriteString(chirpChannelsIndent + "  </channel>\n")
+	}
+	text.WriteString(chirpChannelsIndent + "</channels>\n")
+	return text.String()
+}
+
+// chirpImportReport lists what was imported and every rejected row with the reason
+func chirpImportReport(result chirpImportStruct) string {
+	var text bytes.Buffer
+	fmt.Fprintf(&text, "Imported %v Channels, Rejected %v Rows\n", len(result.Channels), len(result.Rejected))
+	for _, reject := range result.Rejected {
+		fmt.Fprintf(&text, "Rejected Row %v Location %v: %v\n", reject.Row, reject.Location, reject.Reason)
+	}
+	if len(result.Skipped) > 0 {
+		fmt.Fprintf(&text, "Marked Skip in CHIRP, Add To The Radio Scan Lockout: %v\n", strings.Join(result.Skipped, " "))
+	}
+	return text.String()
+}
+
+// chirpWriteConfig swaps the channels element of the sa818 section in the config file for the imported one and keeps
+// the old file as .bak, the rest of the file and its comments are left as they are
+func chirpWriteConfig(file string, channels []radioChannelsStruct) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	contents, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	text := string(contents)
+
+	section := strings.Index(text, "<sa818")
+	if section < 0 {
+		return errors.New("no sa818 section in " + file)
+	}
+	start := strings.Index(text[section:], "<channels>")
+	end := strings.Index(text[section:], "</channels>")
+	if start < 0 || end < start {
+		return errors.New("no channels in the sa818 section of " + file)
+	}
+	start = strings.LastIndex(text[:section+start], "\n") + 1
+	end = section + end + len("</channels>")
+	if end < len(text) && text[end] == '\n' {
+		end++
+	}
+
+	// the config can hold passwords, so the backup gets its mode, an older backup goes first as it would keep its own
+	if err := os.Remove(file + ".bak"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := ioutil.WriteFile(file+".bak", contents, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, []byte(text[:start]+chirpChannelsXML(channels)+text[end:]), info.Mode().Perm())
+}
+
+// chirpFrequencyRange is the range in the import section of the radio config, rigctld radios are not range checked
+func chirpFrequencyRange() (float64, float64) {
+	minFreq := float64(Config.Global.Hardware.Radio.Import.MinFreq)
+	maxFreq := float64(Config.Global.Hardware.Radio.Import.MaxFreq)
+	if minFreq == 0 && maxFreq == 0 && Config.Global.Hardware.Radio.Driver != "rigctld" {
+		return defaultChirpMinFreq, defaultChirpMaxFreq
+	}
+	return minFreq, maxFreq
+}
+
+// ImportChirp is the importchirp subcommand, it prints the channels from a chirp csv or writes them into the config with -write
+func ImportChirp(args []string) int {
+	flags := flag.NewFlagSet("importchirp", flag.ContinueOnError)
+	config := flags.String("config", "/home/talkkonnect/gocode/src/github.com/talkkonnect/talkkonnect/talkkonnect.xml", "full path to talkkonnect.xml configuration file")
+	write := flags.Bool("write", false, "replace the sa818 channels in the configuration file, the old file is kept as .bak")
+	minFreq := flags.Float64("minfreq", 0, "lowest frequency in MHz the radio module takes, from the configuration file when not given")
+	maxFreq := flags.Float64("maxfreq", 0, "highest frequency in MHz the radio module takes, from the configuration file when not given")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: talkkonnect importchirp [-config=talkkonnect.xml] [-write] [-minfreq=n] [-maxfreq=n] chirp.csv")
+		return 2
+	}
+
+	if contents, err := ioutil.ReadFile(*config); err == nil {
+		if err := xml.Unmarshal(contents, &Config); err != nil {
+			fmt.Fprintf(os.Stderr, "error: Cannot Parse %v %v\n", *config, err)
+			return 1
+		}
+	} else if *write {
+		fmt.Fprintf(os.Stderr, "error: Cannot Read %v %v\n", *config, err)
+		return 1
+	}
+	if *minFreq == 0 && *maxFreq == 0 {
+		*minFreq, *maxFreq = chirpFrequencyRange()
+	}
+
+	csvFile, err := os.Open(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	defer csvFile.Close()
+
+	result, err := chirpImport(csvFile, *minFreq, *maxFreq)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v %v\n", flags.Arg(0), err)
+		return 1
+	}
+	fmt.Fprint(os.Stderr, chirpImportReport(result))
+	if len(result.Channels) == 0 {
+		return 1
+	}
+
+	if !*write {
+		fmt.Print(chirpChannelsXML(result.Channels))
+		return 0
+	}
+	if err := chirpWriteConfig(*config, result.Channels); err != nil {
+		fmt.Fprintf(os.Stderr, "error: Cannot Write Channels To %v %v\n", *config, err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "info: Wrote %v Channels To %v, Restart talkkonnect To Use Them\n", len(result.Channels), *config)
+	return 0
+}
+
+// httpChirpImport takes a chirp csv posted as the body or as the file field of a form, answers with the report and the
+// channels and with write=true puts them in the config when the import section allows it
+func httpChirpImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "405 error: post a chirp csv export", http.StatusMethodNotAllowed)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, chirpMaxUploadBytes)
+
+	var reader io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "400 error: form needs the csv in the file field", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	minFreq, maxFreq := chirpFrequencyRange()
+	result, err := chirpImport(reader, minFreq, maxFreq)
+	if err != nil {
+		http.Error(w, "400 error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("info: CHIRP Import From %v %v Channels %v Rejected\n", r.RemoteAddr, len(result.Channels), len(result.Rejected))
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if r.URL.Query().Get("write") == "true" {
+		if !Config.Global.Hardware.Radio.Import.Write {
+			http.Error(w, "403 error: writing the config is not allowed by the import section", http.StatusForbidden)
+			return
+		}
+		if len(result.Channels) == 0 {
+			http.Error(w, "400 error: no channels to write\n"+chirpImportReport(result), http.StatusBadRequest)
+			return
+		}
+		if err := chirpWriteConfig(ConfigXMLFile, result.Channels); err != nil {
+			log.Println("error: CHIRP Import Cannot Write Config ", err)
+			http.Error(w, "500 error: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Printf("info: CHIRP Import Wrote %v Channels To %v\n", len(result.Channels), ConfigXMLFile)
+		fmt.Fprintf(w, "Wrote %v Channels To %v, Restart talkkonnect To Use Them\n", len(result.Channels), ConfigXMLFile)
+	}
+	fmt.Fprint(w, chirpImportReport(result))
+	fmt.Fprint(w, chirpChannelsXML(result.Channels))
+}
@@ -228,6 +228,17 @@ func Init(file string, ServerIndex string) {
 	if Config.Global.Software.RemoteControl.HTTP.Enabled && !HTTPServRunning {
 		go func() {
 			http.HandleFunc("/", b.httpAPI)
+			if gpioSimulated() {
+				http.HandleFunc("/gpio", httpGPIOSim)
+				http.HandleFunc("/gpio/ws", httpGPIOSimWebSocket)
+				http.HandleFunc("/gpio/panel", httpGPIOSimPanel)
+			}
+			if Config.Global.Hardware.TrackLog.Enabled {
+				http.HandleFunc("/track", httpTrackDownload)
+			}
+			if Config.Global.Hardware.Radio.Import.Enabled {
+				http.HandleFunc("/radio/import", httpChirpImport)
+			}
 			if err := http.ListenAndServe(":"+Config.Global.Software.RemoteControl.HTTP.ListenPort, nil); err != nil {
 				FatalCleanUp("Problem Starting HTTP API Server " + err.Error())
 			}
@@ -254,7 +265,7 @@ func (b *Talkkonnect) ClientStart() {
 		FatalCleanUp("Problem Opening talkkonnect.log file " + err.Error())
 	}
 
-	if Config.Global.Hardware.TargetBoard == "rpi" {
+	if targetBoardHasGPIO() {
 		GPIOOutAll("led/relay", "off")
 	}
 
@@ -277,18 +288,22 @@ func (b *Talkkonnect) ClientStart() {
 
 	log.Printf("info: [%d] Default Mumble Accounts Found in XML config\n", AccountCount)
 
-	if Config.Global.Hardware.TargetBoard == "rpi" {
+	if targetBoardHasGPIO() {
 		log.Println("info: Target Board Set as RPI (gpio enabled) ")
 		b.initGPIO()
 		if Config.Global.Hardware.LedStripEnabled {
 			MyLedStrip, _ = NewLedStrip()
 			log.Printf("info: Led Strip %v %s\n", MyLedStrip.buf, MyLedStrip.display)
 		}
+		initLEDPatterns()
 	} else {
 		log.Println("info: Target Board Set as PC (gpio disabled) ")
 	}
 
-	if (Config.Global.Hardware.TargetBoard == "rpi" && Config.Global.Hardware.LCD.BacklightTimerEnabled) && (OLEDEnabled || Config.Global.Hardware.LCD.Enabled) {
+	initDisplayManager()
+	go b.loneWorker()
+
+	if (targetBoardHasGPIO() && Config.Global.Hardware.LCD.BacklightTimerEnabled) && (OLEDEnabled || Config.Global.Hardware.LCD.Enabled) {
 
 		log.Println("info: Backlight Timer Enabled by Config")
 		BackLightTime = *BackLightTimePtr
@@ -336,29 +351,6 @@ func (b *Talkkonnect) ClientStart() {
 
 	pstream = gumbleffmpeg.New(b.Client, gumbleffmpeg.SourceFile(""), 0)
 
-	if (Config.Global.Hardware.HeartBeat.Enabled) && (Config.Global.Hardware.TargetBoard == "rpi") {
-		HeartBeat := time.NewTicker(time.Duration(Config.Global.Hardware.HeartBeat.Periodmsecs) * time.Millisecond)
-
-		go func() {
-			for range HeartBeat.C {
-				timer1 := time.NewTimer(time.Duration(Config.Global.Hardware.HeartBeat.LEDOnmsecs) * time.Millisecond)
-				timer2 := time.NewTimer(time.Duration(Config.Global.Hardware.HeartBeat.LEDOffmsecs) * time.Millisecond)
-				<-timer1.C
-				if Config.Global.Hardware.HeartBeat.Enabled {
-					GPIOOutPin("heartbeat", "on")
-				}
-				<-timer2.C
-				if Config.Global.Hardware.HeartBeat.Enabled {
-					GPIOOutPin("heartbeat", "off")
-				}
-				if KillHeartBeat {
-					HeartBeat.Stop()
-				}
-
-			}
-		}()
-	}
-
 	if Config.Global.Software.Beacon.Enabled {
 		BeaconTicker := time.NewTicker(time.Duration(Config.Global.Software.Beacon.BeaconTimerSecs) * time.Second)
 
@@ -393,7 +385,7 @@ func (b *Talkkonnect) ClientStart() {
 				if Config.Global.Hardware.AudioRecordFunction.RecordMode == "traffic" {
 					log.Println("info: Incoming Traffic will be Recorded with sox")
 					AudioRecordTraffic()
-					if Config.Global.Hardware.TargetBoard == "rpi" {
+					if targetBoardHasGPIO() {
 						if LCDEnabled {
 							LcdText = [4]string{"nil", "nil", "nil", "Traffic Recording ->"} // 4
 							LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
@@ -406,7 +398,7 @@ func (b *Talkkonnect) ClientStart() {
 				if Config.Global.Hardware.AudioRecordFunction.RecordMode == "ambient" {
 					log.Println("info: Ambient Audio from Mic will be Recorded with sox")
 					AudioRecordAmbient()
-					if Config.Global.Hardware.TargetBoard == "rpi" {
+					if targetBoardHasGPIO() {
 						if LCDEnabled {
 							LcdText = [4]string{"nil", "nil", "nil", "Mic Recording ->"} // 4
 							LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
@@ -419,7 +411,7 @@ func (b *Talkkonnect) ClientStart() {
 				if Config.Global.Hardware.AudioRecordFunction.RecordMode == "combo" {
 					log.Println("info: Both Incoming Traffic and Ambient Audio from Mic will be Recorded with sox")
 					AudioRecordCombo()
-					if Config.Global.Hardware.TargetBoard == "rpi" {
+					if targetBoardHasGPIO() {
 						if LCDEnabled {
 							LcdText = [4]string{"nil", "nil", "nil", "Combo Recording ->"} // 4
 							LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
@@ -479,11 +471,29 @@ func (b *Talkkonnect) ClientStart() {
 	}()
 
 	if Config.Global.Hardware.GPS.Enabled {
+		startGNSSReader()
+
+		if Config.Global.Hardware.Geofence.Enabled {
+			go b.geofence()
+		}
+
+		if Config.Global.Hardware.TrackLog.Enabled {
+			go trackLogger()
+		}
+
+		if Config.Global.Hardware.PositionShare.Enabled {
+			go b.positionShare()
+		}
+
+		if Config.Global.Hardware.GPS.MQTTPublishSecs > 0 && Config.Global.Software.RemoteControl.MQTT.Enabled {
+			go gnssMQTTPublisher()
+		}
+
 		if Config.Global.Hardware.GPS.GpsInfoVerbose {
 			go consoleScreenLogging()
 		}
 
-		if Config.Global.Hardware.TargetBoard == "rpi" && Config.Global.Hardware.Traccar.DeviceScreenEnabled && (Config.Global.Hardware.LCD.Enabled || Config.Global.Hardware.OLED.Enabled) {
+		if targetBoardHasGPIO() && Config.Global.Hardware.Traccar.DeviceScreenEnabled && (Config.Global.Hardware.LCD.Enabled || Config.Global.Hardware.OLED.Enabled) {
 			go gpsDisplayShow()
 		}
 
@@ -499,15 +509,28 @@ func (b *Talkkonnect) ClientStart() {
 			if Config.Global.Hardware.Traccar.Track && Config.Global.Hardware.Traccar.Protocol.Name == "t55" {
 				go tcpSendT55Traccar()
 			}
+
+			if Config.Global.Hardware.Traccar.Track && Config.Global.Hardware.Traccar.Protocol.Name == "osmandjson" {
+				go httpPostTraccarJSON()
+			}
+
+			if Config.Global.Hardware.Traccar.Track && Config.Global.Hardware.Traccar.Protocol.Name == "aprs" {
+				go aprsBeacon()
+			}
 		}
 	}
 
 	if Config.Global.Hardware.Radio.Enabled {
-		if !(Config.Global.Hardware.Radio.Sa818.Enabled && Config.Global.Hardware.Radio.Sa818.Serial.Enabled) {
-			log.Println("error: Radio Module Not Configured Properly")
+		if err := radioOpen(); err != nil {
+			log.Println("error: Radio Module Not Configured Properly ", err)
 		} else {
 			createEnabledRadioChannels()
-			go radioSetChannel(Config.Global.Hardware.Radio.ConnectChannelID)
+			go func() {
+				radioSetChannel(Config.Global.Hardware.Radio.ConnectChannelID)
+				if Config.Global.Hardware.Radio.Scan.Enabled && Config.Global.Hardware.Radio.Scan.StartOnConnect {
+					radioScanStart()
+				}
+			}()
 		}
 	}
 
@@ -622,49 +645,8 @@ keyPressListenerLoop:
 					b.nextEnabledRotaryEncoderFunction()
 					//b.cmdConnNextServer()
 				default:
-					if _, ok := TTYKeyMap[ev.Ch]; ok {
-						switch strings.ToLower(TTYKeyMap[ev.Ch].Command) {
-						case "channelup":
-							b.cmdChannelUp()
-						case "channeldown":
-							b.cmdChannelDown()
-						case "serverup":
-							b.cmdConnNextServer()
-						case "serverdown":
-							b.cmdConnPreviousServer()
-						case "mute":
-							b.cmdMuteUnmute("mute")
-						case "unmute":
-							b.cmdMuteUnmute("unmute")
-						case "mute-toggle":
-							b.cmdMuteUnmute("toggle")
-						case "stream-toggle":
-							b.cmdPlayback()
-						case "volumeup":
-							b.cmdVolumeUp()
-						case "volumedown":
-							b.cmdVolumeDown()
-						case "setcomment":
-							if TTYKeyMap[ev.Ch].ParamValue == "setcomment" {
-								log.Println("info: Set Commment ", TTYKeyMap[ev.Ch].ParamValue)
-								b.Client.Self.SetComment(TTYKeyMap[ev.Ch].ParamValue)
-							}
-						case "transmitstart":
-							b.cmdStartTransmitting()
-						case "transmitstop":
-							b.cmdStopTransmitting()
-						case "record":
-							b.cmdAudioTrafficRecord()
-							b.cmdAudioMicRecord()
-						case "voicetargetset":
-							Paramvalue, err := strconv.Atoi(TTYKeyMap[ev.Ch].ParamValue)
-							if err != nil {
-								log.Printf("error: Error Message %v, %v Is Not A Number", err, Paramvalue)
-							}
-							b.cmdSendVoiceTargets(uint32(Paramvalue))
-						default:
-							log.Println("error: Command Not Defined ", strings.ToLower(TTYKeyMap[ev.Ch].Command))
-						}
+					if key, ok := TTYKeyMap[ev.Ch]; ok {
+						b.runInputCommand("tty", string(ev.Ch), inputCommandStruct{key.Command, key.ParamName, key.ParamValue}, false)
 					} else {
 						log.Println("error: Key Not Mapped ASC ", ev.Ch)
 					}
@@ -54,7 +54,7 @@ func FatalCleanUp(message string) {
 
 func CleanUp() {
 
-	if Config.Global.Hardware.TargetBoard == "rpi" {
+	if targetBoardHasGPIO() {
 		t := time.Now()
 		if LCDEnabled {
 			LcdText = [4]string{"talkkonnect stopped", t.Format("02-01-2006 15:04:05"), "Please Visit", "www.talkkonnect.com"}
@@ -109,7 +109,7 @@ func (b *Talkkonnect) ReConnect() {
 		ConnectAttempts++
 		b.Connect()
 	} else {
-		if Config.Global.Hardware.TargetBoard == "rpi" {
+		if targetBoardHasGPIO() {
 			if LCDEnabled {
 				LcdText = [4]string{"Failed to Connect!", "nil", "nil", "nil"}
 				LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
@@ -153,7 +153,7 @@ func (b *Talkkonnect) TransmitStart() {
 		}
 	}
 
-	if Config.Global.Hardware.TargetBoard == "rpi" {
+	if targetBoardHasGPIO() {
 		// use groutine so no need to wait for local screen cause it causes delay
 		go GPIOOutPin("transmit", "on")
 		go MyLedStripTransmitLEDOn()
@@ -176,8 +176,9 @@ func (b *Talkkonnect) TransmitStop(withBeep bool) {
 	}
 
 	b.BackLightTimer()
+	displayWidgetSet("status", "Online")
 
-	if Config.Global.Hardware.TargetBoard == "rpi" {
+	if targetBoardHasGPIO() {
 		GPIOOutPin("transmit", "off")
 		MyLedStripTransmitLEDOff()
 		if LCDEnabled {
@@ -213,8 +214,9 @@ func (b *Talkkonnect) ChangeChannel(ChannelName string) {
 	if channel != nil {
 
 		b.Client.Self.Move(channel)
+		displayWidgetSet("channel", ChannelName)
 
-		if Config.Global.Hardware.TargetBoard == "rpi" {
+		if targetBoardHasGPIO() {
 			if LCDEnabled {
 				LcdText[1] = "Joined " + ChannelName
 				LcdText[2] = Username[AccountIndex]
@@ -278,7 +280,8 @@ func (b *Talkkonnect) ParticipantLEDUpdate(verbose bool) {
 		if verbose {
 			log.Println("info: Current Channel ", b.Client.Self.Channel.Name, " has (", participantCount, ") participants")
 			b.ListUsers()
-			if Config.Global.Hardware.TargetBoard == "rpi" {
+			displayWidgetSet("channel", "("+strconv.Itoa(participantCount)+")"+b.Client.Self.Channel.Name)
+			if targetBoardHasGPIO() {
 				if LCDEnabled {
 					LcdText[0] = b.Name //b.Address
 					LcdText[1] = "(" + strconv.Itoa(participantCount) + ")" + b.Client.Self.Channel.Name
@@ -296,7 +299,7 @@ func (b *Talkkonnect) ParticipantLEDUpdate(verbose bool) {
 	}
 
 	if participantCount > 1 {
-		if Config.Global.Hardware.TargetBoard == "rpi" {
+		if targetBoardHasGPIO() {
 			GPIOOutPin("participants", "on")
 		}
 	} else {
@@ -314,7 +317,7 @@ func (b *Talkkonnect) ParticipantLEDUpdate(verbose bool) {
 
 			prevParticipantCount = 0
 
-			if Config.Global.Hardware.TargetBoard == "rpi" {
+			if targetBoardHasGPIO() {
 				GPIOOutPin("participants", "off")
 				if LCDEnabled {
 					LcdText = [4]string{b.Name, "(0)" + b.Client.Self.Channel.Name, "", "nil"} //b.Address
@@ -472,7 +475,7 @@ func (b *Talkkonnect) SetComment(comment string) {
 		b.BackLightTimer()
 		b.Client.Self.SetComment(comment)
 		t := time.Now()
-		if Config.Global.Hardware.TargetBoard == "rpi" {
+		if targetBoardHasGPIO() {
 			if LCDEnabled {
 				LcdText[2] = "Status at " + t.Format("15:04:05")
 				time.Sleep(500 * time.Millisecond)
@@ -490,7 +493,7 @@ func (b *Talkkonnect) SetComment(comment string) {
 func (b *Talkkonnect) BackLightTimer() {
 	BackLightTime = *BackLightTimePtr
 
-	if Config.Global.Hardware.TargetBoard != "rpi" || (!LCDBackLightTimerEnabled && !OLEDEnabled && !LCDEnabled) {
+	if !targetBoardHasGPIO() || (!LCDBackLightTimerEnabled && !OLEDEnabled && !LCDEnabled) {
 		return
 	}
 
@@ -626,9 +629,11 @@ func (b *Talkkonnect) VoiceTargetUserSet(TargetID uint32, TargetUsers []string)
                         }
                         b.sevenSegment("voicetarget", strconv.Itoa(int(TargetID)))
                         GPIOOutPin("voicetarget", "on")
+                        MyLedStripStateSet("voicetarget", true)
                 } else {
                         //b.VoiceTarget.Clear()
                         GPIOOutPin("voicetarget", "off")
+                        MyLedStripStateSet("voicetarget", false)
                         log.Println("debug: Cleared Voice Targets")
                         b.sevenSegment("voicetarget", strconv.Itoa(int(TargetID)))
                 }
@@ -664,6 +669,7 @@ func (b *Talkkonnect) VoiceTargetChannelSet(targetID uint32, targetChannelName s
 		b.Client.Send(vtarget)
 		log.Printf("debug: Shouting to Root Channel %v to VT ID %v with recursive %v links %v group %v\n", vChannel.Name, targetID, recursive, links, group)
 		GPIOOutPin("voicetarget", "off")
+		MyLedStripStateSet("voicetarget", false)
 		b.sevenSegment("voicetarget", strconv.Itoa(int(targetID)))
 		return
 	}
@@ -680,6 +686,7 @@ func (b *Talkkonnect) VoiceTargetChannelSet(targetID uint32, targetChannelName s
 	b.sevenSegment("voicetarget", strconv.Itoa(int(targetID)))
 	if targetID > 0 {
 		GPIOOutPin("voicetarget", "on")
+		MyLedStripStateSet("voicetarget", true)
 	}
 }
 
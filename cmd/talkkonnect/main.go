@@ -47,6 +47,10 @@ var serverindex = flag.String("serverindex", "0", "jump to server index [n]")
 
 func main() {
 
+	if len(os.Args) > 1 && os.Args[1] == "importchirp" {
+		os.Exit(talkkonnect.ImportChirp(os.Args[2:]))
+	}
+
 	config := flag.String("config", "/home/talkkonnect/gocode/src/github.com/talkkonnect/talkkonnect/talkkonnect.xml", "full path to talkkonnect.xml configuration file")
 
 	flag.Usage = talkkonnectusage
@@ -90,5 +94,6 @@ func talkkonnectusage() {
 	fmt.Println("-config=/home/talkkonnect/gocode/src/github.com/talkkonnect/talkkonnect/talkkonnect.xml")
 	fmt.Println("-serverindex=[n] for the index of the enabled server to connect to in XML file")
 	fmt.Println("-version for the version")
+	fmt.Println("importchirp [-config=file] [-write] [-minfreq=n] [-maxfreq=n] chirp.csv to import radio channels from a chirp csv export")
 	fmt.Println("-help for this screen")
 }
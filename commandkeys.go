@@ -39,7 +39,6 @@ import (
 	"strconv"
 	"time"
 
-	hd44780 "github.com/talkkonnect/go-hd44780"
 	"github.com/talkkonnect/volume-go"
 )
 
@@ -85,7 +84,7 @@ func (b *Talkkonnect) cmdMuteUnmute(subCommand string) {
 			}
 			TTSEvent("unmutespeaker")
 			log.Println("info: Output Device Unmuted")
-			if Config.Global.Hardware.TargetBoard == "rpi" {
+			if targetBoardHasGPIO() {
 				if LCDEnabled {
 					LcdText = [4]string{"nil", "nil", "nil", "UnMuted"}
 					LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
@@ -102,7 +101,7 @@ func (b *Talkkonnect) cmdMuteUnmute(subCommand string) {
 				log.Println("error: Muting Failed", err)
 			}
 			log.Println("info: Output Device Muted")
-			if Config.Global.Hardware.TargetBoard == "rpi" {
+			if targetBoardHasGPIO() {
 				if LCDEnabled {
 					LcdText = [4]string{"nil", "nil", "nil", "Muted"}
 					LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
@@ -124,7 +123,7 @@ func (b *Talkkonnect) cmdMuteUnmute(subCommand string) {
 			return
 		}
 		log.Println("info: Output Device Muted")
-		if Config.Global.Hardware.TargetBoard == "rpi" {
+		if targetBoardHasGPIO() {
 			if LCDEnabled {
 				LcdText = [4]string{"nil", "nil", "nil", "Muted"}
 				LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
@@ -145,7 +144,7 @@ func (b *Talkkonnect) cmdMuteUnmute(subCommand string) {
 			return
 		}
 		log.Println("info: Output Device Unmuted")
-		if Config.Global.Hardware.TargetBoard == "rpi" {
+		if targetBoardHasGPIO() {
 			if LCDEnabled {
 				LcdText = [4]string{"nil", "nil", "nil", "UnMuted"}
 				LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
@@ -167,7 +166,7 @@ func (b *Talkkonnect) cmdCurrentVolume() {
 	log.Println("info: Volume Level is at", OrigVolume, "%")
 
 	TTSEvent("currentvolumelevel")
-	if Config.Global.Hardware.TargetBoard == "rpi" {
+	if targetBoardHasGPIO() {
 		if LCDEnabled {
 			LcdText = [4]string{"nil", "nil", "nil", "Volume " + strconv.Itoa(OrigVolume)}
 			LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
@@ -194,7 +193,7 @@ func (b *Talkkonnect) cmdVolumeUp() {
 		}
 		origVolume, _ := volume.GetVolume(Config.Global.Software.Settings.OutputVolControlDevice)
 		log.Println("info: Volume UP (+) Now At ", origVolume, "%")
-		if Config.Global.Hardware.TargetBoard == "rpi" {
+		if targetBoardHasGPIO() {
 			if LCDEnabled {
 				LcdText = [4]string{"nil", "nil", "nil", "Volume + " + strconv.Itoa(origVolume)}
 				LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
@@ -207,7 +206,7 @@ func (b *Talkkonnect) cmdVolumeUp() {
 	} else {
 		log.Println("debug: F5 Increase Volume")
 		log.Println("info: Already at Maximum Possible Volume")
-		if Config.Global.Hardware.TargetBoard == "rpi" {
+		if targetBoardHasGPIO() {
 			if LCDEnabled {
 				LcdText = [4]string{"nil", "nil", "nil", "Max Vol"}
 				LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
@@ -236,7 +235,7 @@ func (b *Talkkonnect) cmdVolumeDown() {
 		}
 		origVolume, _ := volume.GetVolume(Config.Global.Software.Settings.OutputVolControlDevice)
 		log.Println("info: Volume Down (-) Now At ", origVolume, "%")
-		if Config.Global.Hardware.TargetBoard == "rpi" {
+		if targetBoardHasGPIO() {
 			if LCDEnabled {
 				LcdText = [4]string{"nil", "nil", "nil", "Volume - " + strconv.Itoa(origVolume)}
 				LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
@@ -249,7 +248,7 @@ func (b *Talkkonnect) cmdVolumeDown() {
 	} else {
 		log.Println("debug: F6 Increase Volume Already")
 		log.Println("info: Already at Minimum Possible Volume")
-		if Config.Global.Hardware.TargetBoard == "rpi" {
+		if targetBoardHasGPIO() {
 			if LCDEnabled {
 				LcdText = [4]string{"nil", "nil", "nil", "Min Vol"}
 				LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
@@ -368,46 +367,38 @@ func (b *Talkkonnect) cmdGPSPosition() {
 
 	TTSEvent("requestgpsposition")
 
-	var i int = 0
-	var tries int = 10
-	for i = 0; i < tries; i++ {
-		goodGPSRead, err := getGpsPosition(3)
-		if err != nil {
-			log.Println("error: GPS Function Returned Error Message", err)
+	goodGPSRead, err := getGpsPosition(gnssRequestWaitSecs)
+	if err != nil {
+		log.Println("error: GPS Function Returned Error Message", err)
 
-			if Config.Global.Hardware.GPS.Enabled {
-				if Config.Global.Hardware.GPS.GpsDiagSounds {
-					eventSound := findEventSound("gpsDeviceError")
-					if eventSound.Enabled {
-						if v, err := strconv.Atoi(eventSound.Volume); err == nil {
-							localMediaPlayer(eventSound.FileName, v, eventSound.Blocking, 0, 1)
-							log.Printf("debug: Playing a GPS diagnostic sound")
-						}
+		if Config.Global.Hardware.GPS.Enabled {
+			if Config.Global.Hardware.GPS.GpsDiagSounds {
+				eventSound := findEventSound("gpsDeviceError")
+				if eventSound.Enabled {
+					if v, err := strconv.Atoi(eventSound.Volume); err == nil {
+						localMediaPlayer(eventSound.FileName, v, eventSound.Blocking, 0, 1)
+						log.Printf("debug: Playing a GPS diagnostic sound")
 					}
 				}
 			}
-
-			if Config.Global.Hardware.GPS.Enabled {
-				if Config.Global.Hardware.LCD.Enabled && (Config.Global.Hardware.GPS.GpsDisplayShow || Config.Global.Hardware.Traccar.DeviceScreenEnabled) {
-					LcdText = [4]string{"nil", "GPS ERR1", "GPS Device Error", ""}
-					go hd44780.LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
-				}
-				if Config.Global.Hardware.OLED.Enabled {
-					oledDisplay(false, 4, 1, "GPS ERR1 "+time.Now().Format("15:04:05"))
-					oledDisplay(false, 5, 1, "GPS Device Error")
-					oledDisplay(false, 6, 1, "")
-					oledDisplay(false, 7, 1, "")
-				}
-			}
-			break
 		}
 
-		if goodGPSRead {
-			break
+		if Config.Global.Hardware.GPS.Enabled {
+			if Config.Global.Hardware.LCD.Enabled && (Config.Global.Hardware.GPS.GpsDisplayShow || Config.Global.Hardware.Traccar.DeviceScreenEnabled) {
+				LcdText = [4]string{"nil", "GPS ERR1", "GPS Device Error", ""}
+				LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
+			}
+			if Config.Global.Hardware.OLED.Enabled {
+				oledDisplay(false, 4, 1, "GPS ERR1 "+time.Now().Format("15:04:05"))
+				oledDisplay(false, 5, 1, "GPS Device Error")
+				oledDisplay(false, 6, 1, "")
+				oledDisplay(false, 7, 1, "")
+			}
 		}
+		return
 	}
 
-	if i == tries {
+	if !goodGPSRead {
 		log.Println("warn: Could Not Get a Good GPS Read")
 
 		if Config.Global.Hardware.GPS.Enabled {
@@ -426,7 +417,7 @@ func (b *Talkkonnect) cmdGPSPosition() {
 		if Config.Global.Hardware.GPS.Enabled {
 			if Config.Global.Hardware.LCD.Enabled && (Config.Global.Hardware.GPS.GpsDisplayShow || Config.Global.Hardware.Traccar.DeviceScreenEnabled) {
 				LcdText = [4]string{"nil", "GPS ERR2", "No Good GPS Reading", ""}
-				go hd44780.LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
+				LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
 			}
 			if Config.Global.Hardware.OLED.Enabled {
 				oledDisplay(false, 4, 1, "GPS ERR2 "+time.Now().Format("15:04:05"))
@@ -467,54 +458,45 @@ func (b *Talkkonnect) cmdSendEmail() {
 	log.Printf("debug: Ctrl-E Pressed \n")
 	log.Println("info: Send Email Requested")
 
-	var i int = 0
-	var tries int = 10
-
-	for i = 0; i < tries; i++ {
-		goodGPSRead, err := getGpsPosition(3)
+	if !Config.Global.Software.SMTP.Enabled {
+		log.Println("warning: Sending Email Disabled in Config")
+		return
+	}
 
-		if err != nil {
+	if Config.Global.Hardware.GPS.Enabled {
+		if goodGPSRead, err := getGpsPosition(gnssRequestWaitSecs); err != nil {
 			log.Println("error: GPS Function Returned Error Message", err)
-			break
+		} else if !goodGPSRead {
+			log.Println("warn: Could Not Get a Good GPS Read, Sending Email Without Position")
 		}
-
-		if goodGPSRead {
-			break
-		}
-
-	}
-
-	if i == tries {
-		log.Println("warn: Could Not Get a Good GPS Read")
-		return
 	}
 
 	TTSEvent("sendemail")
 
-	if Config.Global.Software.SMTP.Enabled {
-
-		emailMessage := fmt.Sprintf(Config.Global.Software.SMTP.Message + "\n")
-		emailMessage = emailMessage + fmt.Sprintf("Ident: %s \n", b.Ident)
-		emailMessage = emailMessage + fmt.Sprintf("Mumble Username: %s \n", b.Username)
+	data := b.emailTemplateData()
+	emailMessage := emailTemplate("message", Config.Global.Software.SMTP.Message, data) + "\n"
+	emailMessage = emailMessage + fmt.Sprintf("Ident: %s \n", b.Ident)
+	emailMessage = emailMessage + fmt.Sprintf("Mumble Username: %s \n", b.Username)
 
+	if data.Fix {
 		if Config.Global.Software.SMTP.GpsDateTime {
-			emailMessage = emailMessage + fmt.Sprintf("Date "+GNSSData.Date+" UTC Time "+GNSSData.Time+"\n")
+			emailMessage = emailMessage + "Date " + data.GPSDate + " UTC Time " + data.GPSTime + "\n"
 		}
 
 		if Config.Global.Software.SMTP.GpsLatLong {
-			emailMessage = emailMessage + fmt.Sprintf("Latitude "+strconv.FormatFloat(GNSSData.Lattitude, 'f', 6, 64)+" Longitude "+strconv.FormatFloat(GNSSData.Longitude, 'f', 6, 64)+"\n")
+			emailMessage = emailMessage + "Latitude " + data.Lat + " Longitude " + data.Lon + "\n"
 		}
 
 		if Config.Global.Software.SMTP.GoogleMapsURL {
-			emailMessage = emailMessage + "http://www.google.com/maps/place/" + strconv.FormatFloat(GNSSData.Lattitude, 'f', 6, 64) + "," + strconv.FormatFloat(GNSSData.Longitude, 'f', 6, 64)
+			emailMessage = emailMessage + data.MapsURL + "\n"
 		}
+	} else if Config.Global.Software.SMTP.GpsLatLong || Config.Global.Software.SMTP.GoogleMapsURL {
+		emailMessage = emailMessage + "No GPS Fix\n"
+	}
 
-		err := sendviagmail(Config.Global.Software.SMTP.Username, Config.Global.Software.SMTP.Password, Config.Global.Software.SMTP.Receiver, Config.Global.Software.SMTP.Subject, emailMessage)
-		if err != nil {
-			log.Println("error: Error from Email Module: ", err)
-		}
-	} else {
-		log.Println("warning: Sending Email Disabled in Config")
+	err := sendEmail(emailTemplate("subject", Config.Global.Software.SMTP.Subject, data), emailMessage)
+	if err != nil {
+		log.Println("error: Error from Email Module: ", err)
 	}
 }
 
@@ -537,7 +519,7 @@ func (b *Talkkonnect) cmdConnPreviousServer() {
 func (b *Talkkonnect) cmdClearScreen() {
 	reset()
 	log.Printf("debug: Ctrl-L Pressed Cleared Screen \n")
-	if Config.Global.Hardware.TargetBoard == "rpi" {
+	if targetBoardHasGPIO() {
 		if LCDEnabled {
 			LcdText = [4]string{"nil", "nil", "nil", "nil"}
 			LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
@@ -553,7 +535,7 @@ func (b *Talkkonnect) cmdClearScreen() {
 
 func (b *Talkkonnect) cmdRadioChannelMove(command string) {
 	log.Printf("debug: Ctrl-M Radio Channel %v\n", command)
-	if Config.Global.Hardware.TargetBoard == "rpi" {
+	if targetBoardHasGPIO() {
 		if Config.Global.Hardware.Radio.Enabled {
 			if !(Config.Global.Hardware.Radio.Sa818.Enabled && Config.Global.Hardware.Radio.Sa818.Serial.Enabled) {
 				log.Println("error: Radio Module Not Configured Properly")
@@ -608,7 +590,7 @@ func (b *Talkkonnect) cmdAudioTrafficRecord() {
 			if Config.Global.Hardware.AudioRecordFunction.RecordFromOutput != "" {
 				if Config.Global.Hardware.AudioRecordFunction.RecordSoft == "sox" {
 					go AudioRecordTraffic()
-					if Config.Global.Hardware.TargetBoard == "rpi" {
+					if targetBoardHasGPIO() {
 						if LCDEnabled {
 							LcdText = [4]string{"nil", "nil", "Traffic Audio Rec ->", "nil"} // 4 or 3
 							LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
@@ -640,7 +622,7 @@ func (b *Talkkonnect) cmdAudioMicRecord() {
 			if Config.Global.Hardware.AudioRecordFunction.RecordFromInput != "" {
 				if Config.Global.Hardware.AudioRecordFunction.RecordSoft == "sox" {
 					go AudioRecordAmbient()
-					if Config.Global.Hardware.TargetBoard == "rpi" {
+					if targetBoardHasGPIO() {
 						if LCDEnabled {
 							LcdText = [4]string{"nil", "nil", "Mic Audio Rec ->", "nil"} // 4 or 3
 							LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
@@ -672,7 +654,7 @@ func (b *Talkkonnect) cmdAudioMicTrafficRecord() {
 			if Config.Global.Hardware.AudioRecordFunction.RecordFromInput != "" {
 				if Config.Global.Hardware.AudioRecordFunction.RecordSoft == "sox" {
 					go AudioRecordCombo()
-					if Config.Global.Hardware.TargetBoard == "rpi" {
+					if targetBoardHasGPIO() {
 						if LCDEnabled {
 							LcdText = [4]string{"nil", "nil", "Combo Audio Rec ->", "nil"} // 4 or 3
 							LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
@@ -695,100 +677,15 @@ func (b *Talkkonnect) cmdPanicSimulation() {
 	}
 	b.BackLightTimer()
 	log.Printf("debug: Ctrl-P Pressed \n")
-	log.Println("info: Panic Button Start/Stop Simulation Requested")
-
-	TTSEvent("panicsimulation")
+	log.Println("info: Panic Button Requested")
 
-	if Config.Global.Hardware.PanicFunction.Enabled {
-
-		if b.IsTransmitting {
-			b.TransmitStop(false)
-		} else {
-			b.IsTransmitting = true
-			b.SendMessage(Config.Global.Hardware.PanicFunction.Message, Config.Global.Hardware.PanicFunction.RecursiveSendMessage)
-
-		}
-
-		if Config.Global.Hardware.PanicFunction.SendIdent {
-			b.SendMessage(fmt.Sprintf("My Username is %s and Ident is %s", b.Username, b.Ident), Config.Global.Hardware.PanicFunction.RecursiveSendMessage)
-		}
-
-		if Config.Global.Hardware.PanicFunction.SendGpsLocation {
-
-			var i int = 0
-			var tries int = 10
-
-			for i = 0; i < tries; i++ {
-				goodGPSRead, err := getGpsPosition(3)
-
-				if err != nil {
-					log.Println("error: GPS Function Returned Error Message", err)
-					break
-				}
-
-				if goodGPSRead {
-					break
-				}
-			}
-
-			if i == tries {
-				log.Println("warn: Could Not Get a Good GPS Read")
-			}
-
-			if goodGPSRead && i != tries {
-				log.Println("info: Sending GPS Info My Message")
-				gpsMessage := "My GPS Coordinates are " + fmt.Sprintf(" Latitude "+strconv.FormatFloat(GNSSData.Lattitude, 'f', 6, 64)) + fmt.Sprintf(" Longitude "+strconv.FormatFloat(GNSSData.Longitude, 'f', 6, 64))
-				b.SendMessage(gpsMessage, Config.Global.Hardware.PanicFunction.RecursiveSendMessage)
-			}
-
-			IsPlayStream = true
-			b.playIntoStream(Config.Global.Hardware.PanicFunction.FilenameAndPath, Config.Global.Hardware.PanicFunction.Volume)
-			if Config.Global.Hardware.TargetBoard == "rpi" {
-				if LCDEnabled {
-					LcdText = [4]string{"nil", "nil", "nil", "Panic Message Sent!"}
-					LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
-				}
-				if OLEDEnabled {
-					oledDisplay(false, 6, 1, "Panic Message Sent!")
-				}
-			}
-			if Config.Global.Hardware.PanicFunction.TxLockEnabled && Config.Global.Hardware.PanicFunction.TxLockTimeOutSecs > 0 {
-				b.TxLockTimer()
-			}
-
-			// New. Send email after Panic Event //
-			if Config.Global.Hardware.PanicFunction.PMailEnabled {
-				b.cmdSendEmail()
-				log.Println("info: Sending Panic Alert Email To Predefined Email Address")
-			}
-			//
-
-			// New. Record ambient audio on Panic Event if recording is enabled
-			if Config.Global.Hardware.AudioRecordFunction.Enabled {
-				log.Println("info: Running sox for Audio Recording...")
-				AudioRecordAmbient()
-			}
-			//
-
-		} else {
-			log.Println("warn: Panic Function Disabled in Config")
-		}
-		IsPlayStream = false
-		b.IsTransmitting = false
-
-		if Config.Global.Hardware.PanicFunction.PLowProfile {
-			GPIOOutAll("led/relay", "off")
-			log.Println("info: Low Profile Lights Option is Enabled. Turning All Leds Off During Panic Event")
-			if LCDEnabled {
-				log.Println("info: Low Profile Lights is Enabled. Turning Off Display During Panic Event")
-				LcdText = [4]string{"", "", "", ""}
-				LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
-			}
-			if OLEDEnabled {
-				oledDisplay(true, 0, 0, "")
-			}
-		}
+	if !Config.Global.Hardware.PanicFunction.Enabled {
+		log.Println("warn: Panic Function Disabled in Config")
+		return
 	}
+
+	TTSEvent("panicsimulation")
+	b.panicTrigger()
 }
 
 func (b *Talkkonnect) cmdRepeatTxLoop() {
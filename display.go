@@ -38,7 +38,19 @@ import (
 
 //var mutex = &sync.Mutex{}
 
+// oledDisplay is left to the display manager when it is enabled, so the hardcoded rows do not overwrite its pages, the
+// text goes to the notice widget instead unless the lcd code already sent it there
 func oledDisplay(OledClear bool, OledRow int, OledColumn int, OledText string) {
+	if displayManagerActive() {
+		if !OledClear && len(OledText) > 0 && !LCDEnabled {
+			displayNotice(OledText)
+		}
+		return
+	}
+	oledWrite(OledClear, OledRow, OledColumn, OledText)
+}
+
+func oledWrite(OledClear bool, OledRow int, OledColumn int, OledText string) {
 	//mutex.Lock()
 	//defer mutex.Unlock()
 
@@ -84,44 +96,76 @@ func oledDisplay(OledClear bool, OledRow int, OledColumn int, OledText string) {
 }
 
 func LcdDisplay(lcdtextshow [4]string, PRSPin int, PEPin int, PD4Pin int, PD5Pin int, PD6Pin int, PD7Pin int, LCDInterfaceType string, LCDI2CAddress byte) {
+	if displayManagerActive() {
+		displayLcdNotice(lcdtextshow)
+		return
+	}
 	go hd44780.LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
 }
 
+// displayLcdNotice sends the rows of a partial lcd update to the notice widget, the rows set to nil are the ones the
+// update leaves alone. Full updates are the status and channel rows which have widgets of their own
+func displayLcdNotice(lcdtextshow [4]string) {
+	var rows []string
+	var partial bool
+	for _, row := range lcdtextshow {
+		if row == "nil" {
+			partial = true
+			continue
+		}
+		if len(strings.TrimSpace(row)) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	if partial && len(rows) > 0 {
+		displayNotice(strings.Join(rows, "\n"))
+	}
+}
+
 func (b *Talkkonnect) sevenSegment(function string, value string) {
 	if Config.Global.Hardware.IO.Max7219.Enabled {
 		if function == "mumblechannel" {
 			prefix := "c"
 			if b.findEnabledRotaryEncoderFunction("mumblechannel") {
-				Max7219(Config.Global.Hardware.IO.Max7219.Max7219Cascaded, Config.Global.Hardware.IO.Max7219.SPIBus, Config.Global.Hardware.IO.Max7219.SPIDevice, Config.Global.Hardware.IO.Max7219.Brightness, prefix+value)
+				sevenSegmentShow(prefix + value)
 			}
 		}
 		if function == "localvolume" {
 			prefix := "u"
 			if b.findEnabledRotaryEncoderFunction("localvolume") {
-				Max7219(Config.Global.Hardware.IO.Max7219.Max7219Cascaded, Config.Global.Hardware.IO.Max7219.SPIBus, Config.Global.Hardware.IO.Max7219.SPIDevice, Config.Global.Hardware.IO.Max7219.Brightness, prefix+value)
+				sevenSegmentShow(prefix + value)
 			}
 		}
 		if function == "radiochannel" {
 			prefix := "r"
 			if b.findEnabledRotaryEncoderFunction("radiochannel") {
-				Max7219(Config.Global.Hardware.IO.Max7219.Max7219Cascaded, Config.Global.Hardware.IO.Max7219.SPIBus, Config.Global.Hardware.IO.Max7219.SPIDevice, Config.Global.Hardware.IO.Max7219.Brightness, prefix+value)
+				sevenSegmentShow(prefix + value)
 			}
 		}
 		if function == "voicetarget" {
 			prefix := "t"
 			if b.findEnabledRotaryEncoderFunction("voicetarget") {
-				Max7219(Config.Global.Hardware.IO.Max7219.Max7219Cascaded, Config.Global.Hardware.IO.Max7219.SPIBus, Config.Global.Hardware.IO.Max7219.SPIDevice, Config.Global.Hardware.IO.Max7219.Brightness, prefix+value)
+				sevenSegmentShow(prefix + value)
 			}
 		}
 		if function == "hello" {
 			prefix := "hello"
-			Max7219(Config.Global.Hardware.IO.Max7219.Max7219Cascaded, Config.Global.Hardware.IO.Max7219.SPIBus, Config.Global.Hardware.IO.Max7219.SPIDevice, Config.Global.Hardware.IO.Max7219.Brightness, prefix)
+			sevenSegmentShow(prefix)
 		}
 		if function == "bye" {
 			prefix := "bye"
-			Max7219(Config.Global.Hardware.IO.Max7219.Max7219Cascaded, Config.Global.Hardware.IO.Max7219.SPIBus, Config.Global.Hardware.IO.Max7219.SPIDevice, Config.Global.Hardware.IO.Max7219.Brightness, prefix)
+			sevenSegmentShow(prefix)
 		}
 	} else {
 		log.Println("debug: Max7219 Seven Segment Not Enabled")
 	}
 }
+
+// sevenSegmentShow puts the text in the rotary widget when the display manager is enabled so the max7219 page decides where it goes
+func sevenSegmentShow(text string) {
+	if displayManagerActive() {
+		displayWidgetSet("rotary", text)
+		return
+	}
+	Max7219(Config.Global.Hardware.IO.Max7219.Max7219Cascaded, Config.Global.Hardware.IO.Max7219.SPIBus, Config.Global.Hardware.IO.Max7219.SPIDevice, Config.Global.Hardware.IO.Max7219.Brightness, text)
+}
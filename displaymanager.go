@@ -0,0 +1,413 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * displaymanager.go talkkonnects function to render pages of widgets laid out in the xml config on lcd, oled, max7219 and console displays
+ */
+
+package talkkonnect
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	hd44780 "github.com/talkkonnect/go-hd44780"
+)
+
+var displayWidgetNames = []string{"status", "server", "channel", "lastspeaker", "message", "gps", "recording", "rotary", "menu", "notice", "radiochannel", "where", "geofence"}
+
+// rows the old lcd and oled code writes one after another within this time end up together in the notice widget
+const displayNoticeJoinMsecs = 200
+
+// displayDevice is a character display, show is called with one line per row already cut to the number of columns
+type displayDevice interface {
+	size() (rows int, columns int)
+	show(lines []string)
+}
+
+type displayWidgetStruct struct {
+	Name  string
+	Row   int
+	Rows  int
+	Label string
+}
+
+type displayPageStruct struct {
+	Name    string
+	Widgets []displayWidgetStruct
+}
+
+type displayDeviceStruct struct {
+	Name   string
+	Device displayDevice
+	Pages  []displayPageStruct
+	Page   int
+	Saved  int
+	Lines  []string
+}
+
+var (
+	displayDevices []*displayDeviceStruct
+	displayWidgets = map[string]string{}
+	displayScrolls = map[string]int{}
+	displayMutex   sync.Mutex
+	displayNoticed time.Time
+)
+
+func displayManagerActive() bool {
+	return Config.Global.Hardware.DisplayManager.Enabled
+}
+
+func initDisplayManager() {
+	if !displayManagerActive() {
+		return
+	}
+
+	for _, device := range Config.Global.Hardware.DisplayManager.Device {
+		if !device.Enabled {
+			continue
+		}
+		var driver displayDevice
+		switch device.Type {
+		case "hd44780":
+			if !LCDEnabled || !targetBoardHasGPIO() {
+				log.Printf("error: Display %v Needs The LCD Enabled On A GPIO Board\n", device.Name)
+				continue
+			}
+			driver = &hd44780Display{rows: displaySize(device.Rows, 4), columns: displaySize(device.Columns, 20)}
+		case "ssd1306":
+			if !OLEDEnabled || !targetBoardHasGPIO() {
+				log.Printf("error: Display %v Needs The OLED Enabled On A GPIO Board\n", device.Name)
+				continue
+			}
+			driver = &ssd1306Display{rows: displaySize(device.Rows, OLEDDisplayRows), columns: displaySize(device.Columns, int(OLEDDisplayColumns))}
+		case "max7219":
+			if !Config.Global.Hardware.IO.Max7219.Enabled {
+				log.Printf("error: Display %v Needs The Max7219 Enabled\n", device.Name)
+				continue
+			}
+			driver = &max7219Display{columns: displaySize(device.Columns, 8*Config.Global.Hardware.IO.Max7219.Max7219Cascaded)}
+		case "console":
+			driver = &consoleDisplay{name: device.Name, rows: displaySize(device.Rows, 4), columns: displaySize(device.Columns, 20)}
+		default:
+			log.Printf("error: Display %v Has Unknown Type %v\n", device.Name, device.Type)
+			continue
+		}
+		if rows, columns := driver.size(); rows < 1 || columns < 1 {
+			log.Printf("error: Display %v Has No Size %vx%v Set Rows And Columns\n", device.Name, rows, columns)
+			continue
+		}
+
+		displayDevices = append(displayDevices, &displayDeviceStruct{Name: device.Name, Device: driver})
+	}
+
+	for _, page := range Config.Global.Hardware.DisplayManager.Page {
+		device := findDisplayDevice(page.Device)
+		if device == nil {
+			log.Printf("error: Display Page %v Uses Undefined Or Disabled Display %v\n", page.Name, page.Device)
+			continue
+		}
+		var loaded = displayPageStruct{Name: page.Name}
+		for _, widget := range page.Widget {
+			rows := widget.Rows
+			if rows <= 0 {
+				rows = 1
+			}
+			loaded.Widgets = append(loaded.Widgets, displayWidgetStruct{widget.Name, widget.Row, rows, widget.Label})
+		}
+		device.Pages = append(device.Pages, loaded)
+	}
+
+	displayMutex.Lock()
+	defer displayMutex.Unlock()
+	for _, device := range displayDevices {
+		rows, columns := device.Device.size()
+		log.Printf("info: Display %v %vx%v With %v Pages\n", device.Name, rows, columns, len(device.Pages))
+		displayRender(device)
+	}
+}
+
+func displaySize(configured int, defaultSize int) int {
+	if configured > 0 {
+		return configured
+	}
+	return defaultSize
+}
+
+func findDisplayDevice(name string) *displayDeviceStruct {
+	for _, device := range displayDevices {
+		if device.Name == name {
+			return device
+		}
+	}
+	return nil
+}
+
+// displayWidgetSet updates the text of a widget and redraws every display whose current page shows it
+func displayWidgetSet(name string, text string) {
+	if !displayManagerActive() {
+		return
+	}
+
+	displayMutex.Lock()
+	defer displayMutex.Unlock()
+
+	if displayWidgets[name] == text {
+		return
+	}
+	displayWidgets[name] = text
+	displayWidgetRender(name)
+}
+
+// displayNotice puts feedback such as volume, mute and gps errors, which the lcd and oled code writes to fixed rows,
+// in the notice widget
+func displayNotice(text string) {
+	displayMutex.Lock()
+	if time.Since(displayNoticed) < displayNoticeJoinMsecs*time.Millisecond && len(displayWidgets["notice"]) > 0 {
+		text = displayWidgets["notice"] + "\n" + text
+	}
+	displayNoticed = time.Now()
+	displayMutex.Unlock()
+
+	displayWidgetSet("notice", text)
+}
+
+// displayWidgetScroll sets how many lines a widget that does not fit its rows is scrolled, the first line stays as a heading
+func displayWidgetScroll(name string, scroll int) {
+	if !displayManagerActive() {
+		return
+	}
+
+	displayMutex.Lock()
+	defer displayMutex.Unlock()
+
+	if displayScrolls[name] == scroll {
+		return
+	}
+	displayScrolls[name] = scroll
+	displayWidgetRender(name)
+}
+
+// displayWidgetRender redraws the displays whose current page shows the widget, the caller must hold displayMutex
+func displayWidgetRender(name string) {
+	for _, device := range displayDevices {
+		if len(device.Pages) == 0 {
+			continue
+		}
+		for _, widget := range device.Pages[device.Page].Widgets {
+			if widget.Name == name {
+				displayRender(device)
+				break
+			}
+		}
+	}
+}
+
+// displayPageSet switches a display to the named page
+func displayPageSet(deviceName string, pageName string) error {
+	displayMutex.Lock()
+	defer displayMutex.Unlock()
+
+	device := findDisplayDevice(deviceName)
+	if device == nil {
+		return fmt.Errorf("display %v not found", deviceName)
+	}
+	for index, page := range device.Pages {
+		if page.Name == pageName {
+			device.Page = index
+			displayRender(device)
+			return nil
+		}
+	}
+	return fmt.Errorf("display %v has no page %v", deviceName, pageName)
+}
+
+// displayPageNext cycles every display to its next page
+func displayPageNext() {
+	displayMutex.Lock()
+	defer displayMutex.Unlock()
+
+	for _, device := range displayDevices {
+		if len(device.Pages) > 1 {
+			device.Page = (device.Page + 1) % len(device.Pages)
+			log.Printf("debug: Display %v Showing Page %v\n", device.Name, device.Pages[device.Page].Name)
+			displayRender(device)
+		}
+	}
+}
+
+// displayMenuPages switches the displays that have a page named menu to it while the menu is open and back after
+func displayMenuPages(active bool) {
+	displayMutex.Lock()
+	defer displayMutex.Unlock()
+
+	for _, device := range displayDevices {
+		for index, page := range device.Pages {
+			if page.Name != "menu" {
+				continue
+			}
+			if active {
+				device.Saved = device.Page
+				device.Page = index
+			} else if device.Page == index {
+				device.Page = device.Saved
+			}
+			displayRender(device)
+		}
+	}
+}
+
+// displayRender lays out the widgets of the current page, the caller must hold displayMutex
+func displayRender(device *displayDeviceStruct) {
+	rows, columns := device.Device.size()
+	lines := make([]string, rows)
+
+	if len(device.Pages) > 0 {
+		for _, widget := range device.Pages[device.Page].Widgets {
+			text, found := displayWidgets[widget.Name]
+			if !found || len(text) == 0 {
+				continue
+			}
+			var wrapped []string
+			for _, paragraph := range strings.Split(widget.Label+text, "\n") {
+				wrapped = append(wrapped, wordWrap(paragraph, columns)...)
+			}
+			if scroll := displayScrolls[widget.Name]; scroll > 0 && widget.Rows > 1 && len(wrapped) > widget.Rows {
+				start := 1 + scroll%(len(wrapped)-widget.Rows+1)
+				wrapped = append([]string{wrapped[0]}, wrapped[start:]...)
+			}
+			for index := 0; index < widget.Rows && index < len(wrapped); index++ {
+				if row := widget.Row + index; row >= 0 && row < rows {
+					lines[row] = wrapped[index]
+				}
+			}
+		}
+	}
+
+	if strings.Join(lines, "\n") == strings.Join(device.Lines, "\n") {
+		return
+	}
+	device.Lines = lines
+	device.Device.show(lines)
+}
+
+// wordWrap splits text into lines of at most width characters, breaking on spaces where it can, a width below 1
+// leaves the text as it is
+func wordWrap(text string, width int) []string {
+	if width < 1 {
+		return []string{text}
+	}
+
+	var lines []string
+	var line string
+
+	for _, word := range strings.Fields(text) {
+		for len(word) > width {
+			if len(line) > 0 {
+				lines = append(lines, line)
+				line = ""
+			}
+			lines = append(lines, word[:width])
+			word = word[width:]
+		}
+		if len(line) == 0 {
+			line = word
+		} else if len(line)+1+len(word) <= width {
+			line += " " + word
+		} else {
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if len(line) > 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+type hd44780Display struct {
+	rows    int
+	columns int
+}
+
+func (d *hd44780Display) size() (int, int) {
+	return d.rows, d.columns
+}
+
+func (d *hd44780Display) show(lines []string) {
+	var text [4]string
+	for index := range text {
+		if index < len(lines) {
+			text[index] = lines[index]
+		}
+	}
+	hd44780.LcdDisplay(text, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
+}
+
+type ssd1306Display struct {
+	rows    int
+	columns int
+}
+
+func (d *ssd1306Display) size() (int, int) {
+	return d.rows, d.columns
+}
+
+func (d *ssd1306Display) show(lines []string) {
+	for row, line := range lines {
+		oledWrite(false, row, 1, line)
+	}
+}
+
+type max7219Display struct {
+	columns int
+}
+
+func (d *max7219Display) size() (int, int) {
+	return 1, d.columns
+}
+
+func (d *max7219Display) show(lines []string) {
+	Max7219(Config.Global.Hardware.IO.Max7219.Max7219Cascaded, Config.Global.Hardware.IO.Max7219.SPIBus, Config.Global.Hardware.IO.Max7219.SPIDevice, Config.Global.Hardware.IO.Max7219.Brightness, lines[0])
+}
+
+type consoleDisplay struct {
+	name    string
+	rows    int
+	columns int
+}
+
+func (d *consoleDisplay) size() (int, int) {
+	return d.rows, d.columns
+}
+
+func (d *consoleDisplay) show(lines []string) {
+	for row, line := range lines {
+		log.Printf("info: Display %v Row %v [%-*v]\n", d.name, row, d.columns, line)
+	}
+}
@@ -0,0 +1,252 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * email.go talkkonnects function to send templated email through any smtp server
+ */
+
+package talkkonnect
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultSMTPHost = "smtp.gmail.com"
+	defaultSMTPPort = 587
+)
+
+// emailTemplateStruct holds the variables available to the smtp subject and message templates
+type emailTemplateStruct struct {
+	Ident    string
+	Username string
+	Server   string
+	Channel  string
+	Time     string
+	Fix      bool
+	GPSDate  string
+	GPSTime  string
+	Lat      string
+	Lon      string
+	MapsURL  string
+}
+
+func (b *Talkkonnect) emailTemplateData() emailTemplateStruct {
+	data := emailTemplateStruct{
+		Ident:    b.Ident,
+		Username: b.Username,
+		Server:   b.Address,
+		Time:     time.Now().Format("2006-01-02 15:04:05 MST"),
+	}
+	if IsConnected && b.Client != nil && b.Client.Self != nil && b.Client.Self.Channel != nil {
+		data.Channel = b.Client.Self.Channel.Name
+	}
+	if fix, fresh := gnssLatest(); fresh {
+		data.Fix = true
+		data.GPSDate = fix.Date
+		data.GPSTime = fix.Time
+		data.Lat = strconv.FormatFloat(fix.Lattitude, 'f', 6, 64)
+		data.Lon = strconv.FormatFloat(fix.Longitude, 'f', 6, 64)
+		data.MapsURL = "http://www.google.com/maps/place/" + data.Lat + "," + data.Lon
+	}
+	return data
+}
+
+// emailTemplate fills in a subject or message, text without template actions comes back as it is
+func emailTemplate(name string, text string, data emailTemplateStruct) string {
+	parsed, err := template.New(name).Parse(text)
+	if err != nil {
+		log.Printf("error: Email %v Template Error %v\n", name, err)
+		return text
+	}
+	var filled bytes.Buffer
+	if err := parsed.Execute(&filled, data); err != nil {
+		log.Printf("error: Email %v Template Error %v\n", name, err)
+		return text
+	}
+	return filled.String()
+}
+
+// emailRecipients returns every receiver in the config, a receiver may also hold a comma separated list
+func emailRecipients() []string {
+	var recipients []string
+	for _, receiver := range Config.Global.Software.SMTP.Receiver {
+		for _, address := range strings.Split(receiver, ",") {
+			if address = strings.TrimSpace(address); len(address) > 0 {
+				recipients = append(recipients, address)
+			}
+		}
+	}
+	return recipients
+}
+
+// sendEmail sends a plain text message to all the configured receivers using the smtp server, security and auth in the config
+func sendEmail(subject string, message string) error {
+	smtpConfig := Config.Global.Software.SMTP
+
+	host := smtpConfig.Host
+	if len(host) == 0 {
+		host = defaultSMTPHost
+	}
+	port := smtpConfig.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+	from := smtpConfig.From
+	if len(from) == 0 {
+		from = smtpConfig.Username
+	}
+	recipients := emailRecipients()
+	if len(recipients) == 0 {
+		return errors.New("no email receivers defined")
+	}
+
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: host, InsecureSkipVerify: smtpConfig.SkipVerify}
+
+	var conn net.Conn
+	var err error
+	if smtpConfig.Security == "tls" {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: 30 * time.Second}, "tcp", address, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", address, 30*time.Second)
+	}
+	if err != nil {
+		return fmt.Errorf("connecting to smtp server %v error %v", address, err)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp server %v error %v", address, err)
+	}
+	defer client.Close()
+
+	if smtpConfig.Security == "starttls" || len(smtpConfig.Security) == 0 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("smtp starttls error %v", err)
+			}
+		} else if smtpConfig.Security == "starttls" {
+			return fmt.Errorf("smtp server %v does not offer starttls", address)
+		}
+	}
+
+	if auth := emailAuth(smtpConfig.Auth, smtpConfig.Username, smtpConfig.Password, host); auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp auth error %v", err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("smtp sender %v refused %v", from, err)
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("smtp receiver %v refused %v", recipient, err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data error %v", err)
+	}
+	fmt.Fprintf(writer, "From: %v\r\n", from)
+	fmt.Fprintf(writer, "To: %v\r\n", strings.Join(recipients, ", "))
+	// the subject comes from templates filled with message text, a line break in it would start a new header
+	subject = strings.Join(strings.FieldsFunc(subject, func(r rune) bool { return r == '\r' || r == '\n' }), " ")
+	fmt.Fprintf(writer, "Subject: %v\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(writer, "Date: %v\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(writer, "MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	fmt.Fprint(writer, strings.ReplaceAll(message, "\n", "\r\n"))
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("smtp message refused %v", err)
+	}
+
+	if targetBoardHasGPIO() {
+		if LCDEnabled {
+			LcdText = [4]string{"nil", "nil", "nil", "Sending Email"}
+			go LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
+		}
+		if OLEDEnabled {
+			oledDisplay(false, 6, 1, "Sending Email")
+		}
+	}
+
+	log.Printf("info: Email %v Sent To %v\n", subject, strings.Join(recipients, ", "))
+	return client.Quit()
+}
+
+func emailAuth(method string, username string, password string, host string) smtp.Auth {
+	switch method {
+	case "none":
+		return nil
+	case "login":
+		return &loginAuth{username, password}
+	case "crammd5":
+		return smtp.CRAMMD5Auth(username, password)
+	}
+	if len(username) == 0 {
+		return nil
+	}
+	return smtp.PlainAuth("", username, password, host)
+}
+
+// loginAuth is the LOGIN mechanism still required by some servers, net/smtp only has PLAIN and CRAM-MD5
+type loginAuth struct {
+	username string
+	password string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS {
+		return "", nil, errors.New("login auth needs an encrypted connection")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	}
+	return nil, fmt.Errorf("unexpected login auth challenge %v", string(fromServer))
+}
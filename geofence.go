@@ -0,0 +1,259 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * geofence.go talkkonnects function to run actions when the position enters or leaves a circle or polygon geofence
+ */
+
+package talkkonnect
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultGeofenceConfirmFixes = 2
+	geofenceEnter               = "enter"
+	geofenceExit                = "exit"
+)
+
+// geofenceStateStruct follows one fence, inside only changes after confirm fixes in a row agree so a fix wandering
+// along the boundary does not fire actions over and over
+type geofenceStateStruct struct {
+	polygon [][2]float64
+	known   bool
+	inside  bool
+	pending int
+}
+
+// geofenceEventStruct is the payload published on mqtt and posted to webhooks
+type geofenceEventStruct struct {
+	Fence     string  `json:"fence"`
+	Event     string  `json:"event"`
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+	Time      string  `json:"time"`
+	Ident     string  `json:"ident"`
+	Username  string  `json:"username"`
+}
+
+// geofenceParsePolygon reads a polygon written as space separated lat,lon points
+func geofenceParsePolygon(text string) ([][2]float64, error) {
+	var polygon [][2]float64
+	for _, point := range strings.Fields(text) {
+		coordinates := strings.Split(point, ",")
+		if len(coordinates) != 2 {
+			return nil, fmt.Errorf("point %v is not lat,lon", point)
+		}
+		lat, err := strconv.ParseFloat(coordinates[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("point %v latitude %v", point, err)
+		}
+		lon, err := strconv.ParseFloat(coordinates[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("point %v longitude %v", point, err)
+		}
+		polygon = append(polygon, [2]float64{lat, lon})
+	}
+	if len(polygon) > 0 && len(polygon) < 3 {
+		return nil, errors.New("polygon needs at least 3 points")
+	}
+	return polygon, nil
+}
+
+// geofenceInPolygon casts a ray along the latitude and counts the edges it crosses, fine for fences that are not
+// near the poles or across the date line
+func geofenceInPolygon(lat float64, lon float64, polygon [][2]float64) bool {
+	var inside bool
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		if (polygon[i][1] > lon) != (polygon[j][1] > lon) &&
+			lat < (polygon[j][0]-polygon[i][0])*(lon-polygon[i][1])/(polygon[j][1]-polygon[i][1])+polygon[i][0] {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// geofence checks every fix against the enabled fences and runs the enter or exit actions of a fence when it changes
+func (b *Talkkonnect) geofence() {
+	if !Config.Global.Hardware.Geofence.Enabled {
+		return
+	}
+
+	confirm := Config.Global.Hardware.Geofence.ConfirmFixes
+	if confirm <= 0 {
+		confirm = defaultGeofenceConfirmFixes
+	}
+
+	states := make([]geofenceStateStruct, len(Config.Global.Hardware.Geofence.Fence))
+	for index, fence := range Config.Global.Hardware.Geofence.Fence {
+		if !fence.Enabled {
+			continue
+		}
+		polygon, err := geofenceParsePolygon(fence.Polygon)
+		if err != nil {
+			log.Printf("error: Geofence %v Polygon %v\n", fence.Name, err)
+			continue
+		}
+		states[index].polygon = polygon
+		log.Printf("info: Geofence %v Loaded\n", fence.Name)
+	}
+
+	subscriber := gnssSubscribe("geofence", time.Second)
+	for fix := range subscriber.C {
+		for index, fence := range Config.Global.Hardware.Geofence.Fence {
+			if !fence.Enabled {
+				continue
+			}
+			state := &states[index]
+
+			var inside bool
+			if len(state.polygon) > 0 {
+				inside = geofenceInPolygon(fix.Lattitude, fix.Longitude, state.polygon)
+			} else if fence.Circle.Radius > 0 {
+				inside = gnssDistance(fix.Lattitude, fix.Longitude, fence.Circle.Lat, fence.Circle.Lon) <= fence.Circle.Radius
+			} else {
+				continue
+			}
+
+			if state.known && inside == state.inside {
+				state.pending = 0
+				continue
+			}
+			if state.pending++; state.pending < confirm {
+				continue
+			}
+
+			// outside at start up is not an exit
+			fire := state.known || inside
+			state.known, state.inside, state.pending = true, inside, 0
+			if !fire {
+				continue
+			}
+
+			event := geofenceExit
+			if inside {
+				event = geofenceEnter
+			}
+			log.Printf("info: Geofence %v %v At %.6f,%.6f\n", fence.Name, strings.Title(event), fix.Lattitude, fix.Longitude)
+			b.geofenceActions(index, event, fix)
+		}
+		displayWidgetSet("geofence", geofenceInside(states))
+	}
+}
+
+// geofenceInside lists the fences the position is in for the display
+func geofenceInside(states []geofenceStateStruct) string {
+	var names []string
+	for index, state := range states {
+		if state.known && state.inside {
+			names = append(names, Config.Global.Hardware.Geofence.Fence[index].Name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+// geofenceActions runs the enabled actions of the fence for the event, an action without an event runs on both
+func (b *Talkkonnect) geofenceActions(index int, event string, fix GNSSDataStruct) {
+	fence := Config.Global.Hardware.Geofence.Fence[index]
+	payload, _ := json.Marshal(geofenceEventStruct{
+		Fence:     fence.Name,
+		Event:     event,
+		Latitude:  fix.Lattitude,
+		Longitude: fix.Longitude,
+		Time:      time.Now().Format(time.RFC3339),
+		Ident:     b.Ident,
+		Username:  b.Username,
+	})
+
+	for _, action := range fence.Action {
+		if !action.Enabled || (len(action.Event) > 0 && action.Event != event) {
+			continue
+		}
+		log.Printf("debug: Geofence %v %v Action %v %v\n", fence.Name, event, action.Type, action.Value)
+
+		switch action.Type {
+		case "channel":
+			if !IsConnected {
+				log.Printf("warn: Geofence %v Cannot Change Channel Not Connected\n", fence.Name)
+				continue
+			}
+			b.ChangeChannel(action.Value)
+		case "voicetarget":
+			target, found := findVoiceTarget(action.Value)
+			if !found {
+				log.Printf("warn: Geofence %v Voice Target %v Not Found\n", fence.Name, action.Value)
+				continue
+			}
+			b.cmdSendVoiceTargets(target)
+		case "radiochannel":
+			if !Config.Global.Hardware.Radio.Enabled {
+				log.Printf("warn: Geofence %v Cannot Change Radio Channel Radio Disabled\n", fence.Name)
+				continue
+			}
+			radioSetChannel(action.Value)
+		case "tts":
+			go b.Speak(action.Value, "local", Config.Global.Software.TTS.Volumelevel, 0, 1, Config.Global.Software.TTSMessages.TTSLanguage)
+		case "mqtt":
+			if !Config.Global.Software.RemoteControl.MQTT.Enabled || MQTTClient == nil {
+				log.Printf("warn: Geofence %v Needs MQTT Enabled\n", fence.Name)
+				continue
+			}
+			topic := action.Value
+			if len(topic) == 0 {
+				topic = Config.Global.Software.RemoteControl.MQTT.Settings.MQTTPubTopic
+			}
+			token := MQTTClient.Publish(topic, Config.Global.Software.RemoteControl.MQTT.Settings.MQTTQos, false, string(payload))
+			go func(name string) {
+				<-token.Done()
+				if token.Error() != nil {
+					log.Printf("error: Geofence %v MQTT Publish Error %v\n", name, token.Error())
+				}
+			}(fence.Name)
+		case "webhook":
+			go geofenceWebhook(fence.Name, action.Value, string(payload))
+		}
+	}
+}
+
+func geofenceWebhook(name string, url string, body string) {
+	response, err := forwardHTTPClient.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		log.Printf("error: Geofence %v Webhook Error %v\n", name, err)
+		return
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		log.Printf("error: Geofence %v Webhook Returned %v\n", name, response.Status)
+	}
+}
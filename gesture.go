@@ -0,0 +1,257 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * gesture.go talkkonnects function to detect short, long, double and hold presses on inputs
+ */
+
+package talkkonnect
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLongPressMsecs   = 1000
+	defaultDoublePressMsecs = 400
+	defaultHoldMsecs        = 800
+	defaultHoldRepeatMsecs  = 200
+	gesturePollMsecs        = 10
+)
+
+// gpioInputEventsMutex keeps the drain and refill of an input events channel together
+var gpioInputEventsMutex sync.Mutex
+
+type gestureCommandStruct struct {
+	Enabled  bool
+	Command  inputCommandStruct
+	Duration time.Duration
+	Repeat   time.Duration
+}
+
+type inputGesturesStruct struct {
+	Long   gestureCommandStruct
+	Double gestureCommandStruct
+	Hold   gestureCommandStruct
+}
+
+func (g inputGesturesStruct) enabled() bool {
+	return g.Long.Enabled || g.Double.Enabled || g.Hold.Enabled
+}
+
+// Commands that need to see both the press and the release of an input cannot be combined with gestures
+func gestureCapableCommand(command string) bool {
+	return !(command == "txptt" || command == "comment" || command == "tracking")
+}
+
+func loadInputGestures(name string, command inputCommandStruct, gestures []inputGestureStruct) inputGesturesStruct {
+	var loaded inputGesturesStruct
+
+	for _, gesture := range gestures {
+		if !gesture.Enabled {
+			continue
+		}
+		if !gestureCapableCommand(command.Command) {
+			log.Printf("warn: Input %v Command %v Does Not Support Gestures, Ignoring %v Gesture\n", name, command.Command, gesture.Type)
+			continue
+		}
+		gestureCommand, found := findInputCommand(name, gesture.Action, gesture.ParamName, gesture.ParamValue)
+		if !found || len(gesture.Action) == 0 {
+			log.Printf("error: Input %v %v Gesture Has Invalid Action %v\n", name, gesture.Type, gesture.Action)
+			continue
+		}
+
+		switch strings.ToLower(gesture.Type) {
+		case "long":
+			loaded.Long = gestureCommandStruct{true, gestureCommand, gestureMsecs(gesture.Msecs, defaultLongPressMsecs), 0}
+		case "double":
+			loaded.Double = gestureCommandStruct{true, gestureCommand, gestureMsecs(gesture.Msecs, defaultDoublePressMsecs), 0}
+		case "hold":
+			loaded.Hold = gestureCommandStruct{true, gestureCommand, gestureMsecs(gesture.Msecs, defaultHoldMsecs), gestureMsecs(gesture.RepeatMsecs, defaultHoldRepeatMsecs)}
+		default:
+			log.Printf("error: Input %v Has Unknown Gesture Type %v\n", name, gesture.Type)
+		}
+	}
+
+	if loaded.Long.Enabled && loaded.Hold.Enabled {
+		log.Printf("warn: Input %v Has Both Long And Hold Gestures, Hold Takes Precedence\n", name)
+		loaded.Long.Enabled = false
+	}
+
+	return loaded
+}
+
+func gestureMsecs(msecs int, defaultMsecs int) time.Duration {
+	if msecs <= 0 {
+		msecs = defaultMsecs
+	}
+	return time.Duration(msecs) * time.Millisecond
+}
+
+// event hands the new level of the input to its gesture decoder without waiting on it, a level the decoder has not
+// taken yet is replaced by the newer one so the decoder always ends up on the current level
+func (input *gpioInputStruct) event(pressed bool) {
+	gpioInputEventsMutex.Lock()
+	defer gpioInputEventsMutex.Unlock()
+	select {
+	case <-input.Events:
+	default:
+	}
+	input.Events <- pressed
+}
+
+// sample feeds a raw reading of the input and reports the new state once it has been stable for the debounce time
+func (input *gpioInputStruct) sample(state uint) bool {
+	if state != input.candidate {
+		input.candidate = state
+		input.candidateSince = time.Now()
+	}
+	if input.candidate != input.State && time.Since(input.candidateSince) >= input.Debounce {
+		input.State = input.candidate
+		return true
+	}
+	return false
+}
+
+// inputGestures turns the pressed/released events of an input into short, long, double and hold presses. Inputs
+// without gestures pass the press and release straight through so commands such as txptt keep working
+func (b *Talkkonnect) inputGestures(input *gpioInputStruct) {
+	var (
+		presses      int
+		held         bool
+		heldTimer    *time.Timer
+		heldC        <-chan time.Time
+		doubleTimer  *time.Timer
+		doubleC      <-chan time.Time
+		repeatTicker *time.Ticker
+		repeatC      <-chan time.Time
+		wasPressed   bool
+	)
+
+	stopHeld := func() {
+		if heldTimer != nil {
+			heldTimer.Stop()
+			heldTimer, heldC = nil, nil
+		}
+		if repeatTicker != nil {
+			repeatTicker.Stop()
+			repeatTicker, repeatC = nil, nil
+		}
+	}
+
+	// the decoder follows every press and release while offline, only the commands wait for the connection. Releases
+	// still go through so a transmit or squelch started before the drop ends
+	action := func(command inputCommandStruct, pressed bool) {
+		if !IsConnected && pressed {
+			log.Printf("debug: Input %v Command %v Skipped While Not Connected\n", input.Name, command.Command)
+			return
+		}
+		b.inputCommandAction(input.Name, command, pressed)
+	}
+
+	stopDouble := func() {
+		if doubleTimer != nil {
+			doubleTimer.Stop()
+			doubleTimer, doubleC = nil, nil
+		}
+	}
+
+	for {
+		select {
+		case pressed := <-input.Events:
+			// coalescing can hand over the same level twice
+			if pressed == wasPressed {
+				continue
+			}
+			wasPressed = pressed
+
+			if !input.Gestures.enabled() {
+				action(input.Command, pressed)
+				continue
+			}
+
+			if pressed {
+				held = false
+				presses++
+				if presses == 2 && input.Gestures.Double.Enabled {
+					stopDouble()
+					presses = 0
+					held = true
+					log.Printf("debug: Input %v Double Press\n", input.Name)
+					action(input.Gestures.Double.Command, true)
+					continue
+				}
+				if input.Gestures.Hold.Enabled {
+					heldTimer = time.NewTimer(input.Gestures.Hold.Duration)
+					heldC = heldTimer.C
+				} else if input.Gestures.Long.Enabled {
+					heldTimer = time.NewTimer(input.Gestures.Long.Duration)
+					heldC = heldTimer.C
+				}
+				continue
+			}
+
+			stopHeld()
+			if held || presses == 0 {
+				held = false
+				presses = 0
+				continue
+			}
+			if input.Gestures.Double.Enabled {
+				doubleTimer = time.NewTimer(input.Gestures.Double.Duration)
+				doubleC = doubleTimer.C
+				continue
+			}
+			presses = 0
+			action(input.Command, true)
+
+		case <-heldC:
+			heldTimer, heldC = nil, nil
+			held = true
+			presses = 0
+			stopDouble()
+			if input.Gestures.Hold.Enabled {
+				log.Printf("debug: Input %v Hold Press\n", input.Name)
+				action(input.Gestures.Hold.Command, true)
+				repeatTicker = time.NewTicker(input.Gestures.Hold.Repeat)
+				repeatC = repeatTicker.C
+			} else {
+				log.Printf("debug: Input %v Long Press\n", input.Name)
+				action(input.Gestures.Long.Command, true)
+			}
+
+		case <-repeatC:
+			action(input.Gestures.Hold.Command, true)
+
+		case <-doubleC:
+			doubleTimer, doubleC = nil, nil
+			presses = 0
+			action(input.Command, true)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * gnss.go talkkonnects function to keep reading the gnss receiver and hand the latest fix to subscribers at their own rate
+ */
+
+package talkkonnect
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/adrianmo/go-nmea"
+	"github.com/jacobsa/go-serial/serial"
+)
+
+const (
+	defaultGNSSStaleSecs   = 10
+	gnssReopenSecs         = 5
+	gnssWaitForFixPollMsec = 100
+	gnssRequestWaitSecs    = 3
+	earthRadiusMetres      = 6371000
+)
+
+// gnssSubscriberStruct gets at most one fix per interval, a subscriber that is still busy only ever has the newest fix waiting
+type gnssSubscriberStruct struct {
+	Name     string
+	C        chan GNSSDataStruct
+	interval time.Duration
+	last     time.Time
+}
+
+var (
+	gnssLatestFix   GNSSDataStruct
+	gnssSubscribers []*gnssSubscriberStruct
+	gnssMutex       sync.Mutex
+	gnssReaderOnce  sync.Once
+)
+
+// age is how long ago the fix was received
+func (fix GNSSDataStruct) age() time.Duration {
+	if fix.DateTime.IsZero() {
+		return time.Duration(1<<63 - 1)
+	}
+	return time.Since(fix.DateTime)
+}
+
+func gnssStaleAfter() time.Duration {
+	if Config.Global.Hardware.GPS.StaleSecs > 0 {
+		return time.Duration(Config.Global.Hardware.GPS.StaleSecs) * time.Second
+	}
+	return defaultGNSSStaleSecs * time.Second
+}
+
+// gnssLatest returns the last fix and whether it is valid and newer than the stale time
+func gnssLatest() (GNSSDataStruct, bool) {
+	gnssMutex.Lock()
+	defer gnssMutex.Unlock()
+	return gnssLatestFix, gnssLatestFix.Validity == "A" && gnssLatestFix.age() <= gnssStaleAfter()
+}
+
+// gnssSubscribe registers a consumer that wants a fix no more often than every interval, zero means every fix
+func gnssSubscribe(name string, interval time.Duration) *gnssSubscriberStruct {
+	gnssMutex.Lock()
+	defer gnssMutex.Unlock()
+
+	subscriber := &gnssSubscriberStruct{Name: name, C: make(chan GNSSDataStruct, 1), interval: interval}
+	gnssSubscribers = append(gnssSubscribers, subscriber)
+	log.Printf("debug: GNSS Subscriber %v Every %v\n", name, interval)
+	return subscriber
+}
+
+func gnssUnsubscribe(subscriber *gnssSubscriberStruct) {
+	gnssMutex.Lock()
+	defer gnssMutex.Unlock()
+
+	for index, registered := range gnssSubscribers {
+		if registered == subscriber {
+			gnssSubscribers = append(gnssSubscribers[:index], gnssSubscribers[index+1:]...)
+			return
+		}
+	}
+}
+
+// gnssPublish stores a complete fix as the latest and offers it to every subscriber that is due without waiting on any of them
+func gnssPublish(fix GNSSDataStruct) {
+	gnssMutex.Lock()
+	defer gnssMutex.Unlock()
+
+	gnssLatestFix = fix
+	if fix.Validity != "A" {
+		return
+	}
+
+	now := time.Now()
+	for _, subscriber := range gnssSubscribers {
+		if now.Sub(subscriber.last) < subscriber.interval {
+			continue
+		}
+		select {
+		case <-subscriber.C:
+		default:
+		}
+		select {
+		case subscriber.C <- fix:
+			subscriber.last = now
+		default:
+		}
+	}
+}
+
+// startGNSSReader starts the one reader of the configured position source, later calls do nothing
+func startGNSSReader() {
+	if !Config.Global.Hardware.GPS.Enabled {
+		return
+	}
+	gnssReaderOnce.Do(func() {
+		switch Config.Global.Hardware.GPS.Source {
+		case "gpsd":
+			go gpsdReader()
+		case "replay":
+			go gnssReplayReader()
+		default:
+			go gnssSerialReader()
+		}
+	})
+}
+
+// gnssSerialReader keeps the serial port open and parses nmea sentences until the port fails, then opens it again
+func gnssSerialReader() {
+	for {
+		if err := gnssReadSerial(); err != nil {
+			log.Printf("error: GNSS Serial Port %v %v Reopening In %v Seconds\n", Config.Global.Hardware.GPS.Port, err, gnssReopenSecs)
+		}
+		time.Sleep(gnssReopenSecs * time.Second)
+	}
+}
+
+func gnssReadSerial() error {
+	if Config.Global.Hardware.GPS.Port == "" {
+		return errors.New("gnss port not specified")
+	}
+
+	if Config.Global.Hardware.GPS.Even && Config.Global.Hardware.GPS.Odd {
+		return errors.New("can't specify both even and odd parity")
+	}
+
+	parity := serial.PARITY_NONE
+
+	if Config.Global.Hardware.GPS.Even {
+		parity = serial.PARITY_EVEN
+	} else if Config.Global.Hardware.GPS.Odd {
+		parity = serial.PARITY_ODD
+	}
+
+	options := serial.OpenOptions{
+		PortName:               Config.Global.Hardware.GPS.Port,
+		BaudRate:               Config.Global.Hardware.GPS.Baud,
+		DataBits:               Config.Global.Hardware.GPS.DataBits,
+		StopBits:               Config.Global.Hardware.GPS.StopBits,
+		MinimumReadSize:        Config.Global.Hardware.GPS.MinRead,
+		InterCharacterTimeout:  Config.Global.Hardware.GPS.CharTimeOut,
+		ParityMode:             parity,
+		Rs485Enable:            Config.Global.Hardware.GPS.Rs485,
+		Rs485RtsHighDuringSend: Config.Global.Hardware.GPS.Rs485HighDuringSend,
+		Rs485RtsHighAfterSend:  Config.Global.Hardware.GPS.Rs485HighAfterSend,
+	}
+
+	port, err := serial.Open(options)
+	if err != nil {
+		return fmt.Errorf("cannot open serial port %v", err)
+	}
+	defer port.Close()
+
+	if Config.Global.Hardware.GPS.TxData != "" {
+		txData_, err := hex.DecodeString(Config.Global.Hardware.GPS.TxData)
+		if err != nil {
+			return errors.New("cannot decode hex data")
+		}
+		log.Println("debug: Sending To Serial ", hex.EncodeToString(txData_))
+		if count, err := port.Write(txData_); err != nil {
+			return errors.New("error writing to serial port")
+		} else {
+			log.Printf("debug: Wrote %v Bytes To Serial\n", count)
+		}
+	}
+
+	if !Config.Global.Hardware.GPS.Rx {
+		return errors.New("gnss rx disabled in config")
+	}
+
+	log.Printf("info: GNSS Reading From Serial Port %v\n", Config.Global.Hardware.GPS.Port)
+	return gnssReadNMEA(port)
+}
+
+// gnssReadNMEA builds a fix from the GGA and GSV sentences of each epoch and publishes it when the RMC sentence arrives
+func gnssReadNMEA(source io.Reader) error {
+	var fix GNSSDataStruct
+	scanner := bufio.NewScanner(source)
+
+	for scanner.Scan() {
+		s, err := nmea.Parse(scanner.Text())
+		if err != nil {
+			continue
+		}
+
+		switch s.DataType() {
+		case nmea.TypeRMC:
+			m := s.(nmea.RMC)
+			fix.DateTime = time.Now().UTC()
+			fix.Date = fmt.Sprintf("%v", m.Date)
+			fix.Time = fmt.Sprintf("%v", m.Time)
+			fix.Validity = fmt.Sprintf("%v", m.Validity)
+			fix.Lattitude = m.Latitude
+			fix.Longitude = m.Longitude
+			fix.Speed = m.Speed
+			fix.Course = m.Course
+			fix.Variation = m.Variation
+			fix.RMCRaw = m.Raw
+			if fix.Lattitude == 0 && fix.Longitude == 0 {
+				fix.Validity = "V"
+			}
+			gnssPublish(fix)
+		case nmea.TypeGGA:
+			m := s.(nmea.GGA)
+			fix.FixQuality = m.FixQuality
+			fix.SatsInUse = m.NumSatellites
+			fix.HDOP = m.HDOP
+			fix.Altitude = m.Altitude
+		case nmea.TypeGSV:
+			m := s.(nmea.GSV)
+			fix.SatsInView = m.NumberSVsInView
+			if m.MessageNumber == 1 {
+				for i := range m.Info {
+					if i < len(fix.GSVData) {
+						fix.GSVData[i].PRNNumber = m.Info[i].SVPRNNumber
+						fix.GSVData[i].SNR = m.Info[i].SNR
+						fix.GSVData[i].Azimuth = m.Info[i].Azimuth
+					}
+				}
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// gnssDistance is the great circle distance in metres between two positions
+func gnssDistance(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	deltaPhi := (lat2 - lat1) * math.Pi / 180
+	deltaLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(deltaPhi/2)*math.Sin(deltaPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(deltaLambda/2)*math.Sin(deltaLambda/2)
+	return earthRadiusMetres * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// gnssBearing is the initial bearing in degrees from true north going from the first position to the second
+func gnssBearing(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	deltaLambda := (lon2 - lon1) * math.Pi / 180
+
+	y := math.Sin(deltaLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(deltaLambda)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// gnssWaitForFix waits up to timeout for a fresh fix
+func gnssWaitForFix(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if _, fresh := gnssLatest(); fresh {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(gnssWaitForFixPollMsec * time.Millisecond)
+	}
+}
+
+// gnssMQTTPublisher publishes the position as json on the mqtt publish topic every publish interval
+func gnssMQTTPublisher() {
+	subscriber := gnssSubscribe("mqtt", time.Duration(Config.Global.Hardware.GPS.MQTTPublishSecs)*time.Second)
+	for fix := range subscriber.C {
+		if MQTTClient == nil || !MQTTClient.IsConnected() {
+			continue
+		}
+		payload, _ := json.Marshal(struct {
+			Ident     string  `json:"ident"`
+			Time      string  `json:"time"`
+			Latitude  float64 `json:"lat"`
+			Longitude float64 `json:"lon"`
+			Speed     float64 `json:"speed"`
+			Course    float64 `json:"course"`
+			Altitude  float64 `json:"altitude"`
+			HDOP      float64 `json:"hdop"`
+		}{Ident[AccountIndex], fix.DateTime.Format(time.RFC3339), fix.Lattitude, fix.Longitude, fix.Speed, fix.Course, fix.Altitude, fix.HDOP})
+		MQTTPublish(string(payload))
+	}
+}
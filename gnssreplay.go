@@ -0,0 +1,208 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * gnssreplay.go talkkonnects function to replay a recorded nmea log or gpx track as the gnss source for testing without satellites
+ */
+
+package talkkonnect
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/adrianmo/go-nmea"
+)
+
+const (
+	defaultGNSSReplaySpeed = 1
+	gnssReplayMaxGapSecs   = 60
+)
+
+// gpxFileStruct has the track points of a gpx file, waypoints and routes are not replayed
+type gpxFileStruct struct {
+	XMLName xml.Name `xml:"gpx"`
+	Track   []struct {
+		Segment []struct {
+			Point []gpxPointStruct `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+type gpxPointStruct struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Ele  float64 `xml:"ele"`
+	Time string  `xml:"time"`
+	Sat  int64   `xml:"sat"`
+	HDOP float64 `xml:"hdop"`
+}
+
+func gnssReplaySpeed() float64 {
+	if Config.Global.Hardware.GPS.Replay.Speed > 0 {
+		return Config.Global.Hardware.GPS.Replay.Speed
+	}
+	return defaultGNSSReplaySpeed
+}
+
+// gnssReplayReader plays the replay file through the nmea parser once, or over and over when loop is set
+func gnssReplayReader() {
+	for {
+		if err := gnssReplay(); err != nil && err != io.EOF {
+			log.Printf("error: GNSS Replay Of %v Stopped %v\n", Config.Global.Hardware.GPS.Replay.File, err)
+			return
+		}
+		if !Config.Global.Hardware.GPS.Replay.Loop {
+			log.Printf("info: GNSS Replay Of %v Finished\n", Config.Global.Hardware.GPS.Replay.File)
+			return
+		}
+		log.Printf("debug: GNSS Replay Of %v Starting Again\n", Config.Global.Hardware.GPS.Replay.File)
+	}
+}
+
+// gnssReplay feeds the file as nmea sentences at the recorded pace into gnssReadNMEA, a gpx track is turned into
+// RMC and GGA sentences first so both go through the same parser as a real receiver
+func gnssReplay() error {
+	path := Config.Global.Hardware.GPS.Replay.File
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	log.Printf("info: GNSS Replaying %v At %vx Speed\n", path, gnssReplaySpeed())
+
+	reader, writer := io.Pipe()
+	go func() {
+		if strings.EqualFold(filepath.Ext(path), ".gpx") {
+			writer.CloseWithError(gnssReplayGPX(file, writer))
+		} else {
+			writer.CloseWithError(gnssReplayNMEA(file, writer))
+		}
+	}()
+	defer reader.Close()
+
+	return gnssReadNMEA(reader)
+}
+
+// gnssReplayNMEA copies the sentences of an nmea log, waiting before each RMC sentence for the time that passed
+// between it and the last one in the recording
+func gnssReplayNMEA(source io.Reader, sink io.Writer) error {
+	var last time.Time
+	scanner := bufio.NewScanner(source)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+		if s, err := nmea.Parse(line); err == nil && s.DataType() == nmea.TypeRMC {
+			m := s.(nmea.RMC)
+			recorded := time.Date(2000+m.Date.YY, time.Month(m.Date.MM), m.Date.DD, m.Time.Hour, m.Time.Minute, m.Time.Second, m.Time.Millisecond*int(time.Millisecond), time.UTC)
+			gnssReplayWait(last, recorded)
+			last = recorded
+		}
+		if _, err := fmt.Fprintln(sink, line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// gnssReplayGPX turns each track point into RMC and GGA sentences, speed and course come from the step to the
+// previous point
+func gnssReplayGPX(source io.Reader, sink io.Writer) error {
+	var gpx gpxFileStruct
+	if err := xml.NewDecoder(source).Decode(&gpx); err != nil {
+		return fmt.Errorf("cannot decode gpx %v", err)
+	}
+
+	var points []gpxPointStruct
+	for _, track := range gpx.Track {
+		for _, segment := range track.Segment {
+			points = append(points, segment.Point...)
+		}
+	}
+	if len(points) == 0 {
+		return errors.New("gpx has no track points")
+	}
+
+	var fix, previous GNSSDataStruct
+	for index, point := range points {
+		recorded, err := time.Parse(time.RFC3339, point.Time)
+		if err != nil {
+			// points without a time are replayed one second apart
+			recorded = previous.DateTime.Add(time.Second)
+			if index == 0 {
+				recorded = time.Now().UTC()
+			}
+		}
+
+		fix = GNSSDataStruct{
+			DateTime:   recorded.UTC(),
+			Validity:   "A",
+			FixQuality: "1",
+			Lattitude:  point.Lat,
+			Longitude:  point.Lon,
+			Altitude:   point.Ele,
+			SatsInUse:  point.Sat,
+			HDOP:       point.HDOP,
+		}
+		if index > 0 {
+			if elapsed := fix.DateTime.Sub(previous.DateTime).Seconds(); elapsed > 0 {
+				fix.Speed = gnssDistance(previous.Lattitude, previous.Longitude, fix.Lattitude, fix.Longitude) / elapsed * knotsPerMetreSec
+			}
+			fix.Course = gnssBearing(previous.Lattitude, previous.Longitude, fix.Lattitude, fix.Longitude)
+			gnssReplayWait(previous.DateTime, fix.DateTime)
+		}
+		previous = fix
+
+		if _, err := fmt.Fprintf(sink, "%s\r\n%s\r\n", gnssGGASentence(fix), gnssRMCSentence(fix)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gnssReplayWait sleeps for the recorded gap divided by the replay speed, gaps that go backwards or are too long
+// to be a live recording are replayed as one second
+func gnssReplayWait(last time.Time, recorded time.Time) {
+	if last.IsZero() {
+		return
+	}
+	gap := recorded.Sub(last)
+	if gap <= 0 || gap > gnssReplayMaxGapSecs*time.Second {
+		gap = time.Second
+	}
+	time.Sleep(time.Duration(float64(gap) / gnssReplaySpeed()))
+}
@@ -34,6 +34,7 @@ package talkkonnect
 import (
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/stianeikeland/go-rpio"
@@ -44,495 +45,117 @@ import (
 
 //Variables for Input Buttons/Switches
 var (
-	TxButtonUsed  bool
-	TxButton      gpio.Pin
-	TxButtonPin   uint
-	TxButtonState uint
-
-	TxToggleUsed  bool
-	TxToggle      gpio.Pin
-	TxTogglePin   uint
-	TxToggleState uint
-
-	UpButtonUsed  bool
-	UpButton      gpio.Pin
-	UpButtonPin   uint
-	UpButtonState uint
-
-	DownButtonUsed  bool
-	DownButton      gpio.Pin
-	DownButtonPin   uint
-	DownButtonState uint
-
-	PanicUsed        bool
-	PanicButton      gpio.Pin
-	PanicButtonPin   uint
-	PanicButtonState uint
-
-	StreamToggleUsed  bool
-	StreamButton      gpio.Pin
-	StreamButtonPin   uint
-	StreamButtonState uint
-
-	CommentUsed        bool
-	CommentButton      gpio.Pin
-	CommentButtonPin   uint
-	CommentButtonState uint
+	GPIOInputs []gpioInputStruct
 
 	RotaryUsed bool
 	RotaryA    gpio.Pin
 	RotaryB    gpio.Pin
 	RotaryAPin uint
 	RotaryBPin uint
+)
 
-	RotaryButtonUsed  bool
-	RotaryButton      gpio.Pin
-	RotaryButtonPin   uint
-	RotaryButtonState uint
-
-	VolUpButtonUsed  bool
-	VolUpButton      gpio.Pin
-	VolUpButtonPin   uint
-	VolUpButtonState uint
-
-	VolDownButtonUsed  bool
-	VolDownButton      gpio.Pin
-	VolDownButtonPin   uint
-	VolDownButtonState uint
-
-	TrackingUsed        bool
-	TrackingButton      gpio.Pin
-	TrackingButtonPin   uint
-	TrackingButtonState uint
-
-	MQTT0ButtonUsed  bool
-	MQTT0Button      gpio.Pin
-	MQTT0ButtonPin   uint
-	MQTT0ButtonState uint
-
-	MQTT1ButtonUsed  bool
-	MQTT1Button      gpio.Pin
-	MQTT1ButtonPin   uint
-	MQTT1ButtonState uint
+// Pins without an action attribute fall back to the command of their legacy name
+var defaultInputCommands = map[string]inputCommandStruct{
+	"txptt":        {"txptt", "", ""},
+	"txtoggle":     {"txtoggle", "", ""},
+	"channelup":    {"channelup", "", ""},
+	"channeldown":  {"channeldown", "", ""},
+	"panic":        {"panic", "", ""},
+	"streamtoggle": {"stream-toggle", "", ""},
+	"comment":      {"comment", "", ""},
+	"rotarybutton": {"rotaryfunction", "", ""},
+	"volup":        {"volumeup", "", ""},
+	"voldown":      {"volumedown", "", ""},
+	"tracking":     {"tracking", "", ""},
+	"mqtt0":        {"mqttpubpayloadset", "buttonitem", "0"},
+	"mqtt1":        {"mqttpubpayloadset", "buttonitem", "1"},
+	"nextserver":   {"serverup", "", ""},
+	"repeatertone": {"repeatertoneplay", "", ""},
+	"radioscan":    {"radioscan", "", ""},
+	"radiosquelch": {"radiosquelch", "", ""},
+}
 
-	NextServerButtonUsed  bool
-	NextServerButton      gpio.Pin
-	NextServerButtonPin   uint
-	NextServerButtonState uint
+type inputCommandStruct struct {
+	Command    string
+	ParamName  string
+	ParamValue string
+}
 
-	RepeaterToneButtonUsed  bool
-	RepeaterToneButton      gpio.Pin
-	RepeaterToneButtonPin   uint
-	RepeaterToneButtonState uint
-)
+type gpioInputStruct struct {
+	Name           string
+	PinNo          uint
+	Pin            gpio.Pin
+	State          uint
+	Command        inputCommandStruct
+	Gestures       inputGesturesStruct
+	Debounce       time.Duration
+	Events         chan bool
+	candidate      uint
+	candidateSince time.Time
+}
 
 var D [8]*mcp23017.Device
 
 func (b *Talkkonnect) initGPIO() {
 
-	if Config.Global.Hardware.TargetBoard != "rpi" {
+	if !targetBoardHasGPIO() {
 		return
 	}
 
-	if err := rpio.Open(); err != nil {
-		log.Println("error: GPIO Error, ", err)
-		b.GPIOEnabled = false
+	if gpioSimulated() {
+		b.GPIOEnabled = true
+		loadGPIOInputs()
+		b.initGPIOSim()
 		return
 	}
-	b.GPIOEnabled = true
 
-	// Handle GPIO Expander Pins As Outputs if Enabled
-	if Config.Global.Hardware.IO.GPIOExpander.Enabled {
-		for _, gpioExpander := range Config.Global.Hardware.IO.GPIOExpander.Chip {
-			if Config.Global.Hardware.IO.GPIOExpander.Chip[gpioExpander.ID].Enabled {
-				log.Printf("debug: Setting up MCP23017 GPIO Expander on IC2 Bus %v Device No %v\n", gpioExpander.I2Cbus, gpioExpander.MCP23017Device)
-				var err error
-				D[gpioExpander.MCP23017Device], err = mcp23017.Open(gpioExpander.I2Cbus, gpioExpander.MCP23017Device)
-				if err != nil {
-					// log.Println("error: Unable To Setup Expander GPIO Chip On I2C Bus " + strconv.Itoa(int(gpioExpander.I2Cbus)) + " Device " + strconv.Itoa(int(gpioExpander.MCP23017Device)) + " With " + err.Error())
-					return
-				}
-				for y := 0; y < 16; y++ {
-					if Config.Global.Hardware.IO.Pins.Pin[y].Enabled && Config.Global.Hardware.IO.Pins.Pin[y].Direction == "output" && Config.Global.Hardware.IO.Pins.Pin[y].Type == "mcp23017" {
-						log.Printf("debug: Pin %v Enabled as Output\n", y)
-						err := D[gpioExpander.MCP23017Device].PinMode(uint8(y), mcp23017.OUTPUT)
-						if err != nil {
-							log.Printf("error: Cannot Set Pin %v as Output With Error %v\n", y, err)
-						}
-					}
-					if Config.Global.Hardware.IO.Pins.Pin[y].Enabled && Config.Global.Hardware.IO.Pins.Pin[y].Direction == "input" && Config.Global.Hardware.IO.Pins.Pin[y].Type == "mcp23017" {
-						log.Printf("debug: Pin %v Enabled as Input\n", y)
-						err := D[gpioExpander.MCP23017Device].PinMode(uint8(y), mcp23017.INPUT)
-						if err != nil {
-							log.Printf("error: Cannot Set Pin %v as Input With Error %v\n", y, err)
-						}
-					}
-				}
-			}
+	// rpio is only used to set the internal pull ups and only works on the raspberry pi
+	if Config.Global.Hardware.TargetBoard == "rpi" && Config.Global.Hardware.IO.GPIOBackend.Name != "chardev" {
+		if err := rpio.Open(); err != nil {
+			log.Println("error: GPIO Error, ", err)
+			b.GPIOEnabled = false
+			return
 		}
 	}
+	b.GPIOEnabled = true
 
-	//handle inputs on RPI GPIO
-	for _, io := range Config.Global.Hardware.IO.Pins.Pin {
-		if io.Enabled && io.Direction == "input" && io.Type == "gpio" {
-			if io.Name == "txptt" && io.PinNo > 0 {
-				log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
-				TxButtonPinPullUp := rpio.Pin(io.PinNo)
-				TxButtonPinPullUp.PullUp()
-				TxButtonUsed = true
-				TxButtonPin = io.PinNo
-			}
-			if io.Name == "txtoggle" && io.PinNo > 0 {
-				log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
-				TxTogglePinPullUp := rpio.Pin(io.PinNo)
-				TxTogglePinPullUp.PullUp()
-				TxToggleUsed = true
-				TxTogglePin = io.PinNo
-			}
-			if io.Name == "channelup" && io.PinNo > 0 {
-				log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
-				ChannelUpPinPullUp := rpio.Pin(io.PinNo)
-				ChannelUpPinPullUp.PullUp()
-				UpButtonUsed = true
-				UpButtonPin = io.PinNo
-			}
-			if io.Name == "channeldown" && io.PinNo > 0 {
-				log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
-				ChannelDownPinPullUp := rpio.Pin(io.PinNo)
-				ChannelDownPinPullUp.PullUp()
-				DownButtonUsed = true
-				DownButtonPin = io.PinNo
-			}
-			if io.Name == "panic" && io.PinNo > 0 {
-				log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
-				PanicPinPullUp := rpio.Pin(io.PinNo)
-				PanicPinPullUp.PullUp()
-				PanicUsed = true
-				PanicButtonPin = io.PinNo
-			}
-			if io.Name == "streamtoggle" && io.PinNo > 0 {
-				log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
-				StreamTogglePinPullUp := rpio.Pin(io.PinNo)
-				StreamTogglePinPullUp.PullUp()
-				StreamToggleUsed = true
-				StreamButtonPin = io.PinNo
-			}
-			if io.Name == "comment" && io.PinNo > 0 {
-				log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
-				CommentPinPullUp := rpio.Pin(io.PinNo)
-				CommentPinPullUp.PullUp()
-				CommentUsed = true
-				CommentButtonPin = io.PinNo
-			}
-			if io.Name == "rotarya" && io.PinNo > 0 {
-				log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
-				RotaryAPinPullUp := rpio.Pin(io.PinNo)
-				RotaryAPinPullUp.PullUp()
-				RotaryUsed = true
-				RotaryAPin = io.PinNo
-			}
-			if io.Name == "rotaryb" && io.PinNo > 0 {
-				log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
-				RotaryBPinPullUp := rpio.Pin(io.PinNo)
-				RotaryBPinPullUp.PullUp()
-				RotaryUsed = true
-				RotaryBPin = io.PinNo
-			}
-			if io.Name == "rotarybutton" && io.PinNo > 0 {
-				log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
-				RotaryButtonPullUp := rpio.Pin(io.PinNo)
-				RotaryButtonPullUp.PullUp()
-				RotaryButtonUsed = true
-				RotaryButtonPin = io.PinNo
-			}
-			if io.Name == "volup" && io.PinNo > 0 {
-				log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
-				VolUpPinPullUp := rpio.Pin(io.PinNo)
-				VolUpPinPullUp.PullUp()
-				VolUpButtonUsed = true
-				VolUpButtonPin = io.PinNo
-			}
-			if io.Name == "voldown" && io.PinNo > 0 {
-				log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
-				VolDownPinPullUp := rpio.Pin(io.PinNo)
-				VolDownPinPullUp.PullUp()
-				VolDownButtonUsed = true
-				VolDownButtonPin = io.PinNo
-			}
-			if io.Name == "tracking" && io.PinNo > 0 {
-				log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
-				TrackingPinPullUp := rpio.Pin(io.PinNo)
-				TrackingPinPullUp.PullUp()
-				TrackingUsed = true
-				TrackingButtonPin = io.PinNo
-			}
-			if io.Name == "mqtt0" && io.PinNo > 0 {
-				log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
-				MQTT0PinPullUp := rpio.Pin(io.PinNo)
-				MQTT0PinPullUp.PullUp()
-				MQTT0ButtonUsed = true
-				MQTT0ButtonPin = io.PinNo
-			}
-			if io.Name == "mqtt1" && io.PinNo > 0 {
-				log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
-				MQTT1PinPullUp := rpio.Pin(io.PinNo)
-				MQTT1PinPullUp.PullUp()
-				MQTT1ButtonUsed = true
-				MQTT1ButtonPin = io.PinNo
-			}
-			if io.Name == "nextserver" && io.PinNo > 0 {
-				log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
-				NextServerPinPullUp := rpio.Pin(io.PinNo)
-				NextServerPinPullUp.PullUp()
-				NextServerButtonUsed = true
-				NextServerButtonPin = io.PinNo
-			}
-			if io.Name == "repeatertone" && io.PinNo > 0 {
-				log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
-				RepeaterToneButtonPinPullUp := rpio.Pin(io.PinNo)
-				RepeaterToneButtonPinPullUp.PullUp()
-				RepeaterToneButtonUsed = true
-				RepeaterToneButtonPin = io.PinNo
-			}
-		}
-	}
-
-	if TxButtonUsed || TxToggleUsed || UpButtonUsed || DownButtonUsed || PanicUsed || StreamToggleUsed || CommentUsed || RotaryUsed || RotaryButtonUsed || VolUpButtonUsed || VolDownButtonUsed || TrackingUsed || MQTT0ButtonUsed || MQTT1ButtonUsed || NextServerButtonUsed || RepeaterToneButtonUsed {
-		rpio.Close()
-	}
-
-	if TxButtonUsed {
-		TxButton = gpio.NewInput(TxButtonPin)
-		go func() {
-			for {
-				if IsConnected {
-					time.Sleep(150 * time.Millisecond)
-					currentState, err := TxButton.Read()
-					if currentState != TxButtonState && err == nil {
-						TxButtonState = currentState
-						if b.Stream != nil {
-							if TxButtonState == 1 {
-								if isTx {
-									isTx = false
-									b.TransmitStop(true)
-									playIOMedia("iotxpttstop")
-									if Config.Global.Software.Settings.TxCounter {
-										txcounter++
-										log.Println("debug: Tx Button Count ", txcounter)
-									}
-								}
-							} else {
-								log.Println("debug: Tx Button is pressed")
-								if !isTx {
-									isTx = true
-									playIOMedia("iotxpttstart")
-								} else {
-									time.Sleep(150 * time.Millisecond)
-								}
-								txlockout := &TXLockOut
-								if Config.Global.Software.Settings.TXLockOut && *txlockout {
-									log.Println("warn: TX Lockout Stopping Transmission")
-									eventSound := findEventSound("txlockout")
-									if eventSound.Enabled {
-										if v, err := strconv.Atoi(eventSound.Volume); err == nil {
-											localMediaPlayer(eventSound.FileName, v, eventSound.Blocking, 0, 1)
-											log.Printf("debug: Playing txlockout Sound")
-										}
-									}
-								} else {
-									b.TransmitStart()
-								}
-
-							}
-						}
-					}
-				} else {
-					time.Sleep(1 * time.Second)
-				}
-			}
-		}()
-	}
-
-	if TxToggleUsed {
-		TxToggle = gpio.NewInput(TxTogglePin)
-		go func() {
-			var prevState uint = 1
-			for {
-				if IsConnected {
-					currentState, err := TxToggle.Read()
-					time.Sleep(150 * time.Millisecond)
-					if err != nil {
-						log.Println("error: Error Opening TXToggle Pin")
-						break
-					}
-					if currentState != prevState {
-						isTx = !isTx
-						if isTx {
-							b.TransmitStop(true)
-							log.Println("debug: Toggle Stopped Transmitting")
-							playIOMedia("iotxtogglestop")
-							for {
-								currentState, err := TxToggle.Read()
-								time.Sleep(150 * time.Millisecond)
-								if currentState == 1 && err == nil {
-									break
-								}
-							}
-							prevState = 1
-							time.Sleep(150 * time.Millisecond)
-						}
-						if !isTx {
-							if Config.Global.Software.Sounds.Input.Enabled {
-								var inputEventSoundFile InputEventSoundFileStruct = findInputEventSoundFile("txtogglestart")
-								if inputEventSoundFile.Enabled {
-									go aplayLocal(inputEventSoundFile.File)
-								}
-							}
-							playIOMedia("txtogglestart")
-							b.TransmitStart()
-							log.Println("debug: Toggle Started Transmitting")
-							for {
-								currentState, err := TxToggle.Read()
-								time.Sleep(150 * time.Millisecond)
-								if currentState == 1 && err == nil {
-									break
-								}
-							}
-							prevState = 1
-							time.Sleep(150 * time.Millisecond)
-						}
-					}
-				} else {
-					time.Sleep(1 * time.Second)
-				}
-			}
-		}()
-	}
-
-	if UpButtonUsed {
-		UpButton = gpio.NewInput(UpButtonPin)
-		go func() {
-			for {
-				if IsConnected {
-					currentState, err := UpButton.Read()
-					time.Sleep(150 * time.Millisecond)
-					if currentState != UpButtonState && err == nil {
-						UpButtonState = currentState
-						if UpButtonState == 1 {
-							log.Println("debug: UP Button is released")
-						} else {
-							log.Println("debug: UP Button is pressed")
-							playIOMedia("iochannelup")
-							b.ChannelUp()
-							time.Sleep(150 * time.Millisecond)
-						}
-					}
-				} else {
-					time.Sleep(1 * time.Second)
-				}
-			}
-		}()
-	}
-
-	if DownButtonUsed {
-		DownButton = gpio.NewInput(DownButtonPin)
-		go func() {
-			for {
-				if IsConnected {
-
-					currentState, err := DownButton.Read()
-					time.Sleep(150 * time.Millisecond)
-
-					if currentState != DownButtonState && err == nil {
-						DownButtonState = currentState
+	initGPIOExpander()
+	loadGPIOInputs()
 
-						if DownButtonState == 1 {
-							log.Println("debug: Ch Down Button is released")
-						} else {
-							log.Println("debug: Ch Down Button is pressed")
-							playIOMedia("iochanneldown")
-							b.ChannelDown()
-							time.Sleep(150 * time.Millisecond)
-						}
-					}
-				} else {
-					time.Sleep(1 * time.Second)
-				}
-			}
-		}()
+	if Config.Global.Hardware.IO.GPIOBackend.Name == "chardev" {
+		b.initGPIOCdev()
+		return
 	}
 
-	if PanicUsed {
-		PanicButton = gpio.NewInput(PanicButtonPin)
-		go func() {
-			for {
-				if IsConnected {
-					currentState, err := PanicButton.Read()
-					time.Sleep(150 * time.Millisecond)
-					if currentState != PanicButtonState && err == nil {
-						PanicButtonState = currentState
-
-						if PanicButtonState == 1 {
-							log.Println("debug: Panic Button is released")
-						} else {
-							log.Println("debug: Panic Button is pressed")
-							playIOMedia("iopanic")
-							b.cmdPanicSimulation()
-							time.Sleep(150 * time.Millisecond)
-						}
-					}
-				} else {
-					time.Sleep(1 * time.Second)
-				}
-			}
-		}()
+	if Config.Global.Hardware.TargetBoard == "rpi" {
+		for _, input := range GPIOInputs {
+			PinPullUp := rpio.Pin(input.PinNo)
+			PinPullUp.PullUp()
+		}
+		if RotaryUsed {
+			rpio.Pin(RotaryAPin).PullUp()
+			rpio.Pin(RotaryBPin).PullUp()
+		}
+		rpio.Close()
 	}
 
-	if CommentUsed {
-		CommentButton = gpio.NewInput(CommentButtonPin)
+	for index := range GPIOInputs {
+		input := &GPIOInputs[index]
+		input.Pin = gpio.NewInput(input.PinNo)
+		log.Printf("info: GPIO Input %v PinNo %v Bound To Command %v\n", input.Name, input.PinNo, input.Command.Command)
+		go b.inputGestures(input)
 		go func() {
-			for {
-				if IsConnected {
-					currentState, err := CommentButton.Read()
-					time.Sleep(150 * time.Millisecond)
-					if currentState != CommentButtonState && err == nil {
-						CommentButtonState = currentState
-						if CommentButtonState == 1 {
-							playIOMedia("iocommenton")
-							log.Println("debug: Comment Button State 1 setting comment to State 1 Message ", Config.Global.Hardware.Comment.CommentMessageOff)
-							b.SetComment(Config.Global.Hardware.Comment.CommentMessageOff)
-						} else {
-							playIOMedia("iocommentoff")
-							log.Println("debug: Comment Button State 2 setting comment to State 2 Message ", Config.Global.Hardware.Comment.CommentMessageOn)
-							b.SetComment(Config.Global.Hardware.Comment.CommentMessageOn)
-							time.Sleep(150 * time.Millisecond)
-						}
-					}
-				} else {
-					time.Sleep(1 * time.Second)
-				}
+			// gestures and debounce need finer timing than the plain 150ms poll
+			pollInterval := 150 * time.Millisecond
+			if input.Gestures.enabled() || input.Debounce > 0 {
+				pollInterval = gesturePollMsecs * time.Millisecond
 			}
-		}()
-
-	}
-
-	if StreamToggleUsed {
-		StreamButton = gpio.NewInput(StreamButtonPin)
-		go func() {
 			for {
 				if IsConnected {
-					currentState, err := StreamButton.Read()
-					time.Sleep(150 * time.Millisecond)
-					if currentState != StreamButtonState && err == nil {
-						StreamButtonState = currentState
-						if StreamButtonState == 1 {
-							log.Println("debug: Stream Button is released")
-						} else {
-							playIOMedia("iostreamtoggle")
-							log.Println("debug: Stream Button is pressed")
-							b.cmdPlayback()
-							time.Sleep(150 * time.Millisecond)
-						}
+					currentState, err := input.Pin.Read()
+					time.Sleep(pollInterval)
+					if err == nil && input.sample(currentState) {
+						input.event(currentState == 0)
 					}
 				} else {
 					time.Sleep(1 * time.Second)
@@ -606,220 +229,199 @@ func (b *Talkkonnect) initGPIO() {
 			}
 		}()
 	}
+}
 
-	if RotaryButtonUsed {
-		RotaryButton = gpio.NewInput(RotaryButtonPin)
-		go func() {
-			for {
-				if IsConnected {
-					currentState, err := RotaryButton.Read()
-					time.Sleep(150 * time.Millisecond)
-
-					if currentState != RotaryButtonState && err == nil {
-						RotaryButtonState = currentState
+// targetBoardHasGPIO is true for the raspberry pi and other single board computers (sbc) that have GPIO pins
+func targetBoardHasGPIO() bool {
+	return Config.Global.Hardware.TargetBoard == "rpi" || Config.Global.Hardware.TargetBoard == "sbc" || gpioSimulated()
+}
 
-						if RotaryButtonState == 1 {
-							log.Println("debug: Rotary Button is released")
-						} else {
-							log.Println("debug: Rotary Button is pressed")
-							playIOMedia("iorotarybutton")
-							b.nextEnabledRotaryEncoderFunction()
+func initGPIOExpander() {
+	// Handle GPIO Expander Pins As Outputs if Enabled
+	if Config.Global.Hardware.IO.GPIOExpander.Enabled {
+		for _, gpioExpander := range Config.Global.Hardware.IO.GPIOExpander.Chip {
+			if Config.Global.Hardware.IO.GPIOExpander.Chip[gpioExpander.ID].Enabled {
+				log.Printf("debug: Setting up MCP23017 GPIO Expander on IC2 Bus %v Device No %v\n", gpioExpander.I2Cbus, gpioExpander.MCP23017Device)
+				var err error
+				D[gpioExpander.MCP23017Device], err = mcp23017.Open(gpioExpander.I2Cbus, gpioExpander.MCP23017Device)
+				if err != nil {
+					// log.Println("error: Unable To Setup Expander GPIO Chip On I2C Bus " + strconv.Itoa(int(gpioExpander.I2Cbus)) + " Device " + strconv.Itoa(int(gpioExpander.MCP23017Device)) + " With " + err.Error())
+					return
+				}
+				for y := 0; y < 16; y++ {
+					if Config.Global.Hardware.IO.Pins.Pin[y].Enabled && Config.Global.Hardware.IO.Pins.Pin[y].Direction == "output" && Config.Global.Hardware.IO.Pins.Pin[y].Type == "mcp23017" {
+						log.Printf("debug: Pin %v Enabled as Output\n", y)
+						err := D[gpioExpander.MCP23017Device].PinMode(uint8(y), mcp23017.OUTPUT)
+						if err != nil {
+							log.Printf("error: Cannot Set Pin %v as Output With Error %v\n", y, err)
+						}
+					}
+					if Config.Global.Hardware.IO.Pins.Pin[y].Enabled && Config.Global.Hardware.IO.Pins.Pin[y].Direction == "input" && Config.Global.Hardware.IO.Pins.Pin[y].Type == "mcp23017" {
+						log.Printf("debug: Pin %v Enabled as Input\n", y)
+						err := D[gpioExpander.MCP23017Device].PinMode(uint8(y), mcp23017.INPUT)
+						if err != nil {
+							log.Printf("error: Cannot Set Pin %v as Input With Error %v\n", y, err)
 						}
 					}
-				} else {
-					time.Sleep(1 * time.Second)
 				}
 			}
-		}()
+		}
 	}
+}
 
-	if VolUpButtonUsed {
-		VolUpButton = gpio.NewInput(VolUpButtonPin)
-		go func() {
-			for {
-				if IsConnected {
-					currentState, err := VolUpButton.Read()
-					time.Sleep(150 * time.Millisecond)
+func loadGPIOInputs() {
+	for _, io := range Config.Global.Hardware.IO.Pins.Pin {
+		if io.Enabled && io.Direction == "input" && io.Type == "gpio" && io.PinNo > 0 {
+			log.Printf("debug: GPIO Setup Input Device %v Name %v PinNo %v", io.Device, io.Name, io.PinNo)
 
-					if currentState != VolUpButtonState && err == nil {
-						VolUpButtonState = currentState
+			if io.Name == "rotarya" {
+				RotaryUsed = true
+				RotaryAPin = io.PinNo
+				continue
+			}
+			if io.Name == "rotaryb" {
+				RotaryUsed = true
+				RotaryBPin = io.PinNo
+				continue
+			}
 
-						if VolUpButtonState == 1 {
-							log.Println("debug: Vol UP Button is released")
-						} else {
-							log.Println("debug: Vol UP Button is pressed")
-							playIOMedia("iovolup")
-							b.cmdVolumeUp()
-						}
-					}
-				} else {
-					time.Sleep(1 * time.Second)
-				}
+			command, found := findInputCommand(io.Name, io.Action, io.ParamName, io.ParamValue)
+			if !found {
+				log.Printf("error: GPIO Input Name %v PinNo %v Has No Command Bound To It\n", io.Name, io.PinNo)
+				continue
 			}
-		}()
+			GPIOInputs = append(GPIOInputs, gpioInputStruct{Name: io.Name, PinNo: io.PinNo, Command: command, Gestures: loadInputGestures(io.Name, command, io.Gesture), Debounce: time.Duration(io.DebounceMsecs) * time.Millisecond, Events: make(chan bool, 1)})
+		}
 	}
+}
 
-	if VolDownButtonUsed {
-		VolDownButton = gpio.NewInput(VolDownButtonPin)
-		go func() {
-			for {
-				if IsConnected {
-					currentState, err := VolDownButton.Read()
-					time.Sleep(150 * time.Millisecond)
-					if currentState != VolDownButtonState && err == nil {
-						VolDownButtonState = currentState
-						if VolDownButtonState == 1 {
-							log.Println("debug: Vol Down Button is released")
-						} else {
-							log.Println("debug: Vol Down Button is pressed")
-							playIOMedia("iovoldown")
-							b.cmdVolumeDown()
-						}
-					}
-				} else {
-					time.Sleep(1 * time.Second)
-				}
-			}
-		}()
+// gpioOutput drives a gpio output pin through the configured gpio backend
+func gpioOutput(pinNo uint, value bool) {
+	if Config.Global.Hardware.IO.GPIOBackend.Name == "chardev" {
+		gpioCdevOutput(pinNo, value)
+		return
 	}
+	gpio.NewOutput(pinNo, value)
+}
 
-	if TrackingUsed {
-		TrackingButton = gpio.NewInput(TrackingButtonPin)
-		go func() {
-			for {
-				if IsConnected {
-					currentState, err := TrackingButton.Read()
-					time.Sleep(150 * time.Millisecond)
-					if currentState != TrackingButtonState && err == nil {
-						TrackingButtonState = currentState
-						if TrackingButtonState == 1 {
-							playIOMedia("iotrackingon")
-							log.Println("debug: Tracking Button State 1 setting GPS Tracking on  ")
-							// place holder to start tracking timer
-						} else {
-							playIOMedia("iotrackingoff")
-							log.Println("debug: Tracking Button State 1 setting GPS Tracking off ")
-							// place holder to start tracking timer
-							time.Sleep(150 * time.Millisecond)
-						}
-					}
-				} else {
-					time.Sleep(1 * time.Second)
-				}
-			}
-		}()
+func findInputCommand(name string, action string, paramName string, paramValue string) (inputCommandStruct, bool) {
+	if len(action) > 0 {
+		action = strings.ToLower(action)
+		if inputCommandKnown(action) {
+			return inputCommandStruct{action, paramName, paramValue}, true
+		}
+		return inputCommandStruct{}, false
 	}
+	command, found := defaultInputCommands[name]
+	return command, found
+}
 
-	if MQTT0ButtonUsed {
-		MQTT0Button = gpio.NewInput(MQTT0ButtonPin)
-		go func() {
-			for {
-				if IsConnected {
-					currentState, err := MQTT0Button.Read()
-					time.Sleep(150 * time.Millisecond)
-					if currentState != MQTT0ButtonState && err == nil {
-						MQTT0ButtonState = currentState
-						if MQTT0ButtonState == 1 {
-							log.Println("debug: MQTT0 Button is released")
-						} else {
-							log.Println("debug: MQTT0 Button is pressed")
-							playIOMedia("iomqtt0")
-							MQTTButtonCommand := findMQTTButton("0")
-							if MQTTButtonCommand.Enabled {
-								MQTTPublish(MQTTButtonCommand.Payload)
-							}
-							time.Sleep(150 * time.Millisecond)
-						}
-					}
-				} else {
-					time.Sleep(1 * time.Second)
-				}
-			}
-		}()
+func (b *Talkkonnect) inputCommandAction(name string, command inputCommandStruct, pressed bool) {
+	if pressed {
+		log.Printf("debug: Input %v is pressed\n", name)
+	} else {
+		log.Printf("debug: Input %v is released\n", name)
 	}
 
-	if MQTT1ButtonUsed {
-		MQTT1Button = gpio.NewInput(MQTT1ButtonPin)
-		go func() {
-			for {
-				if IsConnected {
-					currentState, err := MQTT1Button.Read()
-					time.Sleep(150 * time.Millisecond)
-					if currentState != MQTT1ButtonState && err == nil {
-						MQTT1ButtonState = currentState
-						if MQTT1ButtonState == 1 {
-							log.Println("debug: MQTT1 Button is released")
-						} else {
-							log.Println("debug: MQTT1 Button is pressed")
-							playIOMedia("iomqtt1")
-							MQTTButtonCommand := findMQTTButton("1")
-							if MQTTButtonCommand.Enabled {
-								MQTTPublish(MQTTButtonCommand.Payload)
-							}
-							time.Sleep(150 * time.Millisecond)
-						}
-					}
-				} else {
-					time.Sleep(1 * time.Second)
+	// commands that act on both press and release
+	switch command.Command {
+	case "txptt":
+		if b.Stream == nil {
+			return
+		}
+		if !pressed {
+			if isTx {
+				isTx = false
+				b.TransmitStop(true)
+				playIOMedia("iotxpttstop")
+				if Config.Global.Software.Settings.TxCounter {
+					txcounter++
+					log.Println("debug: Tx Button Count ", txcounter)
 				}
 			}
-		}()
+			return
+		}
+		if !isTx {
+			isTx = true
+			playIOMedia("iotxpttstart")
+		} else {
+			time.Sleep(150 * time.Millisecond)
+		}
+		txlockout := &TXLockOut
+		if Config.Global.Software.Settings.TXLockOut && *txlockout {
+			log.Println("warn: TX Lockout Stopping Transmission")
+			eventSound := findEventSound("txlockout")
+			if eventSound.Enabled {
+				if v, err := strconv.Atoi(eventSound.Volume); err == nil {
+					localMediaPlayer(eventSound.FileName, v, eventSound.Blocking, 0, 1)
+					log.Printf("debug: Playing txlockout Sound")
+				}
+			}
+		} else {
+			b.TransmitStart()
+		}
+		return
+	case "comment":
+		if pressed {
+			playIOMedia("iocommentoff")
+			log.Println("debug: Comment Button State 2 setting comment to State 2 Message ", Config.Global.Hardware.Comment.CommentMessageOn)
+			b.SetComment(Config.Global.Hardware.Comment.CommentMessageOn)
+		} else {
+			playIOMedia("iocommenton")
+			log.Println("debug: Comment Button State 1 setting comment to State 1 Message ", Config.Global.Hardware.Comment.CommentMessageOff)
+			b.SetComment(Config.Global.Hardware.Comment.CommentMessageOff)
+		}
+		return
+	case "tracking":
+		if pressed {
+			playIOMedia("iotrackingoff")
+			log.Println("debug: Tracking Button State 1 setting GPS Tracking off ")
+			// place holder to start tracking timer
+		} else {
+			playIOMedia("iotrackingon")
+			log.Println("debug: Tracking Button State 1 setting GPS Tracking on  ")
+			// place holder to start tracking timer
+		}
+		return
+	case "radiosquelch":
+		radioSquelch(pressed)
+		return
 	}
 
-	if NextServerButtonUsed {
-		NextServerButton = gpio.NewInput(NextServerButtonPin)
-		go func() {
-			for {
-				if IsConnected {
-					currentState, err := NextServerButton.Read()
-					time.Sleep(150 * time.Millisecond)
-					if currentState != NextServerButtonState && err == nil {
-						NextServerButtonState = currentState
-						if NextServerButtonState == 1 {
-							log.Println("debug: NextServer Button is released")
-						} else {
-							log.Println("debug: NextServer Button is pressed")
-							playIOMedia("iocnextserver")
-							b.cmdConnNextServer()
-							time.Sleep(150 * time.Millisecond)
-						}
-					}
-				} else {
-					time.Sleep(1 * time.Second)
-				}
-			}
-		}()
+	if !pressed {
+		return
 	}
 
-	if RepeaterToneButtonUsed {
-		RepeaterToneButton = gpio.NewInput(RepeaterToneButtonPin)
-		go func() {
-			for {
-				if IsConnected {
-					currentState, err := RepeaterToneButton.Read()
-					time.Sleep(150 * time.Millisecond)
-					if currentState != RepeaterToneButtonState && err == nil {
-						RepeaterToneButtonState = currentState
+	b.runInputCommand("io", name, command, false)
+	time.Sleep(150 * time.Millisecond)
+}
 
-						if RepeaterToneButtonState == 1 {
-							log.Println("debug: Repeater Tone Button is released")
-						} else {
-							log.Println("debug: Repeater Tone Button is pressed")
-							playIOMedia("iorepeatertone")
-							b.cmdPlayRepeaterTone()
-							time.Sleep(150 * time.Millisecond)
-						}
-					}
-				} else {
-					time.Sleep(1 * time.Second)
-				}
-			}
-		}()
+func GPIOOutPin(name string, command string) {
+	if !targetBoardHasGPIO() {
+		return
 	}
+
+	if ledPatternOwned(name, command) {
+		return
+	}
+	gpioOutPin(name, command)
 }
 
-func GPIOOutPin(name string, command string) {
-	if Config.Global.Hardware.TargetBoard != "rpi" {
+func gpioOutPin(name string, command string) {
+	if gpioSimulated() {
+		switch command {
+		case "on":
+			simGPIOOutput(name, true)
+		case "off":
+			simGPIOOutput(name, false)
+		case "pulse":
+			simGPIOOutput(name, false)
+			time.Sleep(Config.Global.Hardware.IO.Pulse.Leading * time.Millisecond)
+			simGPIOOutput(name, true)
+			time.Sleep(Config.Global.Hardware.IO.Pulse.Pulse * time.Millisecond)
+			simGPIOOutput(name, false)
+			time.Sleep(Config.Global.Hardware.IO.Pulse.Trailing * time.Millisecond)
+		}
 		return
 	}
 
@@ -830,7 +432,7 @@ func GPIOOutPin(name string, command string) {
 				switch io.Type {
 				case "gpio":
 					log.Printf("debug: Turning On %v at pin %v Output GPIO\n", io.Name, io.PinNo)
-					gpio.NewOutput(io.PinNo, true)
+					gpioOutput(io.PinNo, true)
 				case "mcp23017":
 					log.Printf("debug: Turning On %v at pin %v Output mcp23017\n", io.Name, io.PinNo)
 					err := D[io.ID].DigitalWrite(uint8(io.PinNo), mcp23017.LOW)
@@ -847,7 +449,7 @@ func GPIOOutPin(name string, command string) {
 				switch io.Type {
 				case "gpio":
 					log.Printf("debug: Turning Off %v at pin %v Output GPIO\n", io.Name, io.PinNo)
-					gpio.NewOutput(io.PinNo, false)
+					gpioOutput(io.PinNo, false)
 				case "mcp23017":
 					log.Printf("debug: Turning Off %v at pin %v Output mcp23017\n", io.Name, io.PinNo)
 					err := D[io.ID].DigitalWrite(uint8(io.PinNo), mcp23017.HIGH)
@@ -864,11 +466,11 @@ func GPIOOutPin(name string, command string) {
 				switch io.Type {
 				case "gpio":
 					log.Printf("debug: Pulsing %v at pin %v Output GPIO\n", io.Name, io.PinNo)
-					gpio.NewOutput(io.PinNo, false)
+					gpioOutput(io.PinNo, false)
 					time.Sleep(Config.Global.Hardware.IO.Pulse.Leading * time.Millisecond)
-					gpio.NewOutput(io.PinNo, true)
+					gpioOutput(io.PinNo, true)
 					time.Sleep(Config.Global.Hardware.IO.Pulse.Pulse * time.Millisecond)
-					gpio.NewOutput(io.PinNo, false)
+					gpioOutput(io.PinNo, false)
 					time.Sleep(Config.Global.Hardware.IO.Pulse.Trailing * time.Millisecond)
 				case "mcp23017":
 					log.Printf("debug: Pulsing %v at pin %v Output mcp23017\n", io.Name, io.PinNo)
@@ -897,21 +499,25 @@ func GPIOOutPin(name string, command string) {
 }
 
 func GPIOOutAll(name string, command string) {
-	if Config.Global.Hardware.TargetBoard != "rpi" {
+	if !targetBoardHasGPIO() {
 		return
 	}
 
 	for _, io := range Config.Global.Hardware.IO.Pins.Pin {
 		if io.Enabled && io.Direction == "output" && io.Device == "led/relay" {
+			if gpioSimulated() {
+				simGPIOOutput(io.Name, command == "on")
+				continue
+			}
 			switch io.Type {
 			case "gpio":
 				if command == "on" {
 					log.Printf("debug: Turning On %v Output GPIO\n", io.Name)
-					gpio.NewOutput(io.PinNo, true)
+					gpioOutput(io.PinNo, true)
 				}
 				if command == "off" {
 					log.Printf("debug: Turning Off %v Output GPIO\n", io.Name)
-					gpio.NewOutput(io.PinNo, false)
+					gpioOutput(io.PinNo, false)
 				}
 			case "mcp23017":
 				if command == "on" {
@@ -940,57 +546,56 @@ func GPIOOutAll(name string, command string) {
 }
 
 func MyLedStripGPIOOffAll() {
-	if Config.Global.Hardware.LedStripEnabled {
+	if Config.Global.Hardware.LedStripEnabled && MyLedStrip != nil {
 		log.Println("debug: Turning Off All LEDStrip LEDs")
-		MyLedStrip.ledCtrl(SOnlineLED, OffCol)
-		MyLedStrip.ledCtrl(SVoiceActivityLED, OffCol)
-		MyLedStrip.ledCtrl(STransmitLED, OffCol)
+		MyLedStrip.allOff()
 	}
 }
 
-func MyLedStripOnlineLEDOn() {
-	if Config.Global.Hardware.LedStripEnabled {
-		log.Println("debug: Turning On LEDStrip Online LED")
-		MyLedStrip.ledCtrl(SOnlineLED, OnlineCol)
+func MyLedStripStateSet(name string, active bool) {
+	if Config.Global.Hardware.LedStripEnabled && MyLedStrip != nil {
+		log.Printf("debug: Setting LEDStrip State %v To %v\n", name, active)
+		MyLedStrip.stateSet(name, active)
 	}
 }
 
-func MyLedStripOnlineLEDOff() {
-	if Config.Global.Hardware.LedStripEnabled {
-		log.Println("debug: Turning Off LEDStrip Online LED")
-		MyLedStrip.ledCtrl(SOnlineLED, OffCol)
-
+// MyLedStripChannel shows the colour configured for the channel on the channel state pixels
+func MyLedStripChannel(channelName string) {
+	if !Config.Global.Hardware.LedStripEnabled || MyLedStrip == nil {
+		return
+	}
+	for _, channel := range Config.Global.Hardware.LedStrip.Channel {
+		if channel.Name == channelName {
+			MyLedStrip.stateColour("channel", channel.Colour)
+			MyLedStrip.stateSet("channel", true)
+			return
+		}
 	}
+	MyLedStrip.stateSet("channel", false)
+}
+
+func MyLedStripOnlineLEDOn() {
+	MyLedStripStateSet("online", true)
+}
+
+func MyLedStripOnlineLEDOff() {
+	MyLedStripStateSet("online", false)
 }
 
 func MyLedStripVoiceActivityLEDOn() {
-	if Config.Global.Hardware.LedStripEnabled {
-		log.Println("debug: Turning On LEDStrip VoiceActivity LED")
-		MyLedStrip.ledCtrl(SVoiceActivityLED, VoiceActivityCol)
-	}
+	MyLedStripStateSet("voiceactivity", true)
 }
 
 func MyLedStripVoiceActivityLEDOff() {
-	if Config.Global.Hardware.LedStripEnabled {
-		log.Println("debug: Turning Off LEDStrip VoiceActivity LED")
-		MyLedStrip.ledCtrl(SVoiceActivityLED, OffCol)
-
-	}
+	MyLedStripStateSet("voiceactivity", false)
 }
 
 func MyLedStripTransmitLEDOn() {
-	if Config.Global.Hardware.LedStripEnabled {
-		log.Println("debug: Turning On LEDStrip Transmit LED")
-		MyLedStrip.ledCtrl(STransmitLED, TransmitCol)
-
-	}
+	MyLedStripStateSet("transmit", true)
 }
-func MyLedStripTransmitLEDOff() {
-	if Config.Global.Hardware.LedStripEnabled {
-		log.Println("debug: Turning Off LEDStrip Transmit LED")
-		MyLedStrip.ledCtrl(STransmitLED, OffCol)
 
-	}
+func MyLedStripTransmitLEDOff() {
+	MyLedStripStateSet("transmit", false)
 }
 
 func Max7219(max7219Cascaded int, spiBus int, spiDevice int, brightness byte, toDisplay string) {
@@ -1008,6 +613,18 @@ func Max7219(max7219Cascaded int, spiBus int, spiDevice int, brightness byte, to
 
 func (b *Talkkonnect) rotaryAction(direction string) {
 	if Config.Global.Hardware.IO.RotaryEncoder.Enabled {
+		if RotaryFunction.Function == "menu" {
+			b.menuRotate(direction)
+			return
+		}
+		if RotaryFunction.Function == "messages" {
+			if direction == "cw" {
+				messagePage("older")
+			} else {
+				messagePage("newer")
+			}
+			return
+		}
 		if direction == "cw" {
 			log.Println("debug: Rotating Clockwise")
 			switch RotaryFunction.Function {
@@ -1070,6 +687,10 @@ func createEnabledRotaryEncoderFunctions() {
 }
 
 func (b *Talkkonnect) nextEnabledRotaryEncoderFunction() {
+	if RotaryFunction.Function == "menu" {
+		b.menuClose()
+	}
+
 	if len(RotaryFunctions) > RotaryFunction.Item+1 {
 		RotaryFunction.Item++
 		RotaryFunction.Function = RotaryFunctions[RotaryFunction.Item].Function
@@ -1086,6 +707,9 @@ func (b *Talkkonnect) nextEnabledRotaryEncoderFunction() {
 		if RotaryFunction.Function == "voicetarget" {
 			b.sevenSegment("voicetarget", "")
 		}
+		if RotaryFunction.Function == "menu" {
+			b.menuOpen()
+		}
 		return
 	}
 
@@ -1105,6 +729,9 @@ func (b *Talkkonnect) nextEnabledRotaryEncoderFunction() {
 		if RotaryFunction.Function == "voicetarget" {
 			b.sevenSegment("voicetarget", "")
 		}
+		if RotaryFunction.Function == "menu" {
+			b.menuOpen()
+		}
 		return
 	}
 }
@@ -0,0 +1,148 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * gpiocdev.go talkkonnects function to connect to SBC GPIO using the linux gpio character device /dev/gpiochipN
+ */
+
+package talkkonnect
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/warthog618/gpiod"
+)
+
+var (
+	gpioCdevOutputs      = map[uint]*gpiod.Line{}
+	gpioCdevOutputsMutex sync.Mutex
+)
+
+// initGPIOCdev requests the input lines with both edge events, so inputs react as soon as the kernel sees the
+// edge instead of waiting for the next poll. Debounce is done by the kernel where the driver supports it
+func (b *Talkkonnect) initGPIOCdev() {
+	chip := Config.Global.Hardware.IO.GPIOBackend.Chip
+
+	for index := range GPIOInputs {
+		input := &GPIOInputs[index]
+
+		debounce := time.Duration(Config.Global.Hardware.IO.GPIOBackend.DebounceMsecs) * time.Millisecond
+		if input.Debounce > 0 {
+			debounce = input.Debounce
+		}
+
+		// every edge goes to the gesture decoder, which skips the commands while offline, so a release is never lost
+		options := []gpiod.LineReqOption{gpiod.AsInput, gpiod.WithPullUp, gpiod.WithBothEdges, gpiod.WithEventHandler(func(evt gpiod.LineEvent) {
+			input.event(evt.Type == gpiod.LineEventFallingEdge)
+		})}
+		if debounce > 0 {
+			options = append(options, gpiod.WithDebounce(debounce))
+		}
+
+		line, err := gpiod.RequestLine(chip, int(input.PinNo), options...)
+		if err != nil {
+			log.Printf("error: Cannot Request %v Line %v for Input %v With Error %v\n", chip, input.PinNo, input.Name, err)
+			continue
+		}
+		if value, err := line.Value(); err == nil {
+			input.State = uint(value)
+		}
+
+		log.Printf("info: GPIO Input %v %v Line %v Bound To Command %v\n", input.Name, chip, input.PinNo, input.Command.Command)
+		go b.inputGestures(input)
+	}
+
+	if RotaryUsed {
+		b.initRotaryCdev(chip)
+	}
+}
+
+// initRotaryCdev decodes the rotary encoder on the falling edge of pin A, one step per detent like the sysfs decoder,
+// the level of pin B at that moment gives the direction
+func (b *Talkkonnect) initRotaryCdev(chip string) {
+	lineB, err := gpiod.RequestLine(chip, int(RotaryBPin), gpiod.AsInput, gpiod.WithPullUp)
+	if err != nil {
+		log.Printf("error: Cannot Request %v Line %v for Rotary B With Error %v\n", chip, RotaryBPin, err)
+		return
+	}
+
+	rotaryEvents := make(chan string, 10)
+	_, err = gpiod.RequestLine(chip, int(RotaryAPin), gpiod.AsInput, gpiod.WithPullUp, gpiod.WithFallingEdge, gpiod.WithEventHandler(func(evt gpiod.LineEvent) {
+		if !IsConnected {
+			return
+		}
+		stateB, err := lineB.Value()
+		if err != nil {
+			return
+		}
+		direction := "ccw"
+		if stateB == 1 {
+			direction = "cw"
+		}
+		select {
+		case rotaryEvents <- direction:
+		default:
+		}
+	}))
+	if err != nil {
+		log.Printf("error: Cannot Request %v Line %v for Rotary A With Error %v\n", chip, RotaryAPin, err)
+		lineB.Close()
+		return
+	}
+
+	go func() {
+		for direction := range rotaryEvents {
+			b.rotaryAction(direction)
+		}
+	}()
+}
+
+func gpioCdevOutput(pinNo uint, value bool) {
+	gpioCdevOutputsMutex.Lock()
+	defer gpioCdevOutputsMutex.Unlock()
+
+	level := 0
+	if value {
+		level = 1
+	}
+
+	line, found := gpioCdevOutputs[pinNo]
+	if !found {
+		var err error
+		line, err = gpiod.RequestLine(Config.Global.Hardware.IO.GPIOBackend.Chip, int(pinNo), gpiod.AsOutput(level))
+		if err != nil {
+			log.Printf("error: Cannot Request %v Line %v as Output With Error %v\n", Config.Global.Hardware.IO.GPIOBackend.Chip, pinNo, err)
+			return
+		}
+		gpioCdevOutputs[pinNo] = line
+		return
+	}
+
+	if err := line.SetValue(level); err != nil {
+		log.Printf("error: Cannot Set %v Line %v Output With Error %v\n", Config.Global.Hardware.IO.GPIOBackend.Chip, pinNo, err)
+	}
+}
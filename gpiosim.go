@@ -0,0 +1,340 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * gpiosim.go talkkonnects simulated gpio backend with a virtual io panel for development without hardware
+ */
+
+package talkkonnect
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type simGPIOStateStruct struct {
+	Inputs  map[string]bool `json:"inputs"`
+	Outputs map[string]bool `json:"outputs"`
+}
+
+type simGPIORequestStruct struct {
+	Input  string `json:"input"`
+	Action string `json:"action"`
+}
+
+var (
+	simGPIOClient   *Talkkonnect
+	simGPIOOutputs  = map[string]bool{}
+	simGPIOWatchers = map[chan simGPIOStateStruct]bool{}
+	simGPIOMutex    sync.Mutex
+	simGPIOUpgrader = websocket.Upgrader{CheckOrigin: simGPIOSameOrigin}
+)
+
+func gpioSimulated() bool {
+	return Config.Global.Hardware.IO.GPIOBackend.Name == "simulated"
+}
+
+func (b *Talkkonnect) initGPIOSim() {
+	simGPIOClient = b
+	for index := range GPIOInputs {
+		input := &GPIOInputs[index]
+		input.State = 1
+		log.Printf("info: Simulated GPIO Input %v Bound To Command %v\n", input.Name, input.Command.Command)
+		go b.inputGestures(input)
+	}
+	for _, io := range Config.Global.Hardware.IO.Pins.Pin {
+		if io.Enabled && io.Direction == "output" {
+			simGPIOOutputs[io.Name] = false
+		}
+	}
+}
+
+func simGPIOOutput(name string, value bool) {
+	simGPIOMutex.Lock()
+	defer simGPIOMutex.Unlock()
+
+	if current, found := simGPIOOutputs[name]; found && current == value {
+		return
+	}
+	log.Printf("debug: Simulated GPIO Output %v Set To %v\n", name, value)
+	simGPIOOutputs[name] = value
+	simGPIONotify()
+}
+
+// simGPIONotify sends the current state to the websocket watchers, the caller must hold simGPIOMutex
+func simGPIONotify() {
+	state := simGPIOSnapshot()
+	for watcher := range simGPIOWatchers {
+		select {
+		case watcher <- state:
+		default:
+		}
+	}
+}
+
+// simGPIOSnapshot copies the simulated state, the caller must hold simGPIOMutex
+func simGPIOSnapshot() simGPIOStateStruct {
+	state := simGPIOStateStruct{Inputs: map[string]bool{}, Outputs: map[string]bool{}}
+	for _, input := range GPIOInputs {
+		state.Inputs[input.Name] = input.State == 0
+	}
+	for name, value := range simGPIOOutputs {
+		state.Outputs[name] = value
+	}
+	return state
+}
+
+// SimulatedGPIOInput acts on a simulated input by name, action is one of press, release or click. The rotary encoder
+// is turned with the input name rotary and the action cw or ccw
+func SimulatedGPIOInput(name string, action string) error {
+	if !gpioSimulated() || simGPIOClient == nil {
+		return errors.New("simulated gpio backend not enabled")
+	}
+	if !IsConnected {
+		return errors.New("not connected to server")
+	}
+
+	if name == "rotary" {
+		if !(action == "cw" || action == "ccw") {
+			return fmt.Errorf("invalid rotary action %v", action)
+		}
+		simGPIOClient.rotaryAction(action)
+		return nil
+	}
+
+	simGPIOMutex.Lock()
+	var input *gpioInputStruct
+	for index := range GPIOInputs {
+		if GPIOInputs[index].Name == name {
+			input = &GPIOInputs[index]
+		}
+	}
+	simGPIOMutex.Unlock()
+	if input == nil {
+		return fmt.Errorf("input %v not found", name)
+	}
+
+	switch action {
+	case "press":
+		simGPIOSetInput(input, 0)
+	case "release":
+		simGPIOSetInput(input, 1)
+	case "click":
+		simGPIOSetInput(input, 0)
+		time.Sleep(100 * time.Millisecond)
+		simGPIOSetInput(input, 1)
+	default:
+		return fmt.Errorf("invalid input action %v", action)
+	}
+	return nil
+}
+
+func simGPIOSetInput(input *gpioInputStruct, state uint) {
+	simGPIOMutex.Lock()
+	changed := input.State != state
+	input.State = state
+	if changed {
+		simGPIONotify()
+	}
+	simGPIOMutex.Unlock()
+	if changed {
+		input.event(state == 0)
+	}
+}
+
+// SimulatedGPIOOutputs returns the state of the simulated outputs by pin name
+func SimulatedGPIOOutputs() map[string]bool {
+	simGPIOMutex.Lock()
+	defer simGPIOMutex.Unlock()
+	return simGPIOSnapshot().Outputs
+}
+
+// SimulatedGPIOInputs returns the pressed state of the simulated inputs by pin name
+func SimulatedGPIOInputs() map[string]bool {
+	simGPIOMutex.Lock()
+	defer simGPIOMutex.Unlock()
+	return simGPIOSnapshot().Inputs
+}
+
+// SimulatedGPIOWaitOutput waits up to timeout for a simulated output to be set to value, for automated tests that
+// press an input and expect an output to follow
+func SimulatedGPIOWaitOutput(name string, value bool, timeout time.Duration) error {
+	watcher := make(chan simGPIOStateStruct, 10)
+	simGPIOMutex.Lock()
+	current, found := simGPIOOutputs[name]
+	if !found || current == value {
+		simGPIOMutex.Unlock()
+		if !found {
+			return fmt.Errorf("output %v not found", name)
+		}
+		return nil
+	}
+	simGPIOWatchers[watcher] = true
+	simGPIOMutex.Unlock()
+
+	defer func() {
+		simGPIOMutex.Lock()
+		delete(simGPIOWatchers, watcher)
+		simGPIOMutex.Unlock()
+	}()
+
+	deadline := time.After(timeout)
+	for {
+		select {
+		case state := <-watcher:
+			if state.Outputs[name] == value {
+				return nil
+			}
+		case <-deadline:
+			return fmt.Errorf("output %v not %v after %v", name, value, timeout)
+		}
+	}
+}
+
+// simGPIOSameOrigin refuses inputs a browser sends for a page of another site, tools such as curl send no origin
+func simGPIOSameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 {
+		origin = r.Header.Get("Referer")
+	}
+	if len(origin) == 0 {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(parsed.Host, r.Host)
+}
+
+func httpGPIOSim(w http.ResponseWriter, r *http.Request) {
+	input := r.URL.Query().Get("input")
+	action := r.URL.Query().Get("action")
+
+	if len(input) > 0 {
+		if !simGPIOSameOrigin(r) {
+			log.Printf("warn: Simulated GPIO Input %v From Origin %v Refused\n", input, r.Header.Get("Origin"))
+			http.Error(w, "403 error: cross origin request refused", http.StatusForbidden)
+			return
+		}
+		if err := SimulatedGPIOInput(input, action); err != nil {
+			log.Println("error: Simulated GPIO ", err)
+			http.Error(w, "404 error: "+err.Error(), http.StatusNotFound)
+			return
+		}
+	}
+
+	simGPIOMutex.Lock()
+	state := simGPIOSnapshot()
+	simGPIOMutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
+
+func httpGPIOSimWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := simGPIOUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println("error: Simulated GPIO WebSocket Upgrade ", err)
+		return
+	}
+	defer conn.Close()
+
+	watcher := make(chan simGPIOStateStruct, 10)
+	simGPIOMutex.Lock()
+	simGPIOWatchers[watcher] = true
+	watcher <- simGPIOSnapshot()
+	simGPIOMutex.Unlock()
+
+	defer func() {
+		simGPIOMutex.Lock()
+		delete(simGPIOWatchers, watcher)
+		simGPIOMutex.Unlock()
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			var request simGPIORequestStruct
+			if err := conn.ReadJSON(&request); err != nil {
+				return
+			}
+			if err := SimulatedGPIOInput(request.Input, request.Action); err != nil {
+				log.Println("error: Simulated GPIO ", err)
+			}
+		}
+	}()
+
+	for {
+		select {
+		case state := <-watcher:
+			if err := conn.WriteJSON(state); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+func httpGPIOSimPanel(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+	fmt.Fprint(w, simGPIOPanelHTML)
+}
+
+const simGPIOPanelHTML = `<!DOCTYPE html>
+<html>
+<head><title>talkkonnect virtual io panel</title></head>
+<body>
+<h3>Inputs</h3><div id="inputs"></div>
+<h3>Rotary</h3><button onclick="send('rotary','ccw')">ccw</button><button onclick="send('rotary','cw')">cw</button>
+<h3>Outputs</h3><div id="outputs"></div>
+<script>
+var ws = new WebSocket((location.protocol == "https:" ? "wss://" : "ws://") + location.host + "/gpio/ws");
+function send(input, action) { ws.send(JSON.stringify({input: input, action: action})); }
+ws.onmessage = function(event) {
+  var state = JSON.parse(event.data);
+  var inputs = "";
+  for (var name in state.inputs) {
+    inputs += "<button onmousedown=\"send('" + name + "','press')\" onmouseup=\"send('" + name + "','release')\">" + name + (state.inputs[name] ? " (pressed)" : "") + "</button> ";
+  }
+  document.getElementById("inputs").innerHTML = inputs;
+  var outputs = "";
+  for (var name in state.outputs) {
+    outputs += "<span style=\"padding:4px;margin:2px;background:" + (state.outputs[name] ? "lime" : "gray") + "\">" + name + "</span> ";
+  }
+  document.getElementById("outputs").innerHTML = outputs;
+};
+</script>
+</body>
+</html>
+`
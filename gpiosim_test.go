@@ -0,0 +1,90 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * gpiosim_test.go tests of the simulated gpio backend
+ */
+
+package talkkonnect
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSimulatedGPIOWaitOutput(t *testing.T) {
+	Config.Global.Hardware.IO.GPIOBackend.Name = "simulated"
+	simGPIOMutex.Lock()
+	simGPIOOutputs["transmit"] = false
+	simGPIOMutex.Unlock()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		gpioOutPin("transmit", "on")
+	}()
+	if err := SimulatedGPIOWaitOutput("transmit", true, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if outputs := SimulatedGPIOOutputs(); !outputs["transmit"] {
+		t.Fatalf("transmit output %v, want on", outputs["transmit"])
+	}
+
+	if err := SimulatedGPIOWaitOutput("transmit", false, 50*time.Millisecond); err == nil {
+		t.Fatal("wait for transmit off returned without the output changing")
+	}
+	if err := SimulatedGPIOWaitOutput("nosuchoutput", true, 50*time.Millisecond); err == nil {
+		t.Fatal("wait on an unknown output returned no error")
+	}
+}
+
+func TestSimulatedGPIOInputNeedsBackend(t *testing.T) {
+	Config.Global.Hardware.IO.GPIOBackend.Name = "sysfs"
+	if err := SimulatedGPIOInput("txptt", "press"); err == nil {
+		t.Fatal("input accepted with the simulated backend disabled")
+	}
+}
+
+func TestSimGPIOSameOrigin(t *testing.T) {
+	for _, test := range []struct {
+		header string
+		value  string
+		want   bool
+	}{
+		{"", "", true},
+		{"Origin", "http://talkkonnect.local:8080", true},
+		{"Origin", "http://evil.example", false},
+		{"Referer", "http://talkkonnect.local:8080/gpio/panel", true},
+		{"Referer", "http://evil.example/page", false},
+	} {
+		request := httptest.NewRequest("GET", "http://talkkonnect.local:8080/gpio?input=panic&action=click", nil)
+		if len(test.header) > 0 {
+			request.Header.Set(test.header, test.value)
+		}
+		if got := simGPIOSameOrigin(request); got != test.want {
+			t.Errorf("%v %v allowed %v, want %v", test.header, test.value, got, test.want)
+		}
+	}
+}
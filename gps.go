@@ -31,8 +31,6 @@
 package talkkonnect
 
 import (
-	"bufio"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
@@ -42,10 +40,6 @@ import (
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/adrianmo/go-nmea"
-	"github.com/jacobsa/go-serial/serial"
-	hd44780 "github.com/talkkonnect/go-hd44780"
 )
 
 type GSVDataStruct struct {
@@ -75,18 +69,9 @@ type GNSSDataStruct struct {
 
 //global variables for gps
 var (
-	GNSSData          GNSSDataStruct
-	GNSSDataPublic         = make(chan GNSSDataStruct, GPSDataChannelReceivers+1)
 	TraccarDiagSounds bool = true
 )
 
-var (
-	RMCSentenceValid bool
-	GGASentenceValid bool
-	GSVSentenceValid bool
-	goodGPSRead      bool
-)
-
 var (
 	TCPErrorCount      int
 	HTTPErrorCount     int
@@ -94,194 +79,29 @@ var (
 	HTTPErrorThreshold int = 17280
 )
 
-func getGpsPosition(verbosity int) (bool, error) {
-	RMCSentenceValid = false
-	GGASentenceValid = false
-	GSVSentenceValid = false
-	goodGPSRead = false
-
-	if Config.Global.Hardware.GPS.Enabled {
-
-		if Config.Global.Hardware.GPS.Port == "" {
-			return false, errors.New("gnss port not specified")
-		}
-
-		if Config.Global.Hardware.GPS.Even && Config.Global.Hardware.GPS.Odd {
-			return false, errors.New("can't specify both even and odd parity")
-		}
-
-		parity := serial.PARITY_NONE
-
-		if Config.Global.Hardware.GPS.Even {
-			parity = serial.PARITY_EVEN
-		} else if Config.Global.Hardware.GPS.Odd {
-			parity = serial.PARITY_ODD
-		}
-
-		options := serial.OpenOptions{
-			PortName:               Config.Global.Hardware.GPS.Port,
-			BaudRate:               Config.Global.Hardware.GPS.Baud,
-			DataBits:               Config.Global.Hardware.GPS.DataBits,
-			StopBits:               Config.Global.Hardware.GPS.StopBits,
-			MinimumReadSize:        Config.Global.Hardware.GPS.MinRead,
-			InterCharacterTimeout:  Config.Global.Hardware.GPS.CharTimeOut,
-			ParityMode:             parity,
-			Rs485Enable:            Config.Global.Hardware.GPS.Rs485,
-			Rs485RtsHighDuringSend: Config.Global.Hardware.GPS.Rs485HighDuringSend,
-			Rs485RtsHighAfterSend:  Config.Global.Hardware.GPS.Rs485HighAfterSend,
-		}
-
-		f, err := serial.Open(options)
-
-		if err != nil {
-			Config.Global.Hardware.GPS.Enabled = false
-			return false, errors.New("cannot open serial port")
-		} else {
-			defer f.Close()
-		}
-
-		if Config.Global.Hardware.GPS.TxData != "" {
-			txData_, err := hex.DecodeString(Config.Global.Hardware.GPS.TxData)
-
-			if err != nil {
-				Config.Global.Hardware.GPS.Enabled = false
-				return false, errors.New("cannot decode hex data")
-			}
-
-			log.Println("debug: Sending To Serial ", hex.EncodeToString(txData_))
-
-			count, err := f.Write(txData_)
-
-			if err != nil {
-				return false, errors.New("error writing to serial port")
-			} else {
-				log.Printf("debug: Wrote %v Bytes To Serial\n", count)
-			}
-
-		}
-
-		if Config.Global.Hardware.GPS.Rx {
-			serialPort, err := serial.Open(options)
-			if err != nil {
-				log.Println("warn: Unable to Open Serial Port Error ", err)
-			}
-
-			defer serialPort.Close()
-
-			reader := bufio.NewReader(serialPort)
-			scanner := bufio.NewScanner(reader)
-
-			for scanner.Scan() {
-				s, err := nmea.Parse(scanner.Text())
-				if err == nil {
-
-					switch s.DataType() {
-
-					case nmea.TypeRMC:
-						{
-							m := s.(nmea.RMC)
-							if m.Latitude != 0 && m.Longitude != 0 && !RMCSentenceValid {
-								RMCSentenceValid = true
-								GNSSData.DateTime = time.Now().UTC()
-								GNSSData.Date = fmt.Sprintf("%v", m.Date)
-								GNSSData.Time = fmt.Sprintf("%v", m.Time)
-								GNSSData.Validity = fmt.Sprintf("%v", m.Validity)
-								GNSSData.Lattitude = m.Latitude
-								GNSSData.Longitude = m.Longitude
-								GNSSData.Speed = m.Speed
-								GNSSData.Course = m.Course
-								GNSSData.Variation = m.Variation
-								GNSSData.RMCRaw = m.Raw
-							}
-						}
-					case nmea.TypeGGA:
-						{
-							m := s.(nmea.GGA)
-							if m.Latitude != 0 && m.Longitude != 0 && !GGASentenceValid {
-								GGASentenceValid = true
-								GNSSData.FixQuality = m.FixQuality
-								GNSSData.SatsInUse = m.NumSatellites
-								GNSSData.HDOP = m.HDOP
-								GNSSData.Altitude = m.Altitude
-							}
-						}
-
-					case nmea.TypeGSV:
-						{
-							m := s.(nmea.GSV)
-							for i := range m.Info {
-								if m.Info[i].SNR > 0 && !GSVSentenceValid {
-									GNSSData.GSVData[i].PRNNumber = s.(nmea.GSV).Info[i].SVPRNNumber
-									GNSSData.GSVData[i].SNR = s.(nmea.GSV).Info[i].SNR
-									GNSSData.GSVData[i].Azimuth = s.(nmea.GSV).Info[i].Azimuth
-									if i >= 3 {
-										GSVSentenceValid = true
-										GNSSData.SatsInView = m.NumberSVsInView
-									}
-								}
-							}
-						}
-					}
-				}
-			}
-
-			if RMCSentenceValid && GGASentenceValid && GSVSentenceValid {
-				goodGPSRead = true
-				log.Printf("debug: GPS Good Read Broadcasting to %v GPSDataChannelReceivers\n", GPSDataChannelReceivers)
-				for a := 0; a < GPSDataChannelReceivers; a++ {
-					GNSSDataPublic <- GNSSData
-					time.Sleep(100 * time.Millisecond)
-				}
-			}
-
-		} else {
-			return false, errors.New("error parsing gnss module")
-		}
-		return goodGPSRead, nil
+// getGpsPosition waits up to waitSecs for a fresh fix from the gnss reader, it no longer opens the serial port itself
+func getGpsPosition(waitSecs int) (bool, error) {
+	if !Config.Global.Hardware.GPS.Enabled {
+		return false, errors.New("gnss not enabled")
 	}
-	return false, errors.New("gnss not enabled")
+	startGNSSReader()
+	return gnssWaitForFix(time.Duration(waitSecs) * time.Second), nil
 }
 
 func httpSendTraccar(tprotocol string) {
 
-	GPSDataChannelReceivers++
+	subscriber := gnssSubscribe("traccar "+tprotocol, traccarInterval())
 
 	for {
-		GNSSDataTraccar := <-GNSSDataPublic
+		GNSSDataTraccar := <-subscriber.C
 
-		var TraccarServerFullURL string
-
-		if tprotocol == "osmand" {
-			TraccarDateTime := GNSSDataTraccar.DateTime.Format("2006-01-02") + "%20" + GNSSDataTraccar.DateTime.Format("15:04:05")
-			TraccarServerFullURL = (fmt.Sprint(Config.Global.Hardware.Traccar.Protocol.Osmand.ServerURL) + ":" + fmt.Sprint(Config.Global.Hardware.Traccar.Protocol.Osmand.Port) + "/?" + "id=" + Config.Global.Hardware.Traccar.ClientId + "&" +
-				"timestamp=" + TraccarDateTime + "&" + "lat=" + fmt.Sprintf("%f", GNSSDataTraccar.Lattitude) +
-				"&" + "lon=" + fmt.Sprintf("%f", GNSSDataTraccar.Longitude) + "&" + "speed=" + fmt.Sprintf("%f", GNSSDataTraccar.Speed) + "&" + "course=" +
-				fmt.Sprintf("%f", GNSSDataTraccar.Course) + "&" + "variation=" + fmt.Sprintf("%f", GNSSDataTraccar.Variation) + "&" + "hdop=" + fmt.Sprintf("%f", GNSSData.HDOP) + "&" + "altitude=" + fmt.Sprintf("%f", GNSSData.Altitude))
-
-		}
-
-		if tprotocol == "opengts" {
-			TraccarServerFullURL = (fmt.Sprint(Config.Global.Hardware.Traccar.Protocol.Opengts.ServerURL) + ":" + fmt.Sprint(Config.Global.Hardware.Traccar.Protocol.Opengts.Port) + "/?id=" + Config.Global.Hardware.Traccar.ClientId + "&gprmc=" + GNSSDataTraccar.RMCRaw)
-		}
-
-		client := &http.Client{
-			Transport: &http.Transport{
-				Dial: (&net.Dialer{
-					Timeout:   1 * time.Second,
-					KeepAlive: 0,
-				}).Dial,
-				DisableKeepAlives:     true,
-				DisableCompression:    true,
-				MaxIdleConnsPerHost:   1,
-				ResponseHeaderTimeout: 1 * time.Second,
-			},
+		if traccarQueuePending() {
+			traccarQueueAdd(GNSSDataTraccar)
+			traccarQueueFlush(func(fix GNSSDataStruct) error { return traccarHTTPSend(tprotocol, fix) })
+			continue
 		}
 
-		response, _ := http.NewRequest("GET", TraccarServerFullURL, nil)
-		response.Header.Add("Connection", "close")
-		response.Header.Add("Accept-Encoding", "none")
-
-		if response, err := client.Do(response); err == nil {
+		if response, err := traccarHTTPRequest(tprotocol, GNSSDataTraccar); err == nil {
 			if response.StatusCode >= 200 && response.StatusCode <= 299 {
 				HTTPErrorCount = 0
 				log.Printf("debug: %v Protocol Traccar Server HTTP Response Code %v With Status %v\n", tprotocol, response.StatusCode, http.StatusText(response.StatusCode))
@@ -301,7 +121,7 @@ func httpSendTraccar(tprotocol string) {
 				tnow := time.Now().Format("15:04:05")
 				if Config.Global.Hardware.LCD.Enabled && Config.Global.Hardware.Traccar.DeviceScreenEnabled {
 					LcdText = [4]string{"nil", "TRACK OK " + tnow, "lat:" + fmt.Sprintf("%f", GNSSDataTraccar.Lattitude) + " c:" + fmt.Sprintf("%f", GNSSDataTraccar.Course), "lon:" + fmt.Sprintf("%f", GNSSDataTraccar.Longitude) + " s:" + fmt.Sprintf("%.2f", GNSSDataTraccar.Speed*1.852)}
-					go hd44780.LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
+					LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
 				}
 				if Config.Global.Hardware.OLED.Enabled {
 					oledDisplay(false, 4, 1, "TRACK OK "+GNSSDataTraccar.DateTime.Format("15:04:05"))
@@ -328,7 +148,7 @@ func httpSendTraccar(tprotocol string) {
 				tnow := time.Now().Format("15:04:05")
 				if Config.Global.Hardware.LCD.Enabled && Config.Global.Hardware.Traccar.DeviceScreenEnabled {
 					LcdText = [4]string{"nil", "TRACK ERR2 " + tnow, "lat:" + fmt.Sprintf("%f", GNSSDataTraccar.Lattitude) + " c:" + fmt.Sprintf("%f", GNSSDataTraccar.Course), "lon:" + fmt.Sprintf("%f", GNSSDataTraccar.Longitude) + " s:" + fmt.Sprintf("%.2f", GNSSDataTraccar.Speed*1.852)}
-					go hd44780.LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
+					LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
 				}
 
 				if Config.Global.Hardware.OLED.Enabled {
@@ -341,9 +161,14 @@ func httpSendTraccar(tprotocol string) {
 
 			}
 
+			if response.StatusCode >= 500 {
+				log.Printf("debug: %v Protocol Traccar Server HTTP Response Code %v With Status %v\n", tprotocol, response.StatusCode, http.StatusText(response.StatusCode))
+				traccarQueueAdd(GNSSDataTraccar)
+			}
+
 			response.Body.Close()
 
-			if TCPErrorCount >= TCPErrorThreshold {
+			if !traccarQueueEnabled() && TCPErrorCount >= TCPErrorThreshold {
 				Config.Global.Hardware.Traccar.Enabled = false
 				if TraccarDiagSounds {
 					eventSound := findEventSound("traccarTooManyErrors")
@@ -357,7 +182,7 @@ func httpSendTraccar(tprotocol string) {
 
 				return
 			}
-			if HTTPErrorCount >= HTTPErrorThreshold {
+			if !traccarQueueEnabled() && HTTPErrorCount >= HTTPErrorThreshold {
 				Config.Global.Hardware.Traccar.Enabled = false
 				if TraccarDiagSounds {
 					eventSound := findEventSound("traccarTooManyErrors")
@@ -376,6 +201,7 @@ func httpSendTraccar(tprotocol string) {
 			re := regexp.MustCompile(tcpErrorsTrap)
 			matched := re.MatchString(err.Error())
 			log.Println("error: Failed Communication with Traccar Server with error ", err)
+			traccarQueueAdd(GNSSDataTraccar)
 			if matched {
 				TCPErrorCount++
 				log.Println("error: TCP/IP Error Communicating with Traccar Server")
@@ -393,7 +219,7 @@ func httpSendTraccar(tprotocol string) {
 				tnow := time.Now().Format("15:04:05")
 				if Config.Global.Hardware.LCD.Enabled && Config.Global.Hardware.Traccar.DeviceScreenEnabled {
 					LcdText = [4]string{"nil", "TRACK ERR1 " + tnow, "lat:" + fmt.Sprintf("%f", GNSSDataTraccar.Lattitude) + " c:" + fmt.Sprintf("%f", GNSSDataTraccar.Course), "lon:" + fmt.Sprintf("%f", GNSSDataTraccar.Longitude) + " s:" + fmt.Sprintf("%.2f", GNSSDataTraccar.Speed*1.852)}
-					go hd44780.LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
+					LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
 				}
 				if Config.Global.Hardware.OLED.Enabled {
 					oledDisplay(false, 4, 1, "TRACK ERR1 "+GNSSDataTraccar.DateTime.Format("15:04:05"))
@@ -408,13 +234,77 @@ func httpSendTraccar(tprotocol string) {
 	}
 }
 
+// traccarHTTPRequest sends one position over osmand or opengts, the timestamp is the time of the fix so a queued
+// position keeps its own time
+func traccarHTTPRequest(tprotocol string, GNSSDataTraccar GNSSDataStruct) (*http.Response, error) {
+	var TraccarServerFullURL string
+
+	if tprotocol == "osmand" {
+		TraccarDateTime := GNSSDataTraccar.DateTime.Format("2006-01-02") + "%20" + GNSSDataTraccar.DateTime.Format("15:04:05")
+		TraccarServerFullURL = (fmt.Sprint(Config.Global.Hardware.Traccar.Protocol.Osmand.ServerURL) + ":" + fmt.Sprint(Config.Global.Hardware.Traccar.Protocol.Osmand.Port) + "/?" + "id=" + Config.Global.Hardware.Traccar.ClientId + "&" +
+			"timestamp=" + TraccarDateTime + "&" + "lat=" + fmt.Sprintf("%f", GNSSDataTraccar.Lattitude) +
+			"&" + "lon=" + fmt.Sprintf("%f", GNSSDataTraccar.Longitude) + "&" + "speed=" + fmt.Sprintf("%f", GNSSDataTraccar.Speed) + "&" + "course=" +
+			fmt.Sprintf("%f", GNSSDataTraccar.Course) + "&" + "variation=" + fmt.Sprintf("%f", GNSSDataTraccar.Variation) + "&" + "hdop=" + fmt.Sprintf("%f", GNSSDataTraccar.HDOP) + "&" + "altitude=" + fmt.Sprintf("%f", GNSSDataTraccar.Altitude))
+
+	}
+
+	if tprotocol == "opengts" {
+		TraccarServerFullURL = (fmt.Sprint(Config.Global.Hardware.Traccar.Protocol.Opengts.ServerURL) + ":" + fmt.Sprint(Config.Global.Hardware.Traccar.Protocol.Opengts.Port) + "/?id=" + Config.Global.Hardware.Traccar.ClientId + "&gprmc=" + GNSSDataTraccar.RMCRaw)
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Dial: (&net.Dialer{
+				Timeout:   1 * time.Second,
+				KeepAlive: 0,
+			}).Dial,
+			DisableKeepAlives:     true,
+			DisableCompression:    true,
+			MaxIdleConnsPerHost:   1,
+			ResponseHeaderTimeout: 1 * time.Second,
+		},
+	}
+
+	request, err := http.NewRequest("GET", TraccarServerFullURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Add("Connection", "close")
+	request.Header.Add("Accept-Encoding", "none")
+
+	return client.Do(request)
+}
+
+// traccarHTTPSend sends a queued position and tells a rejected position apart from a server that is still unreachable
+func traccarHTTPSend(tprotocol string, fix GNSSDataStruct) error {
+	response, err := traccarHTTPRequest(tprotocol, fix)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+
+	if response.StatusCode >= 200 && response.StatusCode <= 299 {
+		return nil
+	}
+	if response.StatusCode >= 400 && response.StatusCode <= 499 {
+		return errTraccarRejected
+	}
+	return fmt.Errorf("traccar server responded %v", response.Status)
+}
+
 func tcpSendT55Traccar() {
 
-	GPSDataChannelReceivers++
+	subscriber := gnssSubscribe("traccar t55", traccarInterval())
 
 	for {
 
-		GNSSDataTraccar := <-GNSSDataPublic
+		GNSSDataTraccar := <-subscriber.C
+
+		if traccarQueuePending() {
+			traccarQueueAdd(GNSSDataTraccar)
+			traccarT55Flush()
+			continue
+		}
 
 		PGID := "$PGID" + "," + Config.Global.Hardware.Traccar.ClientId + "*0F" + "\r" + "\n"
 		GPRMC := GNSSDataTraccar.RMCRaw + "\r" + "\n"
@@ -424,6 +314,7 @@ func tcpSendT55Traccar() {
 
 		if err != nil {
 			fmt.Println(err)
+			traccarQueueAdd(GNSSDataTraccar)
 
 			if TraccarDiagSounds {
 				eventSound := findEventSound("traccarTCPConnRefused")
@@ -438,7 +329,7 @@ func tcpSendT55Traccar() {
 			tnow := time.Now().Format("15:04:05")
 			if Config.Global.Hardware.LCD.Enabled && Config.Global.Hardware.Traccar.DeviceScreenEnabled {
 				LcdText = [4]string{"nil", "TRACK ERR3 " + tnow, "lat:" + fmt.Sprintf("%f", GNSSDataTraccar.Lattitude) + " c:" + fmt.Sprintf("%f", GNSSDataTraccar.Course), "lon:" + fmt.Sprintf("%f", GNSSDataTraccar.Longitude) + " s:" + fmt.Sprintf("%.2f", GNSSDataTraccar.Speed*1.852)}
-				go hd44780.LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
+				LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
 			}
 			if Config.Global.Hardware.OLED.Enabled {
 				oledDisplay(false, 4, 1, "Track ERR3 "+GNSSDataTraccar.DateTime.Format("15:04:05"))
@@ -479,7 +370,11 @@ func tcpSendT55Traccar() {
 
 		fmt.Fprint(CONN, PGID) // Send ID
 		time.Sleep(5 * time.Second)
-		fmt.Fprint(CONN, GPRMC) // send $GPRMC
+		if _, err := fmt.Fprint(CONN, GPRMC); err != nil { // send $GPRMC
+			log.Println("error: Failed Sending Position To Traccar Server ", err)
+			traccarQueueAdd(GNSSDataTraccar)
+			continue
+		}
 		log.Println("debug: Sending position message to Traccar over Protocol: " + strings.Title(strings.ToLower(Config.Global.Hardware.Traccar.Protocol.Name)))
 
 		if TraccarDiagSounds {
@@ -495,7 +390,7 @@ func tcpSendT55Traccar() {
 		tnow := time.Now().Format("15:04:05")
 		if Config.Global.Hardware.LCD.Enabled && Config.Global.Hardware.Traccar.DeviceScreenEnabled {
 			LcdText = [4]string{"nil", "TRACK OK* " + tnow, "lat:" + fmt.Sprintf("%f", GNSSDataTraccar.Lattitude) + " c:" + fmt.Sprintf("%f", GNSSDataTraccar.Course), "lon:" + fmt.Sprintf("%f", GNSSDataTraccar.Longitude) + " s:" + fmt.Sprintf("%.2f", GNSSDataTraccar.Speed*1.852)}
-			go hd44780.LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
+			LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
 		}
 		if Config.Global.Hardware.OLED.Enabled {
 			oledDisplay(false, 4, 1, "TRACK OK* "+GNSSDataTraccar.DateTime.Format("15:04:05"))
@@ -506,10 +401,38 @@ func tcpSendT55Traccar() {
 	}
 }
 
+// traccarT55Flush sends the waiting positions over one t55 connection, each $GPRMC carries the time of its own fix
+func traccarT55Flush() {
+	conn, err := net.DialTimeout("tcp", Config.Global.Hardware.Traccar.Protocol.T55.ServerIP+":"+fmt.Sprint(Config.Global.Hardware.Traccar.Protocol.T55.Port), 10*time.Second)
+	if err != nil {
+		log.Println("error: Traccar Queue Cannot Connect ", err)
+		return
+	}
+	defer conn.Close()
+
+	if _, err := fmt.Fprint(conn, "$PGID"+","+Config.Global.Hardware.Traccar.ClientId+"*0F"+"\r"+"\n"); err != nil {
+		log.Println("error: Traccar Queue Cannot Send ID ", err)
+		return
+	}
+	time.Sleep(5 * time.Second)
+
+	traccarQueueFlush(func(fix GNSSDataStruct) error {
+		_, err := fmt.Fprint(conn, fix.RMCRaw+"\r"+"\n")
+		return err
+	})
+}
+
+func traccarInterval() time.Duration {
+	if Config.Global.Hardware.Traccar.IntervalSecs > 0 {
+		return time.Duration(Config.Global.Hardware.Traccar.IntervalSecs) * time.Second
+	}
+	return 30 * time.Second
+}
+
 func consoleScreenLogging() {
-	GPSDataChannelReceivers++
+	subscriber := gnssSubscribe("console", 10*time.Second)
 	for {
-		GNSSDataTraccar := <-GNSSDataPublic
+		GNSSDataTraccar := <-subscriber.C
 		log.Printf("debug: RMC Validity (%v), GGA GPS Quality Indicator (%v) %v/%v\n", GNSSDataTraccar.Validity, GNSSDataTraccar.FixQuality, GNSSDataTraccar.SatsInUse, GNSSDataTraccar.SatsInView)
 		log.Printf("debug: RMC Date Time              %v %v\n", GNSSDataTraccar.Date, GNSSDataTraccar.Time)
 		log.Printf("debug: OS  DateTime(UTC)          %v\n", GNSSDataTraccar.DateTime)
@@ -517,16 +440,16 @@ func consoleScreenLogging() {
 		log.Printf("debug: RMC Speed, Course          %v,%v\n", GNSSDataTraccar.Speed, GNSSDataTraccar.Course)
 		log.Printf("debug: RMC Variation, GGA HDOP    %v,%v\n", GNSSDataTraccar.Variation, GNSSDataTraccar.HDOP)
 		log.Printf("debug: GGA Altitude               %v\n", GNSSDataTraccar.Altitude)
-		for i := range GNSSData.GSVData {
+		for i := range GNSSDataTraccar.GSVData {
 			log.Printf("debug: GSV SVPRNNumber,SNR, Azimuth Sat(%v) %v,%v,%v\n", i, GNSSDataTraccar.GSVData[i].PRNNumber, GNSSDataTraccar.GSVData[i].SNR, GNSSDataTraccar.GSVData[i].Azimuth)
 		}
 	}
 }
 
 func gpsDisplayShow() {
-	GPSDataChannelReceivers++
+	subscriber := gnssSubscribe("display", 5*time.Second)
 	for {
-		GNSSDataTraccar := <-GNSSDataPublic
+		GNSSDataTraccar := <-subscriber.C
 		log.Printf("debug: Device Screen Latitude : %f Longitude : %f\n", GNSSDataTraccar.Lattitude, GNSSDataTraccar.Longitude)
 
 		if Config.Global.Hardware.GPS.Enabled && Config.Global.Hardware.GPS.GpsDiagSounds {
@@ -540,9 +463,10 @@ func gpsDisplayShow() {
 		}
 
 		tnow := time.Now().Format("15:04:05")
+		displayWidgetSet("gps", fmt.Sprintf("lat:%f lon:%f s:%.2f c:%.0f", GNSSDataTraccar.Lattitude, GNSSDataTraccar.Longitude, GNSSDataTraccar.Speed*1.852, GNSSDataTraccar.Course))
 		if Config.Global.Hardware.GPS.Enabled && Config.Global.Hardware.LCD.Enabled && Config.Global.Hardware.GPS.GpsDisplayShow && !Config.Global.Hardware.Traccar.DeviceScreenEnabled {
 			LcdText = [4]string{"nil", "GPS OK " + tnow, "lat:" + fmt.Sprintf("%f", GNSSDataTraccar.Lattitude) + " c:" + fmt.Sprintf("%f", GNSSDataTraccar.Course), "lon:" + fmt.Sprintf("%f", GNSSDataTraccar.Longitude) + " s:" + fmt.Sprintf("%.2f", GNSSDataTraccar.Speed*1.852)}
-			go hd44780.LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
+			LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
 		}
 		if Config.Global.Hardware.OLED.Enabled {
 			oledDisplay(false, 4, 1, "GPS OK "+GNSSDataTraccar.DateTime.Format("15:04:05"))
@@ -0,0 +1,200 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * gpsd.go talkkonnects function to take positions from a gpsd daemon shared with other software
+ */
+
+package talkkonnect
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultGpsdHost  = "localhost"
+	defaultGpsdPort  = 2947
+	knotsPerMetreSec = 1.943844
+)
+
+// gpsdMessageStruct has the fields of the gpsd TPV and SKY reports that make up a fix
+type gpsdMessageStruct struct {
+	Class      string  `json:"class"`
+	Mode       int     `json:"mode"`
+	Time       string  `json:"time"`
+	Lat        float64 `json:"lat"`
+	Lon        float64 `json:"lon"`
+	Alt        float64 `json:"alt"`
+	AltMSL     float64 `json:"altMSL"`
+	Speed      float64 `json:"speed"`
+	Track      float64 `json:"track"`
+	MagVar     float64 `json:"magvar"`
+	HDOP       float64 `json:"hdop"`
+	Satellites []struct {
+		PRN  int64   `json:"PRN"`
+		SS   float64 `json:"ss"`
+		Az   float64 `json:"az"`
+		Used bool    `json:"used"`
+	} `json:"satellites"`
+}
+
+// gpsdReader keeps a watch open on gpsd and connects again after the connection drops
+func gpsdReader() {
+	for {
+		if err := gpsdWatch(); err != nil {
+			log.Printf("error: GNSS gpsd %v Reconnecting In %v Seconds\n", err, gnssReopenSecs)
+		}
+		time.Sleep(gnssReopenSecs * time.Second)
+	}
+}
+
+func gpsdWatch() error {
+	host := Config.Global.Hardware.GPS.Gpsd.Host
+	if len(host) == 0 {
+		host = defaultGpsdHost
+	}
+	port := Config.Global.Hardware.GPS.Gpsd.Port
+	if port == 0 {
+		port = defaultGpsdPort
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+
+	conn, err := net.DialTimeout("tcp", address, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := fmt.Fprint(conn, `?WATCH={"enable":true,"json":true};`); err != nil {
+		return err
+	}
+	log.Printf("info: GNSS Watching gpsd At %v\n", address)
+
+	var fix GNSSDataStruct
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var message gpsdMessageStruct
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			continue
+		}
+
+		switch message.Class {
+		case "TPV":
+			fix.DateTime = time.Now().UTC()
+			if fixTime, err := time.Parse(time.RFC3339Nano, message.Time); err == nil {
+				fix.Date = fixTime.UTC().Format("02/01/06")
+				fix.Time = fixTime.UTC().Format("15:04:05.0000")
+			}
+			fix.Validity = "V"
+			fix.FixQuality = "0"
+			if message.Mode >= 2 {
+				fix.Validity = "A"
+				fix.FixQuality = "1"
+			}
+			fix.Lattitude = message.Lat
+			fix.Longitude = message.Lon
+			fix.Speed = message.Speed * knotsPerMetreSec
+			fix.Course = message.Track
+			fix.Variation = message.MagVar
+			fix.Altitude = message.Alt
+			if message.AltMSL != 0 {
+				fix.Altitude = message.AltMSL
+			}
+			fix.RMCRaw = gnssRMCSentence(fix)
+			gnssPublish(fix)
+		case "SKY":
+			if message.HDOP > 0 {
+				fix.HDOP = message.HDOP
+			}
+			if len(message.Satellites) == 0 {
+				continue
+			}
+			fix.SatsInView = int64(len(message.Satellites))
+			fix.SatsInUse = 0
+			fix.GSVData = [4]GSVDataStruct{}
+			for index, satellite := range message.Satellites {
+				if satellite.Used {
+					fix.SatsInUse++
+				}
+				if index < len(fix.GSVData) {
+					fix.GSVData[index] = GSVDataStruct{satellite.PRN, int64(satellite.SS), int64(satellite.Az)}
+				}
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// gnssRMCSentence builds the $GPRMC sentence traccar opengts and t55 need from a fix that did not come from nmea
+func gnssRMCSentence(fix GNSSDataStruct) string {
+	utc := fix.DateTime
+	return gnssSentence(fmt.Sprintf("GPRMC,%s,%s,%s,%.1f,%.1f,%s,,",
+		utc.Format("150405.00"), fix.Validity, gnssNMEACoordinates(fix), fix.Speed, fix.Course, utc.Format("020106")))
+}
+
+// gnssGGASentence builds the $GPGGA sentence with the fix quality, satellites, hdop and altitude of a fix
+func gnssGGASentence(fix GNSSDataStruct) string {
+	return gnssSentence(fmt.Sprintf("GPGGA,%s,%s,%s,%02d,%.1f,%.1f,M,,M,,",
+		fix.DateTime.Format("150405.00"), gnssNMEACoordinates(fix), fix.FixQuality, fix.SatsInUse, fix.HDOP, fix.Altitude))
+}
+
+// gnssNMEACoordinates formats the position as the ddmm.mmmm,N,dddmm.mmmm,E fields nmea sentences use
+func gnssNMEACoordinates(fix GNSSDataStruct) string {
+	latitude, latitudeHemisphere := math.Abs(fix.Lattitude), "N"
+	if fix.Lattitude < 0 {
+		latitudeHemisphere = "S"
+	}
+	longitude, longitudeHemisphere := math.Abs(fix.Longitude), "E"
+	if fix.Longitude < 0 {
+		longitudeHemisphere = "W"
+	}
+	latitudeDegrees := math.Floor(latitude)
+	longitudeDegrees := math.Floor(longitude)
+
+	return fmt.Sprintf("%02.0f%07.4f,%s,%03.0f%07.4f,%s",
+		latitudeDegrees, (latitude-latitudeDegrees)*60, latitudeHemisphere,
+		longitudeDegrees, (longitude-longitudeDegrees)*60, longitudeHemisphere)
+}
+
+// gnssSentence adds the $ and the checksum to the body of an nmea sentence
+func gnssSentence(body string) string {
+	var checksum byte
+	for index := 0; index < len(body); index++ {
+		checksum ^= body[index]
+	}
+	return fmt.Sprintf("$%s*%02X", body, checksum)
+}
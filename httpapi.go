@@ -64,8 +64,13 @@ func (b *Talkkonnect) httpAPI(w http.ResponseWriter, r *http.Request) {
 		"clearscreen":        b.cmdClearScreen,
 		"pingservers":        b.cmdPingServers,
 		"panicsimulation":    b.cmdPanicSimulation,
+		"panicack":           b.cmdPanicAck,
+		"paniccancel":        b.cmdPanicCancel,
+		"checkin":            b.cmdLoneWorkerCheckIn,
+		"whereiseveryone":    b.cmdWhereIsEveryone,
 		"repeattxloop":       b.cmdRepeatTxLoop,
 		"scanchannels":       b.cmdScanChannels,
+		"radioscan":          cmdRadioScan,
 		"thanks":             cmdThanks,
 		"showuptime":         b.cmdShowUptime,
 		"showversion":        b.cmdDisplayVersion,
@@ -91,6 +96,7 @@ func (b *Talkkonnect) httpAPI(w http.ResponseWriter, r *http.Request) {
 	var APIPreDelay int
 	var APIPostDelay int
 	var APILanguage string
+	var APIPIN string
 	var err error
 
 	APICommand := strings.ToLower(APICommands[0])
@@ -184,6 +190,10 @@ func (b *Talkkonnect) httpAPI(w http.ResponseWriter, r *http.Request) {
 			APILanguage = values[0]
 		}
 
+		if strings.ToLower(key) == "pin" {
+			APIPIN = values[0]
+		}
+
 	}
 
 	for _, apicommand := range Config.Global.Software.RemoteControl.HTTP.Command {
@@ -212,6 +222,13 @@ func (b *Talkkonnect) httpAPI(w http.ResponseWriter, r *http.Request) {
 						} else {
 							fmt.Fprintf(w, "200 OK: http command %v OK \n", APICommand)
 						}
+					case "paniccancel":
+						_, err := b.Call(funcs, apicommand.Action, APIPIN)
+						if err != nil {
+							log.Println("error: Wrong Parameters to Call Function")
+						} else {
+							fmt.Fprintf(w, "200 OK: http command %v OK \n", APICommand)
+						}
 					case "ttsannouncement":
 						_, err := b.Call(funcs, apicommand.Action, APITTSMessage, APITTSLocalPlay, APITTSPlayIntoStream, APIGPIOEnabled, APIGPIOName, time.Duration(APIPreDelay*int(time.Second)), time.Duration(APIPostDelay)*time.Second, APILanguage)
 						if err != nil {
@@ -0,0 +1,237 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * inputcommand.go talkkonnects command table shared by the gpio inputs, the tty keys and the usb keyboard
+ */
+
+package talkkonnect
+
+import (
+	"log"
+	"strconv"
+	"strings"
+)
+
+// inputCommand is an entry of the command table, media is the input event sound played before the command
+// without its io or usb prefix and repeat marks the commands a held usb key repeats
+type inputCommand struct {
+	media  string
+	repeat bool
+	run    func(b *Talkkonnect, source string, name string, command inputCommandStruct)
+}
+
+// Commands bound to a gpio input, a tty key or a usb key all run from this table, the gpio only commands that
+// act on both press and release are in inputEdgeCommands
+var inputCommandTable map[string]inputCommand
+
+var inputEdgeCommands = []string{"txptt", "comment", "tracking", "radiosquelch"}
+
+// Input event sounds that kept their older names
+var inputCommandMediaNames = map[string]string{
+	"ioserverup":      "iocnextserver",
+	"usbrepeatertone": "iorepeatertone",
+}
+
+func init() {
+	inputCommandTable = map[string]inputCommand{
+		"txtoggle": {run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			if b.IsTransmitting {
+				inputCommandMedia(source, "txtogglestop")
+				b.TransmitStop(true)
+				log.Println("debug: Toggle Stopped Transmitting")
+			} else {
+				inputCommandMedia(source, "txtogglestart")
+				b.TransmitStart()
+				log.Println("debug: Toggle Started Transmitting")
+			}
+		}},
+		"transmitstart": {media: "starttx", run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.cmdStartTransmitting()
+		}},
+		"transmitstop": {media: "stoptx", run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.cmdStopTransmitting()
+		}},
+		"channelup": {media: "channelup", repeat: true, run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.ChannelUp()
+		}},
+		"channeldown": {media: "channeldown", repeat: true, run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.ChannelDown()
+		}},
+		"serverup": {media: "serverup", run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.cmdConnNextServer()
+		}},
+		"serverdown": {media: "previousserver", run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.cmdConnPreviousServer()
+		}},
+		"mute": {media: "mute", run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.cmdMuteUnmute("mute")
+		}},
+		"unmute": {media: "unmute", run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.cmdMuteUnmute("unmute")
+		}},
+		"mute-toggle": {media: "mutetoggle", run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.cmdMuteUnmute("toggle")
+		}},
+		"stream-toggle": {media: "streamtoggle", run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.cmdPlayback()
+		}},
+		"volumeup": {media: "volup", repeat: true, run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.cmdVolumeUp()
+		}},
+		"volumedown": {media: "voldown", repeat: true, run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.cmdVolumeDown()
+		}},
+		"setcomment": {run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			if command.ParamName == "setcomment" {
+				log.Println("info: Set Commment ", command.ParamValue)
+				inputCommandMedia(source, "setcomment")
+				b.Client.Self.SetComment(command.ParamValue)
+			}
+		}},
+		"record": {media: "record", run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.cmdAudioTrafficRecord()
+			b.cmdAudioMicRecord()
+		}},
+		"voicetargetset": {run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			voicetarget, err := strconv.Atoi(command.ParamValue)
+			if err != nil {
+				log.Println("error: Target is Non-Numeric Value")
+				return
+			}
+			inputCommandMedia(source, "voicetarget")
+			b.cmdSendVoiceTargets(uint32(voicetarget))
+		}},
+		"mqttpubpayloadset": {run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			switch command.ParamName {
+			case "payloadvalue":
+				inputCommandMedia(source, "mqttpubpayloadset")
+				MQTTPublish(command.ParamValue)
+			case "buttonitem":
+				inputCommandMedia(source, "mqtt"+command.ParamValue)
+				MQTTButtonCommand := findMQTTButton(command.ParamValue)
+				if MQTTButtonCommand.Enabled {
+					MQTTPublish(MQTTButtonCommand.Payload)
+				}
+			}
+		}},
+		"repeatertoneplay": {media: "repeatertone", run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.cmdPlayRepeaterTone()
+		}},
+		"panic": {media: "panic", run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.cmdPanicSimulation()
+		}},
+		"paniccancel": {run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.panicEnd(panicCancelled, inputCommandSource(source)+" "+name)
+		}},
+		"checkin": {media: "checkin", run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.cmdLoneWorkerCheckIn()
+		}},
+		"whereiseveryone": {run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			go b.cmdWhereIsEveryone()
+		}},
+		"radioscan": {run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			cmdRadioScan()
+		}},
+		"radioscanlockout": {run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			radioScanLockoutToggle()
+		}},
+		"rotaryfunction": {media: "rotarybutton", run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			if RotaryFunction.Function == "menu" {
+				b.menuSelect()
+			} else {
+				b.nextEnabledRotaryEncoderFunction()
+			}
+		}},
+		"menuback": {run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			b.menuBack()
+		}},
+		"messageolder": {run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			messagePage("older")
+		}},
+		"messagenewer": {run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			messagePage("newer")
+		}},
+		"messagescroll": {run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			messageScrollDown()
+		}},
+		"displaypage": {run: func(b *Talkkonnect, source string, name string, command inputCommandStruct) {
+			displayPageNext()
+		}},
+	}
+}
+
+// runInputCommand runs a command from the table for an input of the source io, tty or usb, only repeatable
+// commands run when repeat is set
+func (b *Talkkonnect) runInputCommand(source string, name string, command inputCommandStruct, repeat bool) {
+	entry, found := inputCommandTable[strings.ToLower(command.Command)]
+	if !found {
+		if !repeat {
+			log.Println("error: Command Not Defined ", strings.ToLower(command.Command))
+		}
+		return
+	}
+	if repeat && !entry.repeat {
+		return
+	}
+	if len(entry.media) > 0 {
+		inputCommandMedia(source, entry.media)
+	}
+	entry.run(b, source, name, command)
+}
+
+// inputCommandMedia plays the input event sound of a gpio input or usb key, tty keys have no sounds
+func inputCommandMedia(source string, media string) {
+	if source == "tty" {
+		return
+	}
+	event := source + media
+	if renamed, found := inputCommandMediaNames[event]; found {
+		event = renamed
+	}
+	playIOMedia(event)
+}
+
+func inputCommandSource(source string) string {
+	switch source {
+	case "tty":
+		return "key"
+	case "usb":
+		return "usb key"
+	}
+	return "input"
+}
+
+func inputCommandKnown(action string) bool {
+	if _, found := inputCommandTable[action]; found {
+		return true
+	}
+	for _, command := range inputEdgeCommands {
+		if command == action {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,337 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * ledpattern.go talkkonnects function to play named blink, morse and breathing patterns on outputs by state and priority
+ */
+
+package talkkonnect
+
+import (
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const stripTargetPrefix = "strip:"
+
+type ledPatternStepStruct struct {
+	Level    float64
+	Duration time.Duration
+}
+
+type ledPatternStateStruct struct {
+	Name     string
+	Target   string
+	Colour   string
+	Priority int
+	Steps    []ledPatternStepStruct
+}
+
+type ledPatternTargetStruct struct {
+	Active  map[string]ledPatternStateStruct
+	Running string
+	Stop    chan struct{}
+	Base    string
+}
+
+var (
+	ledPatterns       = map[string][]ledPatternStepStruct{}
+	ledPatternStates  []ledPatternStateStruct
+	ledPatternTargets = map[string]*ledPatternTargetStruct{}
+	ledPatternMutex   sync.Mutex
+)
+
+var morseCode = map[rune]string{
+	'a': ".-", 'b': "-...", 'c': "-.-.", 'd': "-..", 'e': ".", 'f': "..-.", 'g': "--.", 'h': "....", 'i': "..", 'j': ".---",
+	'k': "-.-", 'l': ".-..", 'm': "--", 'n': "-.", 'o': "---", 'p': ".--.", 'q': "--.-", 'r': ".-.", 's': "...", 't': "-",
+	'u': "..-", 'v': "...-", 'w': ".--", 'x': "-..-", 'y': "-.--", 'z': "--..", '0': "-----", '1': ".----", '2': "..---",
+	'3': "...--", '4': "....-", '5': ".....", '6': "-....", '7': "--...", '8': "---..", '9': "----.",
+}
+
+func initLEDPatterns() {
+	if Config.Global.Hardware.LEDPatterns.Enabled {
+		for _, pattern := range Config.Global.Hardware.LEDPatterns.Pattern {
+			var steps []ledPatternStepStruct
+			switch strings.ToLower(pattern.Type) {
+			case "blink", "":
+				steps = blinkPatternSteps(pattern.Sequence)
+			case "morse":
+				steps = morsePatternSteps(pattern.Morse, pattern.UnitMsecs)
+			case "breathing":
+				steps = breathingPatternSteps(pattern.PeriodMsecs)
+			default:
+				log.Printf("error: LED Pattern %v Has Unknown Type %v\n", pattern.Name, pattern.Type)
+				continue
+			}
+			if len(steps) == 0 {
+				log.Printf("error: LED Pattern %v Has No Steps\n", pattern.Name)
+				continue
+			}
+			ledPatterns[pattern.Name] = steps
+		}
+
+		for _, state := range Config.Global.Hardware.LEDPatterns.State {
+			if !state.Enabled {
+				continue
+			}
+			steps, found := ledPatterns[state.Pattern]
+			if !found {
+				log.Printf("error: LED State %v Uses Undefined Pattern %v\n", state.Name, state.Pattern)
+				continue
+			}
+			if len(state.Output) > 0 {
+				ledPatternStates = append(ledPatternStates, ledPatternStateStruct{state.Name, state.Output, state.Colour, state.Priority, steps})
+			}
+			if len(state.StripLED) > 0 {
+				ledPatternStates = append(ledPatternStates, ledPatternStateStruct{state.Name, stripTargetPrefix + state.StripLED, state.Colour, state.Priority, steps})
+			}
+		}
+	}
+
+	// the heartbeat is played as a pattern on its own output, each period the led is off for ledonmsecs then on until ledoffmsecs
+	if Config.Global.Hardware.HeartBeat.Enabled {
+		period := Config.Global.Hardware.HeartBeat.Periodmsecs
+		on := Config.Global.Hardware.HeartBeat.LEDOnmsecs
+		off := Config.Global.Hardware.HeartBeat.LEDOffmsecs
+		var steps []ledPatternStepStruct
+		if on > 0 {
+			steps = append(steps, ledPatternStepStruct{0, time.Duration(on) * time.Millisecond})
+		}
+		if off > on {
+			steps = append(steps, ledPatternStepStruct{1, time.Duration(off-on) * time.Millisecond})
+		}
+		if period > off {
+			steps = append(steps, ledPatternStepStruct{0, time.Duration(period-off) * time.Millisecond})
+		}
+		if len(steps) > 0 {
+			ledPatternStates = append(ledPatternStates, ledPatternStateStruct{"heartbeat", "heartbeat", "", 0, steps})
+			ledPatternState("heartbeat", true)
+		}
+	}
+}
+
+func ledPatternSteps(name string) ([]ledPatternStepStruct, bool) {
+	ledPatternMutex.Lock()
+	defer ledPatternMutex.Unlock()
+	steps, found := ledPatterns[name]
+	return steps, found
+}
+
+// blinkPatternSteps turns a comma separated list of msecs into steps alternating on and off, starting with on
+func blinkPatternSteps(sequence string) []ledPatternStepStruct {
+	var steps []ledPatternStepStruct
+	for index, item := range strings.Split(sequence, ",") {
+		msecs, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil || msecs <= 0 {
+			log.Printf("error: LED Pattern Sequence %v Has Invalid Value %v\n", sequence, item)
+			return nil
+		}
+		steps = append(steps, ledPatternStepStruct{float64(1 - index%2), time.Duration(msecs) * time.Millisecond})
+	}
+	return steps
+}
+
+// morsePatternSteps uses the standard timing of dot 1 unit, dash 3 units, 1 unit between symbols, 3 between letters and 7 between words
+func morsePatternSteps(text string, unitMsecs int) []ledPatternStepStruct {
+	if unitMsecs <= 0 {
+		unitMsecs = 150
+	}
+	unit := time.Duration(unitMsecs) * time.Millisecond
+
+	var steps []ledPatternStepStruct
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		for _, letter := range word {
+			code, found := morseCode[letter]
+			if !found {
+				continue
+			}
+			for _, symbol := range code {
+				if symbol == '.' {
+					steps = append(steps, ledPatternStepStruct{1, unit})
+				} else {
+					steps = append(steps, ledPatternStepStruct{1, 3 * unit})
+				}
+				steps = append(steps, ledPatternStepStruct{0, unit})
+			}
+			steps[len(steps)-1].Duration = 3 * unit
+		}
+		if len(steps) > 0 {
+			steps[len(steps)-1].Duration = 7 * unit
+		}
+	}
+	return steps
+}
+
+// breathingPatternSteps fades the brightness up and down over the period, gpio outputs can only show it as a slow blink
+func breathingPatternSteps(periodMsecs int) []ledPatternStepStruct {
+	if periodMsecs <= 0 {
+		periodMsecs = 3000
+	}
+	const stepCount = 30
+	stepDuration := time.Duration(periodMsecs/stepCount) * time.Millisecond
+
+	var steps []ledPatternStepStruct
+	for i := 0; i < stepCount; i++ {
+		level := (1 - math.Cos(2*math.Pi*float64(i)/stepCount)) / 2
+		steps = append(steps, ledPatternStepStruct{level, stepDuration})
+	}
+	return steps
+}
+
+// ledPatternState turns a state such as panic, recording or reconnecting on or off, the highest priority active state on each
+// output plays its pattern and direct on/off control of that output is held back until no state is active
+func ledPatternState(state string, active bool) {
+	MyLedStripStateSet(state, active)
+
+	ledPatternMutex.Lock()
+	defer ledPatternMutex.Unlock()
+
+	for _, patternState := range ledPatternStates {
+		if patternState.Name != state {
+			continue
+		}
+		target, found := ledPatternTargets[patternState.Target]
+		if !found {
+			target = &ledPatternTargetStruct{Active: map[string]ledPatternStateStruct{}}
+			// nothing calls GPIOOutPin for strip leds, the strip keeps its own states so the base only clears the override
+			if strings.HasPrefix(patternState.Target, stripTargetPrefix) {
+				target.Base = "off"
+			}
+			ledPatternTargets[patternState.Target] = target
+		}
+		if active {
+			target.Active[state] = patternState
+		} else {
+			delete(target.Active, state)
+		}
+		ledPatternSchedule(patternState.Target, target)
+	}
+}
+
+// ledPatternSchedule starts the pattern of the highest priority active state, the caller must hold ledPatternMutex
+func ledPatternSchedule(name string, target *ledPatternTargetStruct) {
+	var next *ledPatternStateStruct
+	for _, state := range target.Active {
+		if next == nil || state.Priority > next.Priority {
+			state := state
+			next = &state
+		}
+	}
+
+	if next != nil && next.Name == target.Running {
+		return
+	}
+
+	if target.Stop != nil {
+		close(target.Stop)
+		target.Stop = nil
+		target.Running = ""
+	}
+
+	if next == nil {
+		if len(target.Base) > 0 {
+			ledPatternRestore(name, target.Base)
+		}
+		return
+	}
+
+	log.Printf("debug: LED Output %v Playing State %v Priority %v\n", name, next.Name, next.Priority)
+	target.Running = next.Name
+	target.Stop = make(chan struct{})
+	go ledPatternPlay(name, *next, target.Stop)
+}
+
+func ledPatternPlay(name string, state ledPatternStateStruct, stop chan struct{}) {
+	for {
+		for _, step := range state.Steps {
+			if !ledPatternStep(name, state.Colour, step.Level, stop) {
+				return
+			}
+			select {
+			case <-stop:
+				return
+			case <-time.After(step.Duration):
+			}
+		}
+	}
+}
+
+// ledPatternStep sets the level unless the pattern was stopped, stop is checked under ledPatternMutex so a step can
+// not land on the output after ledPatternRestore has put it back
+func ledPatternStep(name string, colour string, level float64, stop chan struct{}) bool {
+	ledPatternMutex.Lock()
+	defer ledPatternMutex.Unlock()
+	select {
+	case <-stop:
+		return false
+	default:
+	}
+	ledPatternLevel(name, colour, level)
+	return true
+}
+
+func ledPatternLevel(name string, colour string, level float64) {
+	if strings.HasPrefix(name, stripTargetPrefix) {
+		if MyLedStrip != nil {
+			MyLedStrip.ledLevel(strings.TrimPrefix(name, stripTargetPrefix), colour, level)
+		}
+		return
+	}
+	if level >= 0.5 {
+		gpioOutPin(name, "on")
+	} else {
+		gpioOutPin(name, "off")
+	}
+}
+
+func ledPatternRestore(name string, base string) {
+	if strings.HasPrefix(name, stripTargetPrefix) {
+		if MyLedStrip != nil {
+			MyLedStrip.ledRestore(strings.TrimPrefix(name, stripTargetPrefix))
+		}
+		return
+	}
+	gpioOutPin(name, base)
+}
+
+// ledPatternOwned reports whether a pattern is playing on the output and if so remembers the requested
+// on/off or colour so it can be restored when the pattern stops
+func ledPatternOwned(name string, base string) bool {
+	ledPatternMutex.Lock()
+	defer ledPatternMutex.Unlock()
+
+	target, found := ledPatternTargets[name]
+	if !found {
+		return false
+	}
+	if base != "pulse" {
+		target.Base = base
+	}
+	return len(target.Running) > 0
+}
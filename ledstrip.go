@@ -34,6 +34,9 @@ import (
 	"errors"
 	"log"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"periph.io/x/periph/conn/physic"
 	"periph.io/x/periph/conn/spi"
@@ -43,29 +46,58 @@ import (
 )
 
 const (
-	numLEDs           int    = 3
-	SOnlineLED        int    = 0
-	SVoiceActivityLED int    = 1
-	STransmitLED      int    = 2
-	OnlineCol         string = "00FF00" //Green
-	VoiceActivityCol  string = "0000FF" //Blue
-	TransmitCol       string = "FF0000" //Red
-	OffCol            string = "000000" //Off
+	defaultLedStripSPIPort    string = "SPI0.0"
+	defaultLedStripPixels     int    = 3
+	defaultLedStripBrightness uint8  = 16
+	OnlineCol                 string = "00FF00" //Green
+	VoiceActivityCol          string = "0000FF" //Blue
+	TransmitCol               string = "FF0000" //Red
+	OffCol                    string = "000000" //Off
 )
 
+type ledStripStateStruct struct {
+	Name     string
+	First    int
+	Last     int
+	Colour   string
+	Pattern  string
+	Priority int
+	Active   bool
+	Level    float64
+	Override string
+	Stop     chan struct{}
+}
+
 type LedStrip struct {
 	buf          []byte
+	pixels       int
+	states       []*ledStripStateStruct
+	mutex        sync.Mutex
 	display      *apa102.Dev
 	spiInterface spi.PortCloser
 }
 
 func NewLedStrip() (*LedStrip, error) {
-	var spiID string = "SPI0.0"   //SPI port to use
-	var intensity uint8 = 16      //light intensity [1-255]
-	var temperature uint16 = 5000 //light temperature in °Kelvin [3500-7500]
-	var hz physic.Frequency       //SPI port speed
+	var spiID string = Config.Global.Hardware.LedStrip.SPIPort           //SPI port to use
+	var intensity uint8 = Config.Global.Hardware.LedStrip.Brightness     //light intensity [1-255]
+	var temperature uint16 = Config.Global.Hardware.LedStrip.Temperature //light temperature in °Kelvin [3500-7500]
+	var hz physic.Frequency                                              //SPI port speed
 	var globalPWM bool = false
 
+	if len(spiID) == 0 {
+		spiID = defaultLedStripSPIPort
+	}
+	if intensity == 0 {
+		intensity = defaultLedStripBrightness
+	}
+	if temperature == 0 {
+		temperature = 5000
+	}
+	pixels := Config.Global.Hardware.LedStrip.Pixels
+	if pixels <= 0 {
+		pixels = defaultLedStripPixels
+	}
+
 	if _, err := host.Init(); err != nil {
 		return nil, err
 	}
@@ -85,7 +117,7 @@ func NewLedStrip() (*LedStrip, error) {
 		log.Printf("debug: Using pins CLK: %s  MOSI: %s  MISO: %s", p.CLK(), p.MOSI(), p.MISO())
 	}
 	o := apa102.DefaultOpts
-	o.NumPixels = numLEDs
+	o.NumPixels = pixels
 	o.Intensity = intensity
 	o.Temperature = temperature
 	o.DisableGlobalPWM = globalPWM
@@ -95,31 +127,228 @@ func NewLedStrip() (*LedStrip, error) {
 	}
 	log.Printf("debug: init display: %s\n", display)
 
-	buf := make([]byte, numLEDs*3)
+	buf := make([]byte, pixels*3)
 
 	return &LedStrip{
 		buf:          buf,
+		pixels:       pixels,
+		states:       loadLedStripStates(pixels),
 		display:      display,
 		spiInterface: s,
 	}, nil
 }
 
-func (ls *LedStrip) ledCtrl(num int, color string) error {
+// loadLedStripStates maps the states to pixel ranges, without any configured states the strip
+// behaves like the respeaker hat with online, voice activity and transmit on the first 3 pixels
+func loadLedStripStates(pixels int) []*ledStripStateStruct {
+	var states []*ledStripStateStruct
+
+	if len(Config.Global.Hardware.LedStrip.State) == 0 {
+		return []*ledStripStateStruct{
+			{Name: "online", First: 0, Last: 0, Colour: OnlineCol, Level: 1},
+			{Name: "voiceactivity", First: 1, Last: 1, Colour: VoiceActivityCol, Level: 1},
+			{Name: "transmit", First: 2, Last: 2, Colour: TransmitCol, Level: 1},
+		}
+	}
+
+	for _, state := range Config.Global.Hardware.LedStrip.State {
+		if !state.Enabled {
+			continue
+		}
+		first, last, err := parsePixelRange(state.Pixels)
+		if err != nil || first < 0 || last >= pixels || first > last {
+			log.Printf("error: Led Strip State %v Has Invalid Pixels %v For Strip Of %v Pixels\n", state.Name, state.Pixels, pixels)
+			continue
+		}
+		states = append(states, &ledStripStateStruct{Name: state.Name, First: first, Last: last, Colour: state.Colour, Pattern: state.Pattern, Priority: state.Priority, Level: 1})
+	}
+	return states
+}
+
+// parsePixelRange accepts a single pixel such as 3 or a range such as 0-11
+func parsePixelRange(pixels string) (int, int, error) {
+	parts := strings.SplitN(pixels, "-", 2)
+	first, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(parts) == 1 {
+		return first, first, nil
+	}
+	last, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, err
+	}
+	return first, last, nil
+}
+
+// stateSet turns a state on or off, states with a pattern animate while they are on
+func (ls *LedStrip) stateSet(name string, active bool) error {
 	if !Config.Global.Hardware.LedStripEnabled {
 		return errors.New("LedStrip Not Enabled in Config")
 	}
-	rgb, err := strconv.ParseUint(color, 16, 32)
-	if err != nil {
-		return err
+
+	// the patterns are looked up before taking ls.mutex, the led patterns hold ledPatternMutex while they call into the strip
+	patternSteps := map[string][]ledPatternStepStruct{}
+	for _, state := range ls.states {
+		if active && state.Name == name && len(state.Pattern) > 0 {
+			if steps, found := ledPatternSteps(state.Pattern); found {
+				patternSteps[state.Pattern] = steps
+			}
+		}
 	}
-	r := byte(rgb >> 16)
-	g := byte(rgb >> 8)
-	b := byte(rgb)
-	ls.buf[num*numLEDs+0] = r
-	ls.buf[num*numLEDs+1] = g
-	ls.buf[num*numLEDs+2] = b
 
-	_, err = ls.display.Write(ls.buf)
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
 
+	for _, state := range ls.states {
+		if state.Name != name || state.Active == active {
+			continue
+		}
+		state.Active = active
+		if state.Stop != nil {
+			close(state.Stop)
+			state.Stop = nil
+			state.Level = 1
+		}
+		if active && len(state.Pattern) > 0 {
+			if steps, found := patternSteps[state.Pattern]; found {
+				state.Stop = make(chan struct{})
+				go ls.animate(state, steps, state.Stop)
+			} else {
+				log.Printf("error: Led Strip State %v Uses Undefined Pattern %v\n", state.Name, state.Pattern)
+			}
+		}
+	}
+	return ls.render()
+}
+
+func (ls *LedStrip) allOff() error {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	for _, state := range ls.states {
+		if state.Stop != nil {
+			close(state.Stop)
+			state.Stop = nil
+		}
+		state.Active = false
+		state.Level = 1
+	}
+	return ls.render()
+}
+
+// stateColour changes the colour of a state, used for the per channel colour
+func (ls *LedStrip) stateColour(name string, colour string) error {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	for _, state := range ls.states {
+		if state.Name == name {
+			state.Colour = colour
+		}
+	}
+	return ls.render()
+}
+
+func (ls *LedStrip) animate(state *ledStripStateStruct, steps []ledPatternStepStruct, stop chan struct{}) {
+	for {
+		for _, step := range steps {
+			ls.mutex.Lock()
+			select {
+			case <-stop:
+				ls.mutex.Unlock()
+				return
+			default:
+			}
+			state.Level = step.Level
+			ls.render()
+			ls.mutex.Unlock()
+
+			select {
+			case <-stop:
+				return
+			case <-time.After(step.Duration):
+			}
+		}
+	}
+}
+
+// ledLevel is used by the led patterns to override a state with a colour scaled by a brightness level from 0 to 1
+func (ls *LedStrip) ledLevel(name string, colour string, level float64) error {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	for _, state := range ls.states {
+		if state.Name == name {
+			if len(colour) == 0 {
+				colour = state.Colour
+			}
+			state.Override = colour
+			state.Level = level
+		}
+	}
+	return ls.render()
+}
+
+// ledRestore removes the led pattern override from a state
+func (ls *LedStrip) ledRestore(name string) error {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	for _, state := range ls.states {
+		if state.Name == name {
+			state.Override = ""
+			state.Level = 1
+		}
+	}
+	return ls.render()
+}
+
+// render shows on each pixel the highest priority active state covering it, the caller must hold ls.mutex
+func (ls *LedStrip) render() error {
+	for pixel := 0; pixel < ls.pixels; pixel++ {
+		var top *ledStripStateStruct
+		for _, state := range ls.states {
+			if pixel < state.First || pixel > state.Last || !(state.Active || len(state.Override) > 0) {
+				continue
+			}
+			// a led pattern override always wins over the plain states
+			if top == nil || (len(state.Override) > 0) != (len(top.Override) > 0) {
+				if top == nil || len(state.Override) > 0 {
+					top = state
+				}
+				continue
+			}
+			if state.Priority > top.Priority {
+				top = state
+			}
+		}
+
+		colour, level := OffCol, 0.0
+		if top != nil {
+			colour, level = top.Colour, top.Level
+			if len(top.Override) > 0 {
+				colour = top.Override
+			}
+		}
+		if err := ls.ledCtrl(pixel, colour, level); err != nil {
+			return err
+		}
+	}
+
+	_, err := ls.display.Write(ls.buf)
 	return err
 }
+
+// ledCtrl sets a pixel in the buffer to the colour scaled by the level, render writes the buffer to the strip
+func (ls *LedStrip) ledCtrl(num int, color string, level float64) error {
+	rgb, err := strconv.ParseUint(color, 16, 32)
+	if err != nil {
+		return err
+	}
+	ls.buf[num*3+0] = byte(float64(byte(rgb>>16)) * level)
+	ls.buf[num*3+1] = byte(float64(byte(rgb>>8)) * level)
+	ls.buf[num*3+2] = byte(float64(byte(rgb)) * level)
+	return nil
+}
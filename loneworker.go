@@ -0,0 +1,148 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * loneworker.go talkkonnects function to ask lone workers to check in and raise a panic when they miss a check in
+ */
+
+package talkkonnect
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+var loneWorkerCheckIn = make(chan struct{}, 1)
+
+// loneWorker prompts for a check in every interval and raises the panic when no check in comes within the grace time
+func (b *Talkkonnect) loneWorker() {
+	if !Config.Global.Hardware.LoneWorker.Enabled {
+		return
+	}
+
+	interval := time.Duration(Config.Global.Hardware.LoneWorker.IntervalSecs) * time.Second
+	grace := time.Duration(Config.Global.Hardware.LoneWorker.GraceSecs) * time.Second
+	log.Printf("info: Lone Worker Check In Every %v With %v Grace\n", interval, grace)
+
+	for {
+		select {
+		case <-loneWorkerCheckIn:
+			// an early check in restarts the interval
+			continue
+		case <-time.After(interval):
+		}
+
+		b.loneWorkerPrompt()
+		ledPatternState("checkin", true)
+
+		select {
+		case <-loneWorkerCheckIn:
+			ledPatternState("checkin", false)
+			loneWorkerStatusClear()
+			log.Println("info: Lone Worker Checked In")
+		case <-time.After(grace):
+			ledPatternState("checkin", false)
+			loneWorkerStatusClear()
+			b.loneWorkerMissed()
+		}
+	}
+}
+
+// cmdLoneWorkerCheckIn is the check in button, checking in before the prompt restarts the interval
+func (b *Talkkonnect) cmdLoneWorkerCheckIn() {
+	if !Config.Global.Hardware.LoneWorker.Enabled {
+		return
+	}
+	select {
+	case loneWorkerCheckIn <- struct{}{}:
+	default:
+	}
+}
+
+func (b *Talkkonnect) loneWorkerPrompt() {
+	log.Println("info: Lone Worker Check In Requested")
+	if eventSound := findEventSound("loneworkercheckin"); eventSound.Enabled {
+		if v, err := strconv.Atoi(eventSound.Volume); err == nil {
+			localMediaPlayer(eventSound.FileName, v, eventSound.Blocking, 0, 1)
+		}
+	}
+	if len(Config.Global.Hardware.LoneWorker.Prompt) > 0 {
+		go b.Speak(Config.Global.Hardware.LoneWorker.Prompt, "local", Config.Global.Software.TTS.Volumelevel, 0, 1, Config.Global.Software.TTSMessages.TTSLanguage)
+	}
+	displayWidgetSet("status", "Check In Now")
+}
+
+// loneWorkerStatusClear puts the status widget back once the prompt is answered or missed
+func loneWorkerStatusClear() {
+	if IsConnected {
+		displayWidgetSet("status", "Online")
+	} else {
+		displayWidgetSet("status", "Offline")
+	}
+}
+
+// loneWorkerMissed reports the missed check in, raises the panic and opens the mic so the dispatcher can listen in
+func (b *Talkkonnect) loneWorkerMissed() {
+	log.Println("alert: Lone Worker Missed Check In")
+	report := fmt.Sprintf("Missed lone worker check in by %v (%v) at %v", b.Username, b.Ident, time.Now().Format("15:04:05"))
+	// a stale position is still worth sending to whoever goes looking, with its age
+	if fix, fresh := gnssLatest(); fix.Validity == "A" {
+		report += fmt.Sprintf(" last position http://www.google.com/maps/place/%.6f,%.6f", fix.Lattitude, fix.Longitude)
+		if !fresh {
+			report += fmt.Sprintf(" from %v ago", fix.age().Round(time.Second))
+		}
+	}
+
+	if Config.Global.Hardware.LoneWorker.ReportMQTT && Config.Global.Software.RemoteControl.MQTT.Enabled && MQTTClient != nil {
+		MQTTPublish(report)
+	}
+	if Config.Global.Hardware.LoneWorker.ReportEmail && Config.Global.Software.SMTP.Enabled {
+		go func() {
+			if err := sendEmail("Missed Check In "+b.Ident, report+"\n"); err != nil {
+				log.Println("error: Lone Worker Email Error ", err)
+			}
+		}()
+	}
+
+	var alerted <-chan struct{}
+	if Config.Global.Hardware.PanicFunction.Enabled {
+		alerted = b.panicRaise()
+	} else if IsConnected {
+		b.SendMessage(report, Config.Global.Hardware.PanicFunction.RecursiveSendMessage)
+	}
+
+	if openMic := Config.Global.Hardware.LoneWorker.OpenMicSecs; openMic > 0 && IsConnected {
+		if alerted != nil {
+			// transmitting stops a stream playing, so the panic file goes out first
+			<-alerted
+		}
+		log.Printf("info: Lone Worker Opening Mic For %v Seconds\n", openMic)
+		b.TransmitStart()
+		time.Sleep(time.Duration(openMic) * time.Second)
+		b.TransmitStop(true)
+	}
+}
@@ -0,0 +1,445 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * menu.go talkkonnects function for a rotary encoder driven menu on the lcd and oled screens with voice prompts
+ */
+
+package talkkonnect
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/talkkonnect/gumble/gumble"
+	"github.com/talkkonnect/volume-go"
+)
+
+// menuItemStruct is one line of the menu, selecting it opens the submenu built by Items, runs Action or, for
+// items with Adjust, hands the rotation to Adjust until the button is pressed again
+type menuItemStruct struct {
+	Label  string
+	Items  func() []menuItemStruct
+	Action func()
+	Adjust func(direction string) string
+}
+
+type menuLevelStruct struct {
+	Title  string
+	Items  []menuItemStruct
+	Cursor int
+}
+
+var (
+	menuStack   []*menuLevelStruct
+	menuAdjust  *menuItemStruct
+	menuMutex   sync.Mutex
+	menuPrompts = make(chan string, 1)
+	menuSpeaker sync.Once
+)
+
+func (b *Talkkonnect) menuOpen() {
+	menuMutex.Lock()
+	defer menuMutex.Unlock()
+
+	if len(menuStack) > 0 {
+		return
+	}
+	log.Println("info: Rotary Menu Opened")
+	displayMenuPages(true)
+	menuStack = []*menuLevelStruct{{Title: "Menu", Items: b.menuRootItems()}}
+	b.menuShow(true)
+}
+
+func (b *Talkkonnect) menuClose() {
+	menuMutex.Lock()
+	defer menuMutex.Unlock()
+
+	if len(menuStack) == 0 {
+		return
+	}
+	log.Println("info: Rotary Menu Closed")
+	menuStack = nil
+	menuAdjust = nil
+	displayWidgetSet("menu", "")
+	displayMenuPages(false)
+
+	if !displayManagerActive() && targetBoardHasGPIO() {
+		if LCDEnabled {
+			LcdText = [4]string{"", "", "", ""}
+			LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
+		}
+		if OLEDEnabled {
+			oledDisplay(true, 0, 0, "")
+		}
+	}
+}
+
+// menuRotate moves the cursor one item in the direction of the rotary encoder, wrapping at both ends
+func (b *Talkkonnect) menuRotate(direction string) {
+	b.menuOpen()
+
+	menuMutex.Lock()
+	defer menuMutex.Unlock()
+
+	if menuAdjust != nil {
+		b.menuPrompt(menuAdjust.Adjust(direction))
+		b.menuShow(false)
+		return
+	}
+
+	level := menuStack[len(menuStack)-1]
+	if direction == "cw" {
+		level.Cursor = (level.Cursor + 1) % len(level.Items)
+	} else {
+		level.Cursor = (level.Cursor + len(level.Items) - 1) % len(level.Items)
+	}
+	b.menuShow(true)
+}
+
+func (b *Talkkonnect) menuSelect() {
+	b.menuOpen()
+
+	menuMutex.Lock()
+
+	if menuAdjust != nil {
+		menuAdjust = nil
+		b.menuShow(true)
+		menuMutex.Unlock()
+		return
+	}
+
+	level := menuStack[len(menuStack)-1]
+	item := level.Items[level.Cursor]
+	log.Printf("debug: Rotary Menu %v Selected %v\n", level.Title, item.Label)
+
+	switch {
+	case item.Items != nil:
+		menuStack = append(menuStack, &menuLevelStruct{Title: item.Label, Items: append(item.Items(), menuItemStruct{Label: "Back"})})
+		b.menuShow(true)
+	case item.Adjust != nil:
+		menuAdjust = &item
+		b.menuPrompt(item.Adjust(""))
+		b.menuShow(false)
+	case item.Label == "Back":
+		menuStack = menuStack[:len(menuStack)-1]
+		b.menuShow(true)
+	case item.Action != nil:
+		// actions may close the menu or change what the parent lists, so they run without the lock
+		menuMutex.Unlock()
+		item.Action()
+		return
+	default:
+		b.menuPrompt(item.Label)
+	}
+	menuMutex.Unlock()
+}
+
+// menuBack goes up one level and closes the menu from the top level, useful on a long press of the rotary button
+func (b *Talkkonnect) menuBack() {
+	menuMutex.Lock()
+	if menuAdjust != nil {
+		menuAdjust = nil
+		b.menuShow(true)
+		menuMutex.Unlock()
+		return
+	}
+	if len(menuStack) > 1 {
+		menuStack = menuStack[:len(menuStack)-1]
+		b.menuShow(true)
+		menuMutex.Unlock()
+		return
+	}
+	menuMutex.Unlock()
+	b.menuClose()
+}
+
+// menuShow draws a window of the current level around the cursor, the caller must hold menuMutex
+func (b *Talkkonnect) menuShow(prompt bool) {
+	level := menuStack[len(menuStack)-1]
+
+	rows := Config.Global.Hardware.IO.RotaryEncoder.Menu.Rows
+	if rows <= 1 {
+		rows = 4
+	}
+	visible := rows - 1
+	first := 0
+	if level.Cursor >= visible {
+		first = level.Cursor - visible + 1
+	}
+
+	lines := []string{level.Title}
+	for index := first; index < len(level.Items) && index < first+visible; index++ {
+		if index == level.Cursor {
+			lines = append(lines, ">"+level.Items[index].Label)
+		} else {
+			lines = append(lines, " "+level.Items[index].Label)
+		}
+	}
+	if menuAdjust != nil {
+		lines[0] = menuAdjust.Adjust("")
+	}
+
+	if prompt {
+		b.menuPrompt(level.Items[level.Cursor].Label)
+	}
+
+	if displayManagerActive() {
+		displayWidgetSet("menu", strings.Join(lines, "\n"))
+		return
+	}
+
+	if targetBoardHasGPIO() {
+		if LCDEnabled {
+			LcdText = [4]string{"", "", "", ""}
+			for index := 0; index < len(lines) && index < len(LcdText); index++ {
+				LcdText[index] = lines[index]
+			}
+			LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
+		}
+		if OLEDEnabled {
+			for row := 0; row < rows && row < OLEDDisplayRows; row++ {
+				if row < len(lines) {
+					oledDisplay(false, row, 1, lines[row])
+				} else {
+					oledDisplay(false, row, 1, "")
+				}
+			}
+		}
+	}
+}
+
+// menuPrompt speaks the item, only the latest prompt is kept so turning the knob quickly does not queue up speech
+func (b *Talkkonnect) menuPrompt(text string) {
+	if !Config.Global.Hardware.IO.RotaryEncoder.Menu.VoicePrompts || len(text) == 0 {
+		return
+	}
+	menuSpeaker.Do(func() {
+		go func() {
+			for prompt := range menuPrompts {
+				b.Speak(prompt, "local", Config.Global.Software.TTS.Volumelevel, 0, 1, Config.Global.Software.TTSMessages.TTSLanguage)
+			}
+		}()
+	})
+	select {
+	case <-menuPrompts:
+	default:
+	}
+	select {
+	case menuPrompts <- text:
+	default:
+	}
+}
+
+func (b *Talkkonnect) menuRootItems() []menuItemStruct {
+	return []menuItemStruct{
+		{Label: "Channels", Items: func() []menuItemStruct {
+			if !IsConnected {
+				return []menuItemStruct{{Label: "Offline"}}
+			}
+			return b.menuChannelItems(b.Client.Channels[0])
+		}},
+		{Label: "Voice Targets", Items: b.menuVoiceTargetItems},
+		{Label: "Servers", Items: b.menuServerItems},
+		{Label: "Recording", Items: b.menuRecordingItems},
+		{Label: "Messages", Items: b.menuMessageItems},
+		{Label: "Status", Items: b.menuStatusItems},
+		{Label: "Volume", Adjust: b.menuVolumeAdjust},
+		{Label: "Exit", Action: func() {
+			b.menuClose()
+			if len(RotaryFunctions) > 1 {
+				b.nextEnabledRotaryEncoderFunction()
+			}
+		}},
+	}
+}
+
+// menuChannelItems lists the sub channels in server order, channels with their own sub channels open as a
+// submenu that starts with an item to join the channel itself
+func (b *Talkkonnect) menuChannelItems(parent *gumble.Channel) []menuItemStruct {
+	var children []*gumble.Channel
+	for _, child := range parent.Children {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		if children[i].Position != children[j].Position {
+			return children[i].Position < children[j].Position
+		}
+		return children[i].Name < children[j].Name
+	})
+
+	items := []menuItemStruct{{Label: "Join " + parent.Name, Action: b.menuJoinChannel(parent)}}
+	for _, child := range children {
+		child := child
+		if len(child.Children) > 0 {
+			items = append(items, menuItemStruct{Label: child.Name + " >", Items: func() []menuItemStruct { return b.menuChannelItems(child) }})
+		} else {
+			items = append(items, menuItemStruct{Label: child.Name, Action: b.menuJoinChannel(child)})
+		}
+	}
+	return items
+}
+
+func (b *Talkkonnect) menuJoinChannel(channel *gumble.Channel) func() {
+	return func() {
+		log.Println("info: Rotary Menu Joining Channel ", channel.Name)
+		b.Client.Self.Move(channel)
+		displayWidgetSet("channel", channel.Name)
+		b.menuClose()
+	}
+}
+
+func (b *Talkkonnect) menuVoiceTargetItems() []menuItemStruct {
+	var items []menuItemStruct
+	var index int
+	for _, account := range Config.Accounts.Account {
+		if !account.Default {
+			continue
+		}
+		if index == AccountIndex {
+			for _, target := range account.Voicetargets.ID {
+				value := target.Value
+				label := target.Name
+				if len(label) == 0 {
+					label = "Target " + strconv.Itoa(int(value))
+				}
+				items = append(items, menuItemStruct{Label: label, Action: func() {
+					log.Printf("info: Rotary Menu Voice Target %v\n", value)
+					b.cmdSendVoiceTargets(value)
+					b.menuClose()
+				}})
+			}
+		}
+		index++
+	}
+	return items
+}
+
+func (b *Talkkonnect) menuServerItems() []menuItemStruct {
+	var items []menuItemStruct
+	for index := 0; index < AccountCount; index++ {
+		index := index
+		label := Name[index]
+		if index == AccountIndex {
+			label = "*" + label
+		}
+		items = append(items, menuItemStruct{Label: label, Action: func() {
+			if index == AccountIndex {
+				return
+			}
+			log.Printf("info: Rotary Menu Server %v Requested\n", Name[index])
+			AccountIndex = index
+			modifyXMLTagServerHopping(ConfigXMLFile, AccountIndex)
+		}})
+	}
+	return items
+}
+
+func (b *Talkkonnect) menuRecordingItems() []menuItemStruct {
+	if recordingActive {
+		return []menuItemStruct{{Label: "Stop Recording", Action: func() {
+			AudioRecordStop()
+			b.menuClose()
+		}}}
+	}
+	return []menuItemStruct{{Label: "Start Recording", Action: func() {
+		switch Config.Global.Hardware.AudioRecordFunction.RecordMode {
+		case "traffic":
+			b.cmdAudioTrafficRecord()
+		case "ambient":
+			b.cmdAudioMicRecord()
+		case "combo":
+			b.cmdAudioMicTrafficRecord()
+		default:
+			log.Println("warn: Rotary Menu Recording Not Configured")
+			b.menuPrompt("Recording Not Configured")
+			return
+		}
+		b.menuClose()
+	}}}
+}
+
+// menuMessageItems lists the stored messages newest first, each opens to the word wrapped text
+func (b *Talkkonnect) menuMessageItems() []menuItemStruct {
+	messages := messageStoreList()
+	if len(messages) == 0 {
+		return []menuItemStruct{{Label: "No Messages"}}
+	}
+	messageRead()
+
+	var items []menuItemStruct
+	for _, message := range messages {
+		message := message
+		items = append(items, menuItemStruct{Label: message.Time.Format("15:04") + " " + message.Sender, Items: func() []menuItemStruct {
+			var lines []menuItemStruct
+			for _, line := range wordWrap(message.Message, 20) {
+				lines = append(lines, menuItemStruct{Label: line})
+			}
+			return lines
+		}})
+	}
+	return items
+}
+
+func (b *Talkkonnect) menuStatusItems() []menuItemStruct {
+	items := []menuItemStruct{{Label: "Server " + b.Name}}
+	if IsConnected {
+		items = append(items, menuItemStruct{Label: "Channel " + b.Client.Self.Channel.Name})
+	} else {
+		items = append(items, menuItemStruct{Label: "Offline"})
+	}
+	if ip := localIPv4(); len(ip) > 0 {
+		items = append(items, menuItemStruct{Label: "IP " + ip})
+	} else {
+		items = append(items, menuItemStruct{Label: "No Network"})
+	}
+	if Config.Global.Hardware.GPS.Enabled {
+		if fix, fresh := gnssLatest(); fresh {
+			items = append(items, menuItemStruct{Label: fmt.Sprintf("Lat %.5f", fix.Lattitude)}, menuItemStruct{Label: fmt.Sprintf("Lon %.5f", fix.Longitude)})
+		} else {
+			items = append(items, menuItemStruct{Label: "GPS No Fix"})
+		}
+	}
+	return items
+}
+
+// menuVolumeAdjust changes the volume with the rotation and returns the line to show, an empty direction only reads it
+func (b *Talkkonnect) menuVolumeAdjust(direction string) string {
+	switch direction {
+	case "cw":
+		b.cmdVolumeUp()
+	case "ccw":
+		b.cmdVolumeDown()
+	}
+	current, err := volume.GetVolume(Config.Global.Software.Settings.OutputVolControlDevice)
+	if err != nil {
+		return "Volume Unknown"
+	}
+	return "Volume " + strconv.Itoa(current)
+}
@@ -0,0 +1,175 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * messageforward.go talkkonnects function to forward received text messages to mqtt, webhooks and email
+ */
+
+package talkkonnect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// forwardMessageStruct is what a forwarder template sees, the json template function quotes a field for json bodies
+type forwardMessageStruct struct {
+	Sender   string `json:"sender"`
+	Channel  string `json:"channel"`
+	Private  bool   `json:"private"`
+	Message  string `json:"message"`
+	Time     string `json:"time"`
+	Ident    string `json:"ident"`
+	Username string `json:"username"`
+}
+
+var forwardTemplateFuncs = template.FuncMap{
+	"json": func(value interface{}) string {
+		encoded, _ := json.Marshal(value)
+		return string(encoded)
+	},
+}
+
+var forwardHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// messageForward sends the message to every enabled forwarder whose sender regex and channel filters match
+func (b *Talkkonnect) messageForward(sender string, channel string, private bool, message string) {
+	if !Config.Global.Software.MessageForward.Enabled {
+		return
+	}
+
+	forward := forwardMessageStruct{
+		Sender:   sender,
+		Channel:  channel,
+		Private:  private,
+		Message:  message,
+		Time:     time.Now().Format(time.RFC3339),
+		Ident:    b.Ident,
+		Username: b.Username,
+	}
+
+	for _, forwarder := range Config.Global.Software.MessageForward.Forwarder {
+		if !forwarder.Enabled {
+			continue
+		}
+		if len(forwarder.SenderRegex) > 0 {
+			if matched, err := regexp.MatchString(forwarder.SenderRegex, sender); err != nil || !matched {
+				continue
+			}
+		}
+		if len(forwarder.Channel) > 0 && (private || !strings.EqualFold(forwarder.Channel, channel)) {
+			continue
+		}
+		if forwarder.Scope == "private" && !private || forwarder.Scope == "channel" && private {
+			continue
+		}
+
+		body, err := forwardRender(forwarder.Name, forwarder.Template, forwarder.Type, forward)
+		if err != nil {
+			log.Printf("error: Message Forwarder %v Template Error %v\n", forwarder.Name, err)
+			continue
+		}
+
+		switch forwarder.Type {
+		case "mqtt":
+			if !Config.Global.Software.RemoteControl.MQTT.Enabled || MQTTClient == nil {
+				log.Printf("warn: Message Forwarder %v Needs MQTT Enabled\n", forwarder.Name)
+				continue
+			}
+			topic := forwarder.Topic
+			if len(topic) == 0 {
+				topic = Config.Global.Software.RemoteControl.MQTT.Settings.MQTTPubTopic
+			}
+			token := MQTTClient.Publish(topic, Config.Global.Software.RemoteControl.MQTT.Settings.MQTTQos, false, body)
+			go func(name string) {
+				<-token.Done()
+				if token.Error() != nil {
+					log.Printf("error: Message Forwarder %v MQTT Publish Error %v\n", name, token.Error())
+				}
+			}(forwarder.Name)
+		case "webhook":
+			go forwardWebhook(forwarder.Name, forwarder.URL, body)
+		case "email":
+			if !Config.Global.Software.SMTP.Enabled {
+				log.Printf("warn: Message Forwarder %v Needs SMTP Enabled\n", forwarder.Name)
+				continue
+			}
+			subject := forwarder.Subject
+			if len(subject) == 0 {
+				subject = "Message from " + sender
+			}
+			go func(name string) {
+				if err := sendEmail(subject, body); err != nil {
+					log.Printf("error: Message Forwarder %v Email Error %v\n", name, err)
+				}
+			}(forwarder.Name)
+		}
+		log.Printf("debug: Message From %v Forwarded By %v\n", sender, forwarder.Name)
+	}
+}
+
+// forwardRender fills the template, without one mqtt and webhooks get the message as json and email as plain text
+func forwardRender(name string, text string, forwardType string, forward forwardMessageStruct) (string, error) {
+	if len(strings.TrimSpace(text)) == 0 {
+		if forwardType == "email" {
+			where := "channel " + forward.Channel
+			if forward.Private {
+				where = "private message"
+			}
+			return fmt.Sprintf("%v\nFrom: %v (%v)\nTo: %v\n\n%v\n", forward.Time, forward.Sender, where, forward.Username, forward.Message), nil
+		}
+		encoded, err := json.Marshal(forward)
+		return string(encoded), err
+	}
+
+	parsed, err := template.New(name).Funcs(forwardTemplateFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var body bytes.Buffer
+	if err := parsed.Execute(&body, forward); err != nil {
+		return "", err
+	}
+	return body.String(), nil
+}
+
+func forwardWebhook(name string, url string, body string) {
+	response, err := forwardHTTPClient.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		log.Printf("error: Message Forwarder %v Webhook Error %v\n", name, err)
+		return
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		log.Printf("error: Message Forwarder %v Webhook Returned %v\n", name, response.Status)
+	}
+}
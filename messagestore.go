@@ -0,0 +1,194 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * messagestore.go talkkonnects function to keep the last text messages and page and scroll through them on the screens
+ */
+
+package talkkonnect
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const defaultMessageStoreSize = 20
+
+type textMessageStruct struct {
+	Sender  string
+	Message string
+	Time    time.Time
+}
+
+var (
+	messageStore  []textMessageStruct
+	messageIndex  int
+	messageScroll int
+	messageUnread int
+	messageMutex  sync.Mutex
+)
+
+// messageStoreAdd keeps the message, dropping the oldest once the store is full, and shows it as the current message
+func messageStoreAdd(sender string, message string) {
+	messageMutex.Lock()
+	defer messageMutex.Unlock()
+
+	size := Config.Global.Software.MessageStore.Size
+	if size <= 0 {
+		size = defaultMessageStoreSize
+	}
+
+	messageStore = append(messageStore, textMessageStruct{sender, message, time.Now()})
+	if len(messageStore) > size {
+		messageStore = messageStore[len(messageStore)-size:]
+	}
+	messageIndex = 0
+	messageScroll = 0
+	messageUnread++
+	messageUnreadIndicator()
+	messageShow()
+}
+
+// messageStoreList returns the stored messages newest first
+func messageStoreList() []textMessageStruct {
+	messageMutex.Lock()
+	defer messageMutex.Unlock()
+
+	var messages []textMessageStruct
+	for index := len(messageStore) - 1; index >= 0; index-- {
+		messages = append(messages, messageStore[index])
+	}
+	return messages
+}
+
+// messagePage moves through the history, older goes back in time and newer forward, reading clears the unread indicator
+func messagePage(direction string) {
+	messageMutex.Lock()
+	defer messageMutex.Unlock()
+
+	if len(messageStore) == 0 {
+		log.Println("info: No Text Messages Stored")
+		return
+	}
+
+	switch direction {
+	case "older":
+		if messageIndex < len(messageStore)-1 {
+			messageIndex++
+		}
+	case "newer":
+		if messageIndex > 0 {
+			messageIndex--
+		}
+	}
+	messageScroll = 0
+	messageUnread = 0
+	messageUnreadIndicator()
+	messageShow()
+}
+
+// messageScrollDown moves the current message up one line on the screen, going back to the top after the last line
+func messageScrollDown() {
+	messageMutex.Lock()
+	defer messageMutex.Unlock()
+
+	if len(messageStore) == 0 {
+		return
+	}
+	messageScroll++
+	messageUnread = 0
+	messageUnreadIndicator()
+	messageShow()
+}
+
+// messageRead clears the unread indicator without changing what is on the screens
+func messageRead() {
+	messageMutex.Lock()
+	defer messageMutex.Unlock()
+
+	messageUnread = 0
+	messageUnreadIndicator()
+}
+
+// messageUnreadIndicator turns the unread output and led state on while there are unread messages, the caller must hold messageMutex
+func messageUnreadIndicator() {
+	unread := messageUnread > 0
+	ledPatternState("unread", unread)
+	if len(Config.Global.Software.MessageStore.UnreadLED) > 0 {
+		if unread {
+			GPIOOutPin(Config.Global.Software.MessageStore.UnreadLED, "on")
+		} else {
+			GPIOOutPin(Config.Global.Software.MessageStore.UnreadLED, "off")
+		}
+	}
+}
+
+// messageShow puts the current message on the screens word wrapped, the caller must hold messageMutex
+func messageShow() {
+	message := messageStore[len(messageStore)-1-messageIndex]
+	header := fmt.Sprintf("%v/%v %v %v", messageIndex+1, len(messageStore), message.Sender, message.Time.Format("15:04"))
+	log.Printf("info: Message %v: %v\n", header, message.Message)
+
+	if displayManagerActive() {
+		displayWidgetScroll("message", messageScroll)
+		displayWidgetSet("message", header+"\n"+message.Message)
+		return
+	}
+
+	if !targetBoardHasGPIO() {
+		return
+	}
+
+	if LCDEnabled {
+		lines := messageLines(message.Message, 20, 3)
+		LcdText = [4]string{header, lines[0], lines[1], lines[2]}
+		LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
+	}
+	if OLEDEnabled {
+		rows := OLEDDisplayRows - 3
+		if rows < 1 {
+			rows = 1
+		}
+		oledDisplay(false, 2, 1, header)
+		for row, line := range messageLines(message.Message, int(OLEDDisplayColumns), rows) {
+			oledDisplay(false, 3+row, 1, line)
+		}
+	}
+}
+
+// messageLines wraps the message and returns rows lines starting at the scroll position, padded with empty lines
+func messageLines(message string, columns int, rows int) []string {
+	wrapped := wordWrap(message, columns)
+	if len(wrapped) > rows {
+		start := messageScroll % (len(wrapped) - rows + 1)
+		wrapped = wrapped[start:]
+	}
+
+	lines := make([]string, rows)
+	copy(lines, wrapped)
+	return lines
+}
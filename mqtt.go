@@ -134,14 +134,20 @@ func (b *Talkkonnect) onMessageReceived(client MQTT.Client, message MQTT.Message
 		"clearscreen":        b.cmdClearScreen,
 		"pingservers":        b.cmdPingServers,
 		"panicsimulation":    b.cmdPanicSimulation,
+		"panicack":           b.cmdPanicAck,
+		"paniccancel":        b.cmdPanicCancel,
+		"checkin":            b.cmdLoneWorkerCheckIn,
+		"whereiseveryone":    b.cmdWhereIsEveryone,
 		"repeattxloop":       b.cmdRepeatTxLoop,
 		"scanchannels":       b.cmdScanChannels,
+		"radioscan":          cmdRadioScan,
 		"thanks":             cmdThanks,
 		"showuptime":         b.cmdShowUptime,
 		"dumpxmlconfig":      b.cmdDumpXMLConfig,
 		"voicetargetset":     b.cmdSendVoiceTargets,
 		"attention":          attention,
-		"relay":              relay}
+		"relay":              relay,
+		"ledstate":           ledPatternState}
 
 	PayLoad = strings.ToLower(string(message.Payload()))
 	log.Printf("info: Received MQTT message on topic: %s Payload: %s\n", message.Topic(), PayLoad)
@@ -210,6 +216,18 @@ func (b *Talkkonnect) onMessageReceived(client MQTT.Client, message MQTT.Message
 					} else {
 						log.Println("error: Malformed MQTT Command")
 					}
+				case "ledstate":
+					if len(Command) == 3 {
+						_, Err = b.Call(funcs, mqttcommand.Action, Command[1], Command[2] == "on")
+					} else {
+						log.Println("error: Malformed MQTT Command")
+					}
+				case "paniccancel":
+					if len(Command) == 2 {
+						_, Err = b.Call(funcs, mqttcommand.Action, Command[1])
+					} else {
+						_, Err = b.Call(funcs, mqttcommand.Action, "")
+					}
 				case "voicetargetset":
 					if len(Command) == 2 {
 						id, err := strconv.Atoi(Command[1])
@@ -47,11 +47,15 @@ func (b *Talkkonnect) OnConnect(e *gumble.ConnectEvent) {
 	}
 
 	IsConnected = true
+	ledPatternState("reconnecting", false)
+	displayWidgetSet("status", "Online")
+	displayWidgetSet("server", b.Name)
 	GPIOOutPin("online", "on")
 	MyLedStripOnlineLEDOn()
 	b.BackLightTimer()
 	b.Client = e.Client
 	ConnectAttempts = 1
+	go b.chatCommandRequestGroups()
 
 	//serialize tokens send one by one from slice to server
 	if len(Tokens[AccountIndex]) > 0 {
@@ -72,7 +76,7 @@ func (b *Talkkonnect) OnConnect(e *gumble.ConnectEvent) {
 		}
 	}
 
-	if Config.Global.Hardware.TargetBoard == "rpi" {
+	if targetBoardHasGPIO() {
 
 		GPIOOutPin("online", "on")
 
@@ -106,9 +110,11 @@ func (b *Talkkonnect) OnDisconnect(e *gumble.DisconnectEvent) {
 	}
 
 	IsConnected = false
+	ledPatternState("reconnecting", true)
+	displayWidgetSet("status", "Offline")
 	GPIOOutPin("online", "off")
 	MyLedStripOnlineLEDOff()
-	if Config.Global.Hardware.TargetBoard == "rpi" {
+	if targetBoardHasGPIO() {
 		GPIOOutAll("led/relay", "off")
 		MyLedStripGPIOOffAll()
 	}
@@ -124,6 +130,10 @@ func (b *Talkkonnect) OnDisconnect(e *gumble.DisconnectEvent) {
 func (b *Talkkonnect) OnTextMessage(e *gumble.TextMessageEvent) {
 	b.BackLightTimer()
 
+	if b.chatCommand(e) {
+		return
+	}
+
 	var eventSound EventSoundStruct = findEventSound("message")
 	if eventSound.Enabled {
 		if v, err := strconv.Atoi(eventSound.Volume); err == nil {
@@ -131,23 +141,33 @@ func (b *Talkkonnect) OnTextMessage(e *gumble.TextMessageEvent) {
 
 		}
 	}
-	if len(cleanstring(e.Message)) > 105 {
-		log.Println("warn: Message Too Long to Be Displayed on Screen")
-		tmessage = strings.TrimSpace(cleanstring(e.Message)[:105])
-	} else {
-		tmessage = strings.TrimSpace(cleanstring(e.Message))
-	}
+	tmessage = strings.TrimSpace(cleanstring(e.Message))
 
 	var sender string
+	var senderName string
 
 	if e.Sender != nil {
 		sender = strings.TrimSpace(cleanstring(e.Sender.Name))
+		senderName = e.Sender.Name
 		log.Println("info: Sender Name is ", sender)
 	} else {
 		sender = ""
 	}
 
+	// forwarding and panic keywords need the text as it was typed, cleanstring lowercases it and joins the words with -
+	message := strings.TrimSpace(esc(e.Message))
+
 	log.Println(fmt.Sprintf("info: Message ("+strconv.Itoa(len(tmessage))+") from %v %v\n", sender, tmessage))
+	messageStoreAdd(sender, tmessage)
+	b.panicTextMessage(senderName, message)
+
+	var channel string
+	if len(e.Channels) > 0 {
+		channel = e.Channels[0].Name
+	} else if len(e.Trees) > 0 {
+		channel = e.Trees[0].Name
+	}
+	go b.messageForward(senderName, channel, len(e.Users) > 0, message)
 
 	for _, tts := range Config.Global.Software.TTS.Sound {
 		if tts.Action == "message" {
@@ -161,58 +181,6 @@ func (b *Talkkonnect) OnTextMessage(e *gumble.TextMessageEvent) {
 			}
 		}
 	}
-
-	if Config.Global.Hardware.TargetBoard == "rpi" {
-		if LCDEnabled {
-			LcdText[0] = "Msg From " + sender
-			LcdText[1] = tmessage
-			LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
-		}
-		if OLEDEnabled {
-			oledDisplay(false, 2, 1, "Msg From "+sender)
-			if len(tmessage) <= 21 {
-				oledDisplay(false, 3, 1, tmessage)
-				oledDisplay(false, 4, 1, "")
-				oledDisplay(false, 5, 1, "")
-				oledDisplay(false, 6, 1, "")
-				oledDisplay(false, 7, 1, "")
-				return
-			}
-			if len(tmessage) <= 42 {
-				oledDisplay(false, 3, 1, tmessage[0:21])
-				oledDisplay(false, 4, 1, tmessage[21:])
-				oledDisplay(false, 5, 1, "")
-				oledDisplay(false, 6, 1, "")
-				oledDisplay(false, 7, 1, "")
-				return
-			}
-			if len(tmessage) <= 63 {
-				oledDisplay(false, 3, 1, tmessage[0:21])
-				oledDisplay(false, 4, 1, tmessage[21:42])
-				oledDisplay(false, 5, 1, tmessage[42:])
-				oledDisplay(false, 6, 1, "")
-				oledDisplay(false, 7, 1, "")
-				return
-			}
-			if len(tmessage) <= 84 {
-				oledDisplay(false, 3, 1, tmessage[0:21])
-				oledDisplay(false, 4, 1, tmessage[21:42])
-				oledDisplay(false, 5, 1, tmessage[42:63])
-				oledDisplay(false, 6, 1, tmessage[63:])
-				oledDisplay(false, 7, 1, "")
-				return
-			}
-			if len(tmessage) <= 105 {
-				oledDisplay(false, 3, 1, tmessage[0:20])
-				oledDisplay(false, 4, 1, tmessage[21:44])
-				oledDisplay(false, 5, 1, tmessage[42:63])
-				oledDisplay(false, 6, 1, tmessage[63:84])
-				oledDisplay(false, 7, 1, tmessage[84:])
-				return
-			}
-		}
-	}
-
 }
 
 func (b *Talkkonnect) OnUserChange(e *gumble.UserChangeEvent) {
@@ -240,6 +208,7 @@ func (b *Talkkonnect) OnUserChange(e *gumble.UserChangeEvent) {
 		info = "chg channel"
 		if e.User.Name == b.Client.Self.Name {
 			log.Println("info: You Changed Channel to ", e.User.Channel.Name)
+			MyLedStripChannel(e.User.Channel.Name)
 		} else {
 			log.Println("info:", cleanstring(e.User.Name), " Changed Channel to ", e.User.Channel.Name)
 		}
@@ -310,6 +279,9 @@ func (b *Talkkonnect) OnUserList(e *gumble.UserListEvent) {
 
 func (b *Talkkonnect) OnACL(e *gumble.ACLEvent) {
 	log.Println("debug: On ACL Event Detected")
+	if Config.Global.Software.RemoteControl.Mumble.Enabled && e.ACL != nil {
+		chatCommandUpdateGroups(e.ACL)
+	}
 }
 
 func (b *Talkkonnect) OnBanList(e *gumble.BanListEvent) {
@@ -0,0 +1,327 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * panic.go talkkonnects function to run panic alerts through arm, active and acknowledged or cancelled states with repeats and escalation
+ */
+
+package talkkonnect
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	panicIdle         = "idle"
+	panicArmed        = "armed"
+	panicActive       = "active"
+	panicAcknowledged = "acknowledged"
+	panicCancelled    = "cancelled"
+)
+
+var (
+	panicState     = panicIdle
+	panicStarted   time.Time
+	panicStop      chan struct{}
+	panicEscalated []bool
+	panicMutex     sync.Mutex
+)
+
+// panicTrigger arms the panic alert, it goes active after the arm time unless cancelled, pressing again while armed goes active at once
+func (b *Talkkonnect) panicTrigger() {
+	panicMutex.Lock()
+	defer panicMutex.Unlock()
+
+	switch panicState {
+	case panicArmed:
+		log.Println("alert: Panic Triggered Again While Armed Going Active Now")
+		b.panicActivate()
+		return
+	case panicActive:
+		log.Println("info: Panic Already Active Waiting For Acknowledge Or Cancel")
+		return
+	}
+
+	panicStop = make(chan struct{})
+	if Config.Global.Hardware.PanicFunction.ArmSecs <= 0 {
+		b.panicActivate()
+		return
+	}
+
+	panicState = panicArmed
+	log.Printf("alert: Panic Armed Going Active In %v Seconds Unless Cancelled\n", Config.Global.Hardware.PanicFunction.ArmSecs)
+	displayWidgetSet("status", "Panic Armed")
+	ledPatternState("panicarmed", true)
+
+	go func(stop chan struct{}) {
+		select {
+		case <-stop:
+		case <-time.After(time.Duration(Config.Global.Hardware.PanicFunction.ArmSecs) * time.Second):
+			panicMutex.Lock()
+			if panicState == panicArmed {
+				b.panicActivate()
+			}
+			panicMutex.Unlock()
+		}
+	}(panicStop)
+}
+
+// panicRaise goes active at once without arming, for alerts raised by talkkonnect itself rather than the panic button.
+// The channel closes once the first alert has played into the stream, it is nil when the panic was already active
+func (b *Talkkonnect) panicRaise() <-chan struct{} {
+	panicMutex.Lock()
+	defer panicMutex.Unlock()
+
+	switch panicState {
+	case panicActive:
+		return nil
+	case panicIdle:
+		panicStop = make(chan struct{})
+	}
+	return b.panicActivate()
+}
+
+// panicActivate sends the first alert and starts the repeat and escalation loop, the caller must hold panicMutex
+func (b *Talkkonnect) panicActivate() <-chan struct{} {
+	panicState = panicActive
+	panicStarted = time.Now()
+	panicEscalated = make([]bool, len(Config.Global.Hardware.PanicFunction.Escalation))
+	ledPatternState("panicarmed", false)
+	ledPatternState("panic", true)
+	displayWidgetSet("status", "Panic Active")
+	log.Println("alert: Panic Active")
+
+	played := make(chan struct{})
+	go b.panicAlert(true, played)
+	go b.panicLoop(panicStop)
+	return played
+}
+
+// panicLoop repeats the alert every repeat interval and escalates on schedule until the panic is acknowledged or cancelled
+func (b *Talkkonnect) panicLoop(stop chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var repeats int
+	lastAlert := time.Now()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		panicMutex.Lock()
+		if panicState != panicActive {
+			panicMutex.Unlock()
+			return
+		}
+		elapsed := time.Since(panicStarted)
+		for index, escalation := range Config.Global.Hardware.PanicFunction.Escalation {
+			if escalation.Enabled && !panicEscalated[index] && elapsed >= time.Duration(escalation.AfterSecs)*time.Second {
+				panicEscalated[index] = true
+				go b.panicEscalate(index)
+			}
+		}
+		panicMutex.Unlock()
+
+		repeatSecs := Config.Global.Hardware.PanicFunction.RepeatSecs
+		maxRepeats := Config.Global.Hardware.PanicFunction.MaxRepeats
+		if repeatSecs > 0 && time.Since(lastAlert) >= time.Duration(repeatSecs)*time.Second && (maxRepeats == 0 || repeats < maxRepeats) {
+			repeats++
+			lastAlert = time.Now()
+			log.Printf("alert: Panic Not Acknowledged Repeating Alert %v\n", repeats)
+			b.panicAlert(false, nil)
+		}
+	}
+}
+
+// panicAlert sends the panic message, ident and position and plays the panic file into the stream, played is closed once
+// the file has played. The first alert also locks tx, emails, records and goes low profile
+func (b *Talkkonnect) panicAlert(first bool, played chan struct{}) {
+	if !IsConnected {
+		log.Println("warn: Panic Alert Not Sent Not Connected")
+		if played != nil {
+			close(played)
+		}
+		return
+	}
+
+	b.SendMessage(Config.Global.Hardware.PanicFunction.Message, Config.Global.Hardware.PanicFunction.RecursiveSendMessage)
+
+	if Config.Global.Hardware.PanicFunction.SendIdent {
+		b.SendMessage(fmt.Sprintf("My Username is %s and Ident is %s", b.Username, b.Ident), Config.Global.Hardware.PanicFunction.RecursiveSendMessage)
+	}
+
+	if Config.Global.Hardware.PanicFunction.SendGpsLocation && Config.Global.Hardware.GPS.Enabled {
+		if goodGPSRead, err := getGpsPosition(gnssRequestWaitSecs); err != nil || !goodGPSRead {
+			log.Println("warn: Could Not Get a Good GPS Read For Panic Alert")
+		} else {
+			log.Println("info: Sending GPS Info My Message")
+			fix, _ := gnssLatest()
+			gpsMessage := "My GPS Coordinates are " + " Latitude " + strconv.FormatFloat(fix.Lattitude, 'f', 6, 64) + " Longitude " + strconv.FormatFloat(fix.Longitude, 'f', 6, 64)
+			b.SendMessage(gpsMessage, Config.Global.Hardware.PanicFunction.RecursiveSendMessage)
+		}
+	}
+
+	if len(Config.Global.Hardware.PanicFunction.FilenameAndPath) > 0 {
+		IsPlayStream = true
+		b.playIntoStream(Config.Global.Hardware.PanicFunction.FilenameAndPath, Config.Global.Hardware.PanicFunction.Volume)
+		IsPlayStream = false
+	}
+	if played != nil {
+		close(played)
+	}
+
+	if targetBoardHasGPIO() {
+		if LCDEnabled {
+			LcdText = [4]string{"nil", "nil", "nil", "Panic Message Sent!"}
+			LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
+		}
+		if OLEDEnabled {
+			oledDisplay(false, 6, 1, "Panic Message Sent!")
+		}
+	}
+
+	if !first {
+		return
+	}
+
+	if Config.Global.Hardware.PanicFunction.TxLockEnabled && Config.Global.Hardware.PanicFunction.TxLockTimeOutSecs > 0 {
+		b.TxLockTimer()
+	}
+
+	if Config.Global.Hardware.PanicFunction.PMailEnabled {
+		log.Println("info: Sending Panic Alert Email To Predefined Email Address")
+		b.cmdSendEmail()
+	}
+
+	if Config.Global.Hardware.AudioRecordFunction.Enabled {
+		log.Println("info: Running sox for Audio Recording...")
+		AudioRecordAmbient()
+	}
+
+	if Config.Global.Hardware.PanicFunction.PLowProfile {
+		GPIOOutAll("led/relay", "off")
+		log.Println("info: Low Profile Lights Option is Enabled. Turning All Leds Off During Panic Event")
+		if LCDEnabled {
+			log.Println("info: Low Profile Lights is Enabled. Turning Off Display During Panic Event")
+			LcdText = [4]string{"", "", "", ""}
+			LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
+		}
+		if OLEDEnabled {
+			oledDisplay(true, 0, 0, "")
+		}
+	}
+}
+
+// panicEscalate widens the alert to another voice target or channel and sends the escalation message there
+func (b *Talkkonnect) panicEscalate(index int) {
+	escalation := Config.Global.Hardware.PanicFunction.Escalation[index]
+	log.Printf("alert: Panic Escalation %v After %v Seconds\n", index+1, escalation.AfterSecs)
+
+	if len(escalation.Channel) > 0 {
+		b.ChangeChannel(escalation.Channel)
+	}
+	if escalation.VoiceTarget > 0 {
+		b.cmdSendVoiceTargets(escalation.VoiceTarget)
+	}
+	message := escalation.Message
+	if len(message) == 0 {
+		message = Config.Global.Hardware.PanicFunction.Message
+	}
+	b.SendMessage(message, Config.Global.Hardware.PanicFunction.RecursiveSendMessage)
+	if len(Config.Global.Hardware.PanicFunction.FilenameAndPath) > 0 {
+		IsPlayStream = true
+		b.playIntoStream(Config.Global.Hardware.PanicFunction.FilenameAndPath, Config.Global.Hardware.PanicFunction.Volume)
+		IsPlayStream = false
+	}
+}
+
+// panicEnd moves an armed or active panic to acknowledged or cancelled and stops the repeats
+func (b *Talkkonnect) panicEnd(state string, by string) bool {
+	panicMutex.Lock()
+	defer panicMutex.Unlock()
+
+	if panicState != panicArmed && panicState != panicActive {
+		log.Printf("info: No Panic To Mark %v\n", state)
+		return false
+	}
+
+	wasActive := panicState == panicActive
+	close(panicStop)
+	ledPatternState("panicarmed", false)
+	ledPatternState("panic", false)
+	log.Printf("alert: Panic %v By %v\n", strings.Title(state), by)
+	displayWidgetSet("status", "Panic "+strings.Title(state))
+
+	if wasActive && IsConnected {
+		go b.SendMessage(fmt.Sprintf("Panic %v by %v", state, by), Config.Global.Hardware.PanicFunction.RecursiveSendMessage)
+	}
+	panicState = panicIdle
+	return true
+}
+
+// cmdPanicAck is the dispatcher acknowledging the panic from mqtt or http
+func (b *Talkkonnect) cmdPanicAck() {
+	b.panicEnd(panicAcknowledged, "dispatcher")
+}
+
+// cmdPanicCancel cancels the panic, a pin is needed when one is set in the config
+func (b *Talkkonnect) cmdPanicCancel(pin string) {
+	if len(Config.Global.Hardware.PanicFunction.CancelPIN) > 0 && pin != Config.Global.Hardware.PanicFunction.CancelPIN {
+		log.Println("warn: Panic Cancel With Wrong PIN Ignored")
+		return
+	}
+	b.panicEnd(panicCancelled, "user")
+}
+
+// panicTextMessage acknowledges on the ack keyword or cancels on "cancel <pin>" while a panic is armed or active
+func (b *Talkkonnect) panicTextMessage(sender string, message string) {
+	panicMutex.Lock()
+	pending := panicState == panicArmed || panicState == panicActive
+	panicMutex.Unlock()
+	if !pending {
+		return
+	}
+
+	fields := strings.Fields(strings.ToLower(message))
+	if len(fields) == 0 {
+		return
+	}
+	if keyword := Config.Global.Hardware.PanicFunction.AckKeyword; len(keyword) > 0 && fields[0] == strings.ToLower(keyword) {
+		b.panicEnd(panicAcknowledged, sender)
+		return
+	}
+	if fields[0] == "cancel" && len(fields) == 2 && len(Config.Global.Hardware.PanicFunction.CancelPIN) > 0 && fields[1] == Config.Global.Hardware.PanicFunction.CancelPIN {
+		b.panicEnd(panicCancelled, sender)
+	}
+}
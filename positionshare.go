@@ -0,0 +1,206 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * positionshare.go talkkonnects function to share the position in the mumble comment and report where the other units are
+ */
+
+package talkkonnect
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPositionShareIntervalSecs = 60
+	positionShareCommentWaitSecs     = 2
+)
+
+// the tag other units read from the comment, lat lon speed in km/h and the unix time of the fix
+var positionShareTag = regexp.MustCompile(`\[tkpos (-?[0-9.]+) (-?[0-9.]+) ([0-9.]+) ([0-9]+)\]`)
+
+// the whole suffix positionShare writes, the server keeps the comment of a registered user so one is there from the last run
+var positionShareSuffix = regexp.MustCompile(` <br/>Position <a href="[^"]*">[^<]*</a> [^\[<]*\[tkpos [^\]]*\]`)
+
+var compassPoints = []string{"north", "north east", "east", "south east", "south", "south west", "west", "north west"}
+
+// positionShareUserStruct is where another unit is as seen from here
+type positionShareUserStruct struct {
+	Name     string
+	Distance float64
+	Bearing  float64
+	Speed    float64
+	Age      time.Duration
+}
+
+// positionShare keeps the position at the end of the mumble comment, whatever comment was set before stays in front
+func (b *Talkkonnect) positionShare() {
+	if !Config.Global.Hardware.PositionShare.Enabled {
+		return
+	}
+	interval := time.Duration(Config.Global.Hardware.PositionShare.IntervalSecs) * time.Second
+	if interval <= 0 {
+		interval = defaultPositionShareIntervalSecs * time.Second
+	}
+
+	subscriber := gnssSubscribe("positionshare", interval)
+	for fix := range subscriber.C {
+		if !IsConnected || b.Client == nil || b.Client.Self == nil {
+			continue
+		}
+		suffix := fmt.Sprintf(" <br/>Position <a href=\"http://www.google.com/maps/place/%.6f,%.6f\">%.5f,%.5f</a> %.0f km/h at %v UTC [tkpos %.6f %.6f %.1f %d]",
+			fix.Lattitude, fix.Longitude, fix.Lattitude, fix.Longitude, fix.Speed*1.852, fix.DateTime.UTC().Format("15:04"),
+			fix.Lattitude, fix.Longitude, fix.Speed*1.852, fix.DateTime.Unix())
+
+		// a comment set by the comment button or a command since the last update is kept as the base
+		base := positionShareSuffix.ReplaceAllString(b.Client.Self.Comment, "")
+		b.Client.Self.SetComment(base + suffix)
+		log.Printf("debug: Position Shared In Comment %.6f,%.6f\n", fix.Lattitude, fix.Longitude)
+	}
+}
+
+// positionShareReport reads the position tags from the comments of the other users, asking the server for comments
+// that only came as a hash, and returns them nearest first
+func (b *Talkkonnect) positionShareReport() ([]positionShareUserStruct, error) {
+	if !IsConnected {
+		return nil, errors.New("not connected")
+	}
+	fix, fresh := gnssLatest()
+	if !fresh {
+		return nil, errors.New("no gps fix")
+	}
+
+	var requested bool
+	for _, user := range b.Client.Users {
+		if user != b.Client.Self && len(user.Comment) == 0 && len(user.CommentHash) > 0 {
+			user.RequestComment()
+			requested = true
+		}
+	}
+	if requested {
+		time.Sleep(positionShareCommentWaitSecs * time.Second)
+	}
+
+	var users []positionShareUserStruct
+	for _, user := range b.Client.Users {
+		if user == b.Client.Self {
+			continue
+		}
+		// older versions kept adding a suffix each restart, the last tag is the newest position
+		matches := positionShareTag.FindAllStringSubmatch(user.Comment, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		match := matches[len(matches)-1]
+		lat, _ := strconv.ParseFloat(match[1], 64)
+		lon, _ := strconv.ParseFloat(match[2], 64)
+		speed, _ := strconv.ParseFloat(match[3], 64)
+		unix, _ := strconv.ParseInt(match[4], 10, 64)
+		users = append(users, positionShareUserStruct{
+			Name:     user.Name,
+			Distance: gnssDistance(fix.Lattitude, fix.Longitude, lat, lon),
+			Bearing:  gnssBearing(fix.Lattitude, fix.Longitude, lat, lon),
+			Speed:    speed,
+			Age:      time.Since(time.Unix(unix, 0)),
+		})
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Distance < users[j].Distance })
+	return users, nil
+}
+
+func compassPoint(bearing float64) string {
+	return compassPoints[int(math.Round(bearing/45))%len(compassPoints)]
+}
+
+func compassAbbreviation(bearing float64) string {
+	var abbreviation string
+	for _, word := range strings.Fields(compassPoint(bearing)) {
+		abbreviation += strings.ToUpper(word[:1])
+	}
+	return abbreviation
+}
+
+func formatDistance(metres float64) string {
+	if metres < 1000 {
+		return fmt.Sprintf("%.0f m", metres)
+	}
+	return fmt.Sprintf("%.1f km", metres/1000)
+}
+
+func spokenDistance(metres float64) string {
+	if metres < 1000 {
+		return fmt.Sprintf("%.0f metres", metres)
+	}
+	return fmt.Sprintf("%.1f kilometres", metres/1000)
+}
+
+// positionShareText is the report as one line per user for chat replies and the display
+func positionShareText(users []positionShareUserStruct) string {
+	if len(users) == 0 {
+		return "no other units sharing position"
+	}
+	var lines []string
+	for _, user := range users {
+		lines = append(lines, fmt.Sprintf("%v %v %v %.0f° %.0f km/h %v ago", user.Name, formatDistance(user.Distance), compassAbbreviation(user.Bearing), user.Bearing, user.Speed, user.Age.Round(time.Minute)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cmdWhereIsEveryone shows and or speaks the distance and direction to every unit that shares its position
+func (b *Talkkonnect) cmdWhereIsEveryone() {
+	users, err := b.positionShareReport()
+	if err != nil {
+		log.Println("warn: Where Is Everyone ", err)
+		if Config.Global.Hardware.PositionShare.Speak {
+			go b.Speak("Where is everyone failed, "+err.Error(), "local", Config.Global.Software.TTS.Volumelevel, 0, 1, Config.Global.Software.TTSMessages.TTSLanguage)
+		}
+		return
+	}
+
+	text := positionShareText(users)
+	for _, line := range strings.Split(text, "\n") {
+		log.Println("info: Where Is Everyone ", line)
+	}
+	displayWidgetSet("where", text)
+
+	if Config.Global.Hardware.PositionShare.Speak {
+		var spoken []string
+		for _, user := range users {
+			spoken = append(spoken, fmt.Sprintf("%v, %v %v", user.Name, spokenDistance(user.Distance), compassPoint(user.Bearing)))
+		}
+		if len(spoken) == 0 {
+			spoken = append(spoken, "No other units are sharing their position")
+		}
+		go b.Speak(strings.Join(spoken, ". "), "local", Config.Global.Software.TTS.Volumelevel, 0, 1, Config.Global.Software.TTSMessages.TTSLanguage)
+	}
+}
@@ -24,7 +24,7 @@
  * My Blog is at www.talkkonnect.com
  * The source code is hosted at github.com/talkkonnect
  *
- * radio.go -> talkkonnect function to interface to radio modules (currently sa818 only)
+ * radio.go -> talkkonnect function to interface to radio modules through the driver in radiodriver.go
 
  */
 
@@ -33,8 +33,6 @@ package talkkonnect
 import (
 	"log"
 	"time"
-
-	"github.com/talkkonnect/sa818"
 )
 
 var CurrentChannelIndex = 0
@@ -42,37 +40,26 @@ var MoveChannelIndex = 0
 var EnabledChannelCounter = 0
 
 func radioSetChannel(channelID string) {
-	DMOSetup.SerialOptions.PortName = Config.Global.Hardware.Radio.Sa818.Serial.Port
-	DMOSetup.SerialOptions.BaudRate = Config.Global.Hardware.Radio.Sa818.Serial.Baud
-	DMOSetup.SerialOptions.DataBits = Config.Global.Hardware.Radio.Sa818.Serial.Databits
-	DMOSetup.SerialOptions.StopBits = Config.Global.Hardware.Radio.Sa818.Serial.Stopbits
-	DMOSetup.SerialOptions.MinimumReadSize = 2
-	DMOSetup.SerialOptions.InterCharacterTimeout = 200
-	RadioModuleSA818Channel(channelID, true, true)
+	radioScanEnd()
+	radioModuleChannel(channelID, true, true)
 }
 
 func radioChannelIncrement(command string) {
-	DMOSetup.SerialOptions.PortName = Config.Global.Hardware.Radio.Sa818.Serial.Port
-	DMOSetup.SerialOptions.BaudRate = Config.Global.Hardware.Radio.Sa818.Serial.Baud
-	DMOSetup.SerialOptions.DataBits = Config.Global.Hardware.Radio.Sa818.Serial.Databits
-	DMOSetup.SerialOptions.StopBits = Config.Global.Hardware.Radio.Sa818.Serial.Stopbits
-	DMOSetup.SerialOptions.MinimumReadSize = 2
-	DMOSetup.SerialOptions.InterCharacterTimeout = 200
-
+	radioScanEnd()
 	if command == "up" {
 		if Config.Global.Hardware.Radio.Enabled {
 			if len(radioChannels)-1 < CurrentChannelIndex+1 {
 				MoveChannelIndex = 0
 				CurrentChannelIndex = 0
 				log.Printf("info: Moving %v To Channel ID %v Name %v\n", command, radioChannels[MoveChannelIndex].ID, radioChannels[MoveChannelIndex].Name)
-				RadioModuleSA818Channel(radioChannels[MoveChannelIndex].ID, true, true)
+				radioModuleChannel(radioChannels[MoveChannelIndex].ID, true, true)
 				return
 			}
 			if len(radioChannels)-1 >= CurrentChannelIndex+1 {
 				MoveChannelIndex = CurrentChannelIndex + 1
 				CurrentChannelIndex++
 				log.Printf("info: Moving %v To Channel ID %v Name %v\n", command, radioChannels[MoveChannelIndex].ID, radioChannels[MoveChannelIndex].Name)
-				RadioModuleSA818Channel(radioChannels[MoveChannelIndex].ID, true, true)
+				radioModuleChannel(radioChannels[MoveChannelIndex].ID, true, true)
 				return
 			}
 		} else {
@@ -87,14 +74,14 @@ func radioChannelIncrement(command string) {
 				MoveChannelIndex = len(radioChannels) - 1
 				CurrentChannelIndex = len(radioChannels) - 1
 				log.Printf("info: Moving %v To Channel ID %v Name %v\n", command, radioChannels[MoveChannelIndex].ID, radioChannels[MoveChannelIndex].Name)
-				RadioModuleSA818Channel(radioChannels[MoveChannelIndex].ID, true, true)
+				radioModuleChannel(radioChannels[MoveChannelIndex].ID, true, true)
 				return
 			}
 			if CurrentChannelIndex-1 >= 0 {
 				MoveChannelIndex = CurrentChannelIndex - 1
 				CurrentChannelIndex--
 				log.Printf("info: Moving %v To Channel ID %v Name %v\n", command, radioChannels[MoveChannelIndex].ID, radioChannels[MoveChannelIndex].Name)
-				RadioModuleSA818Channel(radioChannels[MoveChannelIndex].ID, true, true)
+				radioModuleChannel(radioChannels[MoveChannelIndex].ID, true, true)
 				return
 			}
 		} else {
@@ -111,87 +98,96 @@ func createEnabledRadioChannels() {
 			if channel.ID == Config.Global.Hardware.Radio.ConnectChannelID {
 				CurrentChannelIndex = EnabledChannelCounter - 1
 			}
-			radioChannels = append(radioChannels, radioChannelsStruct{channel.ID, channel.Name, channel.ItemInList, channel.Bandwidth, channel.Rxfreq, channel.Txfreq, channel.Squelch, channel.Ctcsstone, channel.Dcstone, channel.Predeemph, channel.Highpass, channel.Lowpass, channel.Volume})
+			radioChannels = append(radioChannels, radioChannelsStruct{channel.ID, channel.Name, channel.ItemInList, channel.Bandwidth, channel.Rxfreq, channel.Txfreq, channel.Squelch, channel.Ctcsstone, channel.Dcstone, channel.Predeemph, channel.Highpass, channel.Lowpass, channel.Volume, channel.TXPower})
 		}
 	}
 }
 
-func RadioModuleSA818Channel(useChannelID string, setVolumeToo bool, setFilterToo bool) {
-	found, name := findChannelNameByID(useChannelID)
+func radioModuleChannel(useChannelID string, setVolumeToo bool, setFilterToo bool) {
+	if radioModule == nil {
+		log.Println("error: Radio Module Not Open")
+		return
+	}
+	channel, found := findChannelByID(useChannelID)
 	if found {
-		log.Printf("info: Found Channel ID %v Name %v\n", useChannelID, name)
-		setFrequency()
+		log.Printf("info: Found Channel ID %v Name %v\n", useChannelID, channel.Name)
+		setFrequency(channel)
+		if len(channel.TXPower) > 0 {
+			setPower(channel)
+		}
 		if setVolumeToo {
 			time.Sleep(500 * time.Millisecond)
-			setVolume()
+			setVolume(channel)
 		}
 		if setFilterToo {
 			time.Sleep(700 * time.Millisecond)
-			setFilter()
+			setFilter(channel)
 		}
 	} else {
 		log.Printf("error: Not Found Channel ID %v\n", useChannelID)
 	}
 }
 
-func findChannelNameByID(findChannelID string) (bool, string) {
+func findChannelByID(findChannelID string) (radioChannelsStruct, bool) {
 	var EnabledItemInList int = 0
 	for Item, channel := range Config.Global.Hardware.Radio.Sa818.Channels.Channel {
 		if channel.Enabled {
 			EnabledItemInList++
 			Config.Global.Hardware.Radio.Sa818.Channels.Channel[Item].ItemInList = EnabledItemInList
 			if channel.ID == findChannelID {
-				DMOSetup.Band = channel.Bandwidth
-				DMOSetup.Rxfreq = channel.Rxfreq
-				DMOSetup.Txfreq = channel.Txfreq
-				DMOSetup.Ctsstone = channel.Ctcsstone
-				DMOSetup.Squelch = channel.Squelch
-				DMOSetup.Dcstone = channel.Dcstone
-				DMOSetup.Predeemph = channel.Predeemph
-				DMOSetup.Highpass = channel.Highpass
-				DMOSetup.Lowpass = channel.Lowpass
-				DMOSetup.Volume = channel.Volume
-				return true, channel.Name
+				return radioChannelsStruct{channel.ID, channel.Name, EnabledItemInList, channel.Bandwidth, channel.Rxfreq, channel.Txfreq, channel.Squelch, channel.Ctcsstone, channel.Dcstone, channel.Predeemph, channel.Highpass, channel.Lowpass, channel.Volume, channel.TXPower}, true
 			}
 		}
 	}
-	return false, "not found channel"
+	return radioChannelsStruct{}, false
 }
 
 func checkVersion() {
-	err := sa818.Callsa818("CheckVersion", DMOSetup)
-	log.Println("info: CheckVersion ", err)
+	version, err := radioModule.ReadVersion()
+	if err != nil {
+		log.Printf("error: %v Check Version Error %v\n", radioModule.Name(), err)
+		return
+	}
+	log.Printf("info: %v Version %v\n", radioModule.Name(), version)
 }
 
 func checkRSSI() {
-	err := sa818.Callsa818("CheckRSSI", DMOSetup)
-	log.Println("info: Check RSSI ", err)
+	rssi, err := radioModule.ReadRSSI()
+	if err != nil {
+		log.Printf("error: %v Check RSSI Error %v\n", radioModule.Name(), err)
+		return
+	}
+	log.Printf("info: %v RSSI %v\n", radioModule.Name(), rssi)
 }
 
-func setFrequency() {
-	err := sa818.Callsa818("DMOSetupGroup", DMOSetup)
-	//log.Printf("debug: actual data sent to module %#v", DMOSetup)
-	if err != nil {
-		log.Println("info: SAModule Set Frequecy Error ", err)
+func setFrequency(channel radioChannelsStruct) {
+	if err := radioModule.SetFrequency(channel); err != nil {
+		log.Printf("info: %v Set Frequecy Error %v\n", radioModule.Name(), err)
 	} else {
-		log.Println("info: SAModule Set Frequecy OK ")
+		log.Printf("info: %v Set Frequecy OK\n", radioModule.Name())
 	}
 }
 
-func setFilter() {
-	err := sa818.Callsa818("DMOSetupFilter", DMOSetup)
-	if err != nil {
-		log.Println("info: SAModule Setup Filter Error ", err)
+func setFilter(channel radioChannelsStruct) {
+	if err := radioModule.SetFilter(channel); err != nil {
+		log.Printf("info: %v Setup Filter Error %v\n", radioModule.Name(), err)
 	} else {
-		log.Println("info: SAModule Setup Filter OK ")
+		log.Printf("info: %v Setup Filter OK\n", radioModule.Name())
 	}
 }
 
-func setVolume() {
-	err := sa818.Callsa818("SetVolume", DMOSetup)
-	if err != nil {
-		log.Println("info: SAModule Set Volume Error ", err)
+func setVolume(channel radioChannelsStruct) {
+	if err := radioModule.SetVolume(channel.Volume); err != nil {
+		log.Printf("info: %v Set Volume Error %v\n", radioModule.Name(), err)
+	} else {
+		log.Printf("info: %v Set Volume OK\n", radioModule.Name())
+	}
+}
+
+func setPower(channel radioChannelsStruct) {
+	if err := radioModule.SetPower(channel.TXPower); err != nil {
+		log.Printf("info: %v Set Power Error %v\n", radioModule.Name(), err)
 	} else {
-		log.Println("info: SAModule Set Volume OK ")
+		log.Printf("info: %v Set Power %v OK\n", radioModule.Name(), channel.TXPower)
 	}
 }
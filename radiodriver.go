@@ -0,0 +1,267 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * radiodriver.go talkkonnects function to drive sa818, sa868, dra818 and hamlib rigctld radios through one interface
+ */
+
+package talkkonnect
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jacobsa/go-serial/serial"
+	"github.com/talkkonnect/sa818"
+)
+
+const dmoResponseTimeoutMsec = 1000
+
+// radioDriver is what the channel list, rotary and keyboard commands need from a radio module
+type radioDriver interface {
+	Name() string
+	// SetFrequency tunes rx and tx with the bandwidth, ctcss or dcs tones and squelch of the channel
+	SetFrequency(channel radioChannelsStruct) error
+	SetFilter(channel radioChannelsStruct) error
+	SetVolume(volume int) error
+	// SetPower takes high or low
+	SetPower(power string) error
+	ReadRSSI() (int, error)
+	ReadVersion() (string, error)
+}
+
+var radioModule radioDriver
+
+// radioOpen picks the driver for the driver attribute of the radio section, sa818 when it is not set
+func radioOpen() error {
+	switch Config.Global.Hardware.Radio.Driver {
+	case "", "sa818":
+		if !(Config.Global.Hardware.Radio.Sa818.Enabled && Config.Global.Hardware.Radio.Sa818.Serial.Enabled) {
+			return errors.New("sa818 and its serial port must be enabled")
+		}
+		radioModule = newSA818Radio()
+	case "sa868", "dra818":
+		if !Config.Global.Hardware.Radio.Sa818.Serial.Enabled {
+			return errors.New("serial port in the sa818 section must be enabled")
+		}
+		radioModule = &dmoRadioStruct{model: strings.ToUpper(Config.Global.Hardware.Radio.Driver)}
+	case "rigctld":
+		radioModule = &rigctldRadioStruct{}
+	default:
+		return fmt.Errorf("radio driver %v not known", Config.Global.Hardware.Radio.Driver)
+	}
+	return nil
+}
+
+// dmoRadioStruct talks the DMO at commands shared by the sa818, sa868 and dra818 modules on the serial port of the sa818 section
+type dmoRadioStruct struct {
+	model string
+	mutex sync.Mutex
+}
+
+func (radio *dmoRadioStruct) Name() string {
+	return radio.model
+}
+
+func dmoSerialOptions() serial.OpenOptions {
+	return serial.OpenOptions{
+		PortName:              Config.Global.Hardware.Radio.Sa818.Serial.Port,
+		BaudRate:              Config.Global.Hardware.Radio.Sa818.Serial.Baud,
+		DataBits:              Config.Global.Hardware.Radio.Sa818.Serial.Databits,
+		StopBits:              Config.Global.Hardware.Radio.Sa818.Serial.Stopbits,
+		MinimumReadSize:       0,
+		InterCharacterTimeout: 200,
+	}
+}
+
+// command sends one at command after the handshake and returns the reply line
+func (radio *dmoRadioStruct) command(command string) (string, error) {
+	radio.mutex.Lock()
+	defer radio.mutex.Unlock()
+
+	port, err := serial.Open(dmoSerialOptions())
+	if err != nil {
+		return "", fmt.Errorf("cannot open serial port %v", err)
+	}
+	defer port.Close()
+
+	if reply, err := dmoExchange(port, "AT+DMOCONNECT"); err != nil || !strings.HasSuffix(reply, ":0") {
+		return "", fmt.Errorf("handshake failed %v %v", reply, err)
+	}
+	return dmoExchange(port, command)
+}
+
+func dmoExchange(port io.ReadWriter, command string) (string, error) {
+	if _, err := fmt.Fprint(port, command+"\r\n"); err != nil {
+		return "", err
+	}
+
+	var reply []byte
+	buffer := make([]byte, 1)
+	deadline := time.Now().Add(dmoResponseTimeoutMsec * time.Millisecond)
+	for time.Now().Before(deadline) {
+		count, err := port.Read(buffer)
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		if count == 0 {
+			continue
+		}
+		if buffer[0] == '\n' {
+			if line := strings.TrimSpace(string(reply)); len(line) > 0 {
+				return line, nil
+			}
+			continue
+		}
+		reply = append(reply, buffer[0])
+	}
+	return "", fmt.Errorf("no reply to %v", command)
+}
+
+// set sends a setting command and checks the module answered with result 0
+func (radio *dmoRadioStruct) set(command string) error {
+	reply, err := radio.command(command)
+	if err != nil {
+		return err
+	}
+	if !strings.HasSuffix(reply, ":0") {
+		return fmt.Errorf("%v answered %v", command, reply)
+	}
+	return nil
+}
+
+// dmoTone is the ctcss tone number or the dcs code with N suffix, 0000 for none
+func dmoTone(channel radioChannelsStruct) string {
+	if channel.Dcstone > 0 {
+		return fmt.Sprintf("%03dN", channel.Dcstone)
+	}
+	return fmt.Sprintf("%04d", channel.Ctcsstone)
+}
+
+func (radio *dmoRadioStruct) SetFrequency(channel radioChannelsStruct) error {
+	return radio.set(fmt.Sprintf("AT+DMOSETGROUP=%d,%.4f,%.4f,%v,%d,%v", channel.Bandwidth, channel.Txfreq, channel.Rxfreq, dmoTone(channel), channel.Squelch, dmoTone(channel)))
+}
+
+func (radio *dmoRadioStruct) SetFilter(channel radioChannelsStruct) error {
+	return radio.set(fmt.Sprintf("AT+SETFILTER=%d,%d,%d", channel.Predeemph, channel.Highpass, channel.Lowpass))
+}
+
+func (radio *dmoRadioStruct) SetVolume(volume int) error {
+	return radio.set(fmt.Sprintf("AT+DMOSETVOLUME=%d", volume))
+}
+
+// SetPower switches the H/L pin, these modules have no power command
+func (radio *dmoRadioStruct) SetPower(power string) error {
+	return radioPowerPin(power)
+}
+
+func (radio *dmoRadioStruct) ReadRSSI() (int, error) {
+	reply, err := radio.command("RSSI?")
+	if err != nil {
+		return 0, err
+	}
+	if !strings.HasPrefix(reply, "RSSI=") {
+		return 0, fmt.Errorf("rssi answered %v", reply)
+	}
+	return strconv.Atoi(strings.TrimPrefix(reply, "RSSI="))
+}
+
+func (radio *dmoRadioStruct) ReadVersion() (string, error) {
+	reply, err := radio.command("AT+VERSION")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(reply, "+VERSION:"), nil
+}
+
+// radioPowerPin drives the txpower output pin of the radio module, on for high power
+func radioPowerPin(power string) error {
+	switch strings.ToLower(power) {
+	case "high":
+		GPIOOutPin("txpower", "on")
+	case "low":
+		GPIOOutPin("txpower", "off")
+	default:
+		return fmt.Errorf("power %v must be high or low", power)
+	}
+	return nil
+}
+
+// sa818RadioStruct sets up the sa818 through the sa818 package as before, the package does not return what the
+// module answers so rssi and version are read with the dmo commands
+type sa818RadioStruct struct {
+	dmoRadioStruct
+	setup sa818.DMOSetupStruct
+}
+
+func newSA818Radio() *sa818RadioStruct {
+	radio := &sa818RadioStruct{dmoRadioStruct: dmoRadioStruct{model: "SA818"}}
+	radio.setup.SerialOptions.PortName = Config.Global.Hardware.Radio.Sa818.Serial.Port
+	radio.setup.SerialOptions.BaudRate = Config.Global.Hardware.Radio.Sa818.Serial.Baud
+	radio.setup.SerialOptions.DataBits = Config.Global.Hardware.Radio.Sa818.Serial.Databits
+	radio.setup.SerialOptions.StopBits = Config.Global.Hardware.Radio.Sa818.Serial.Stopbits
+	radio.setup.SerialOptions.MinimumReadSize = 2
+	radio.setup.SerialOptions.InterCharacterTimeout = 200
+	return radio
+}
+
+// call runs a sa818 package command with the channel settings, the mutex keeps it off the port while rssi is read
+func (radio *sa818RadioStruct) call(command string, channel radioChannelsStruct) error {
+	radio.mutex.Lock()
+	defer radio.mutex.Unlock()
+
+	radio.setup.Band = channel.Bandwidth
+	radio.setup.Rxfreq = channel.Rxfreq
+	radio.setup.Txfreq = channel.Txfreq
+	radio.setup.Ctsstone = channel.Ctcsstone
+	radio.setup.Squelch = channel.Squelch
+	radio.setup.Dcstone = channel.Dcstone
+	radio.setup.Predeemph = channel.Predeemph
+	radio.setup.Highpass = channel.Highpass
+	radio.setup.Lowpass = channel.Lowpass
+	radio.setup.Volume = channel.Volume
+	return sa818.Callsa818(command, radio.setup)
+}
+
+func (radio *sa818RadioStruct) SetFrequency(channel radioChannelsStruct) error {
+	return radio.call("DMOSetupGroup", channel)
+}
+
+func (radio *sa818RadioStruct) SetFilter(channel radioChannelsStruct) error {
+	return radio.call("DMOSetupFilter", channel)
+}
+
+func (radio *sa818RadioStruct) SetVolume(volume int) error {
+	radio.mutex.Lock()
+	defer radio.mutex.Unlock()
+
+	radio.setup.Volume = volume
+	return sa818.Callsa818("SetVolume", radio.setup)
+}
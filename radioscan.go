@@ -0,0 +1,278 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * radioscan.go talkkonnects function to scan the radio channel list and stop on channels with activity
+ */
+
+package talkkonnect
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRadioScanDwellMsecs    = 200
+	defaultRadioScanHangSecs      = 3
+	defaultRadioScanPriorityMsecs = 2000
+	radioScanPollMsecs            = 250
+)
+
+var (
+	radioScanMutex   sync.Mutex
+	radioScanQuit    chan struct{}
+	radioScanDone    chan struct{}
+	radioScanLockout = map[string]bool{}
+	radioSquelchOpen bool
+)
+
+// radioScanStart starts cycling the enabled radio channels, it does nothing when a scan is already running
+func radioScanStart() {
+	if !Config.Global.Hardware.Radio.Enabled || !Config.Global.Hardware.Radio.Scan.Enabled || radioModule == nil {
+		log.Println("error: Radio Scan Requested But Radio Or Scan Disabled in Config")
+		return
+	}
+	if len(radioChannels) < 2 {
+		log.Println("warn: Radio Scan Needs At Least 2 Enabled Radio Channels")
+		return
+	}
+
+	radioScanMutex.Lock()
+	defer radioScanMutex.Unlock()
+	if radioScanQuit != nil || radioScanDone != nil {
+		return
+	}
+	for _, id := range Config.Global.Hardware.Radio.Scan.Lockout {
+		radioScanLockout[id] = true
+	}
+	radioScanQuit = make(chan struct{})
+	radioScanDone = make(chan struct{})
+	log.Printf("info: Radio Scan Started Detecting Activity By %v\n", Config.Global.Hardware.Radio.Scan.Detect)
+	radioScanShow("scan", "Radio Scanning")
+	go radioScan(radioScanQuit, radioScanDone)
+}
+
+// radioScanEnd stops a running scan and returns once the scan has stopped tuning, the radio stays on the channel
+// it was on
+func radioScanEnd() bool {
+	radioScanMutex.Lock()
+	quit, done := radioScanQuit, radioScanDone
+	radioScanQuit = nil
+	radioScanMutex.Unlock()
+	if done == nil {
+		return false
+	}
+	if quit != nil {
+		close(quit)
+	}
+
+	// the scan takes radioScanMutex itself, so it is waited for without holding it
+	<-done
+	radioScanMutex.Lock()
+	if radioScanDone == done {
+		radioScanDone = nil
+	}
+	radioScanMutex.Unlock()
+	if quit == nil {
+		return false
+	}
+	log.Printf("info: Radio Scan Stopped On Channel ID %v Name %v\n", radioChannels[CurrentChannelIndex].ID, radioChannels[CurrentChannelIndex].Name)
+	radioScanShow("r"+radioChannels[CurrentChannelIndex].ID, "Radio "+radioChannels[CurrentChannelIndex].Name)
+	return true
+}
+
+func radioScanRunning() bool {
+	radioScanMutex.Lock()
+	defer radioScanMutex.Unlock()
+	return radioScanQuit != nil
+}
+
+func cmdRadioScan() {
+	if radioScanRunning() {
+		if radioScanEnd() {
+			radioModuleChannel(radioChannels[CurrentChannelIndex].ID, true, true)
+		}
+		return
+	}
+	radioScanStart()
+}
+
+// radioScanLockoutToggle locks the current channel out of the scan or lets it back in, until the next restart
+func radioScanLockoutToggle() {
+	if !Config.Global.Hardware.Radio.Enabled || len(radioChannels) == 0 {
+		log.Println("error: Radio Scan Lockout Requested But Radio Disabled in Config")
+		return
+	}
+	id := radioChannels[CurrentChannelIndex].ID
+
+	radioScanMutex.Lock()
+	defer radioScanMutex.Unlock()
+	radioScanLockout[id] = !radioScanLockout[id]
+	log.Printf("info: Radio Scan Channel ID %v Locked Out %v\n", id, radioScanLockout[id])
+}
+
+// radioSquelch follows the squelch output of the radio module, wired as an input with the radiosquelch command
+func radioSquelch(open bool) {
+	radioScanMutex.Lock()
+	defer radioScanMutex.Unlock()
+	radioSquelchOpen = open
+}
+
+func radioScanLockedOut(id string) bool {
+	radioScanMutex.Lock()
+	defer radioScanMutex.Unlock()
+	return radioScanLockout[id]
+}
+
+func radioScan(quit chan struct{}, done chan struct{}) {
+	defer close(done)
+	scan := Config.Global.Hardware.Radio.Scan
+	priority := -1
+	for index, channel := range radioChannels {
+		if channel.ID == scan.PriorityChannelID {
+			priority = index
+		}
+	}
+	lastPriority := time.Now()
+	index := CurrentChannelIndex
+
+	for {
+		select {
+		case <-quit:
+			return
+		default:
+		}
+
+		if priority >= 0 && time.Since(lastPriority) >= time.Duration(scan.PriorityMsecs)*time.Millisecond {
+			lastPriority = time.Now()
+			if radioScanChannel(priority, quit) {
+				lastPriority = time.Now()
+				continue
+			}
+		}
+
+		next := radioScanNext(index, priority)
+		if next < 0 {
+			log.Println("warn: Radio Scan Has Every Channel Locked Out")
+			if !radioScanWait(time.Duration(scan.HangSecs)*time.Second, quit) {
+				return
+			}
+			continue
+		}
+		index = next
+		if radioScanChannel(index, quit) {
+			// after a hold the priority channel gets looked at first
+			lastPriority = time.Time{}
+		}
+	}
+}
+
+// radioScanNext finds the channel after index that is not locked out, the priority channel has its own turn
+func radioScanNext(index int, priority int) int {
+	for step := 1; step <= len(radioChannels); step++ {
+		next := (index + step) % len(radioChannels)
+		if next != priority && !radioScanLockedOut(radioChannels[next].ID) {
+			return next
+		}
+	}
+	return -1
+}
+
+// radioScanChannel tunes the channel, waits the dwell time and holds there while it is active, true when it was.
+// Hops only set the frequency, a channel held on gets its volume and filter too
+func radioScanChannel(index int, quit chan struct{}) bool {
+	scan := Config.Global.Hardware.Radio.Scan
+	channel := radioChannels[index]
+
+	if err := radioModule.SetFrequency(channel); err != nil {
+		log.Printf("error: Radio Scan %v Set Frequency For Channel ID %v Error %v\n", radioModule.Name(), channel.ID, err)
+		return false
+	}
+	CurrentChannelIndex = index
+	radioScanShow("s"+channel.ID, "")
+
+	if !radioScanWait(time.Duration(scan.DwellMsecs)*time.Millisecond, quit) || !radioScanActive() {
+		return false
+	}
+
+	log.Printf("info: Radio Scan Activity On Channel ID %v Name %v\n", channel.ID, channel.Name)
+	radioModuleChannel(channel.ID, true, true)
+	radioScanShow("r"+channel.ID, "Radio "+channel.Name)
+	lastActive := time.Now()
+	for time.Since(lastActive) < time.Duration(scan.HangSecs)*time.Second {
+		if !radioScanWait(radioScanPollMsecs*time.Millisecond, quit) {
+			return true
+		}
+		if radioScanActive() {
+			lastActive = time.Now()
+		}
+	}
+	log.Printf("info: Radio Scan Resuming After Channel ID %v Name %v\n", channel.ID, channel.Name)
+	radioScanShow("scan", "Radio Scanning")
+	return true
+}
+
+// radioScanActive is true when the squelch input is open or the rssi is at the threshold, depending on the detect setting
+func radioScanActive() bool {
+	if Config.Global.Hardware.Radio.Scan.Detect == "squelch" {
+		radioScanMutex.Lock()
+		defer radioScanMutex.Unlock()
+		return radioSquelchOpen
+	}
+
+	rssi, err := radioModule.ReadRSSI()
+	if err != nil {
+		log.Printf("debug: Radio Scan %v Read RSSI Error %v\n", radioModule.Name(), err)
+		return false
+	}
+	return rssi >= Config.Global.Hardware.Radio.Scan.RSSIThreshold
+}
+
+// radioScanWait sleeps for the duration, false when the scan was stopped meanwhile
+func radioScanWait(duration time.Duration, quit chan struct{}) bool {
+	select {
+	case <-quit:
+		return false
+	case <-time.After(duration):
+		return true
+	}
+}
+
+// radioScanShow puts the short text on the max7219 and the long text on the lcd, the lcd is left alone while hopping
+func radioScanShow(short string, long string) {
+	if Config.Global.Hardware.IO.Max7219.Enabled {
+		sevenSegmentShow(short)
+	}
+	if len(long) == 0 {
+		return
+	}
+	displayWidgetSet("radiochannel", long)
+	if targetBoardHasGPIO() && LCDEnabled {
+		LcdText[3] = long
+		LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
+	}
+}
@@ -0,0 +1,241 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * rigctld.go talkkonnects function to drive any radio hamlib supports through the rigctld network daemon
+ */
+
+package talkkonnect
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRigctldHost  = "localhost"
+	defaultRigctldPort  = 4532
+	rigctldTimeoutSecs  = 5
+	radioMaxLevel       = 8.0
+	rigctldNotAvailable = -11
+)
+
+// ctcssTones are the tones in Hz of the ctcss numbers used in the channel list, the numbering of the sa818 family
+var ctcssTones = []float64{0, 67.0, 71.9, 74.4, 77.0, 79.7, 82.5, 85.4, 88.5, 91.5, 94.8, 97.4, 100.0, 103.5, 107.2, 110.9,
+	114.8, 118.8, 123.0, 127.3, 131.8, 136.5, 141.3, 146.2, 151.4, 156.7, 162.2, 167.9, 173.8, 179.9, 186.2, 192.8,
+	203.5, 210.7, 218.1, 225.7, 233.6, 241.8, 250.3}
+
+// rigctldRadioStruct keeps one connection to rigctld and opens it again after a connection error
+type rigctldRadioStruct struct {
+	conn        net.Conn
+	reader      *bufio.Reader
+	mutex       sync.Mutex
+	unavailable map[string]bool
+}
+
+func (radio *rigctldRadioStruct) Name() string {
+	return "rigctld"
+}
+
+// rigctldReportError is a RPRT with an error code, rigctld answered so the connection is still good
+type rigctldReportError struct {
+	command string
+	code    int
+}
+
+func (err *rigctldReportError) Error() string {
+	return fmt.Sprintf("%v answered RPRT %v", err.command, err.code)
+}
+
+// command sends one rigctld command and returns the first reply line, a RPRT with an error code is an error
+func (radio *rigctldRadioStruct) command(command string) (string, error) {
+	radio.mutex.Lock()
+	defer radio.mutex.Unlock()
+
+	if radio.conn == nil {
+		host := Config.Global.Hardware.Radio.Rigctld.Host
+		if len(host) == 0 {
+			host = defaultRigctldHost
+		}
+		port := Config.Global.Hardware.Radio.Rigctld.Port
+		if port == 0 {
+			port = defaultRigctldPort
+		}
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), rigctldTimeoutSecs*time.Second)
+		if err != nil {
+			return "", err
+		}
+		radio.conn = conn
+		radio.reader = bufio.NewReader(conn)
+	}
+
+	radio.conn.SetDeadline(time.Now().Add(rigctldTimeoutSecs * time.Second))
+	reply, err := radio.exchange(command)
+	if _, report := err.(*rigctldReportError); report {
+		return "", err
+	}
+	if err != nil {
+		radio.conn.Close()
+		radio.conn = nil
+		return "", err
+	}
+	return reply, nil
+}
+
+func (radio *rigctldRadioStruct) exchange(command string) (string, error) {
+	if _, err := fmt.Fprint(radio.conn, command+"\n"); err != nil {
+		return "", err
+	}
+	reply, err := radio.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	reply = strings.TrimSpace(reply)
+	if strings.HasPrefix(reply, "RPRT ") {
+		code, err := strconv.Atoi(strings.TrimPrefix(reply, "RPRT "))
+		if err != nil {
+			return "", fmt.Errorf("%v answered %v", strings.Fields(command)[0], reply)
+		}
+		if code != 0 {
+			return "", &rigctldReportError{strings.Fields(command)[0], code}
+		}
+	}
+	return reply, nil
+}
+
+// set sends a setting command, rigctld answers RPRT 0 when it worked. Settings the rig does not have, like tone
+// squelch on some rigs, are only warned about
+func (radio *rigctldRadioStruct) set(command string) error {
+	_, err := radio.command(command)
+	if report, ok := err.(*rigctldReportError); ok && report.code == rigctldNotAvailable {
+		radio.mutex.Lock()
+		defer radio.mutex.Unlock()
+		if !radio.unavailable[report.command] {
+			if radio.unavailable == nil {
+				radio.unavailable = map[string]bool{}
+			}
+			radio.unavailable[report.command] = true
+			log.Printf("warn: Radio rigctld %v Not Available On This Rig\n", report.command)
+		}
+		return nil
+	}
+	return err
+}
+
+func rigctldHz(mhz float32) int64 {
+	return int64(float64(mhz)*1000000 + 0.5)
+}
+
+// SetFrequency sets the frequency, split when tx differs, narrow or wide fm, tones and the squelch level out of 8
+func (radio *rigctldRadioStruct) SetFrequency(channel radioChannelsStruct) error {
+	if err := radio.set(fmt.Sprintf("F %d", rigctldHz(channel.Rxfreq))); err != nil {
+		return err
+	}
+
+	if channel.Txfreq != channel.Rxfreq && channel.Txfreq > 0 {
+		if err := radio.set("S 1 VFOB"); err != nil {
+			return err
+		}
+		if err := radio.set(fmt.Sprintf("I %d", rigctldHz(channel.Txfreq))); err != nil {
+			return err
+		}
+	} else if err := radio.set("S 0 VFOA"); err != nil {
+		return err
+	}
+
+	mode := "FMN"
+	if channel.Bandwidth == 1 {
+		mode = "FM"
+	}
+	if err := radio.set("M " + mode + " 0"); err != nil {
+		return err
+	}
+
+	switch {
+	case channel.Dcstone > 0:
+		if err := radio.set(fmt.Sprintf("D %d", channel.Dcstone)); err != nil {
+			return err
+		}
+		if err := radio.set(fmt.Sprintf("\\set_dcs_sql %d", channel.Dcstone)); err != nil {
+			return err
+		}
+	case channel.Ctcsstone > 0 && channel.Ctcsstone < len(ctcssTones):
+		tone := int(ctcssTones[channel.Ctcsstone]*10 + 0.5)
+		if err := radio.set(fmt.Sprintf("C %d", tone)); err != nil {
+			return err
+		}
+		if err := radio.set(fmt.Sprintf("\\set_ctcss_sql %d", tone)); err != nil {
+			return err
+		}
+	default:
+		if err := radio.set("C 0"); err != nil {
+			return err
+		}
+		if err := radio.set("\\set_ctcss_sql 0"); err != nil {
+			return err
+		}
+	}
+
+	return radio.set(fmt.Sprintf("L SQL %.3f", float64(channel.Squelch)/radioMaxLevel))
+}
+
+// SetFilter does nothing, emphasis and audio filters are set on the rig itself
+func (radio *rigctldRadioStruct) SetFilter(channel radioChannelsStruct) error {
+	return nil
+}
+
+// SetVolume scales the channel volume of 1 to 8 to the af gain
+func (radio *rigctldRadioStruct) SetVolume(volume int) error {
+	return radio.set(fmt.Sprintf("L AF %.3f", float64(volume)/radioMaxLevel))
+}
+
+func (radio *rigctldRadioStruct) SetPower(power string) error {
+	switch strings.ToLower(power) {
+	case "high":
+		return radio.set("L RFPOWER 1.0")
+	case "low":
+		return radio.set("L RFPOWER 0.25")
+	}
+	return fmt.Errorf("power %v must be high or low", power)
+}
+
+// ReadRSSI is the signal strength in dB relative to S9
+func (radio *rigctldRadioStruct) ReadRSSI() (int, error) {
+	reply, err := radio.command("l STRENGTH")
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(reply)
+}
+
+func (radio *rigctldRadioStruct) ReadVersion() (string, error) {
+	return radio.command("\\get_info")
+}
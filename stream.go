@@ -289,7 +289,7 @@ func (b *Talkkonnect) splayIntoStream(filepath string, vol float32) {
 func (b *Talkkonnect) OpenStream() {
 	if stream, err := b.New(b.Client); err != nil {
 
-		if Config.Global.Hardware.TargetBoard == "rpi" {
+		if targetBoardHasGPIO() {
 			if LCDEnabled {
 				LcdText = [4]string{"Stream Error!", "nil", "nil", "nil"}
 				LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
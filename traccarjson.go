@@ -0,0 +1,170 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * traccarjson.go talkkonnects function to post positions with battery and extra attributes as json to the traccar osmand port
+ */
+
+package talkkonnect
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// traccarJSONStruct is the location body the traccar client app posts to the osmand port
+type traccarJSONStruct struct {
+	DeviceID string `json:"device_id"`
+	Location struct {
+		Timestamp string `json:"timestamp"`
+		Coords    struct {
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+			Speed     float64 `json:"speed"`
+			Heading   float64 `json:"heading"`
+			Altitude  float64 `json:"altitude"`
+		} `json:"coords"`
+		Battery *traccarJSONBatteryStruct `json:"battery,omitempty"`
+		Extras  map[string]interface{}    `json:"extras,omitempty"`
+	} `json:"location"`
+}
+
+type traccarJSONBatteryStruct struct {
+	Level      float64 `json:"level"`
+	IsCharging bool    `json:"is_charging"`
+}
+
+// httpPostTraccarJSON posts every position as json, positions that cannot be delivered go through the queue like osmand
+func httpPostTraccarJSON() {
+	subscriber := gnssSubscribe("traccar osmandjson", traccarInterval())
+
+	for {
+		GNSSDataTraccar := <-subscriber.C
+
+		if traccarQueuePending() {
+			traccarQueueAdd(GNSSDataTraccar)
+			traccarQueueFlush(traccarJSONSend)
+			continue
+		}
+
+		switch err := traccarJSONSend(GNSSDataTraccar); err {
+		case nil:
+			log.Println("debug: osmandjson Protocol Traccar Server Accepted Position")
+			traccarDisplayStatus("TRACK OK", GNSSDataTraccar)
+		case errTraccarRejected:
+			log.Println("error: osmandjson Protocol Traccar Server Rejected Position")
+			traccarDisplayStatus("TRACK ERR2", GNSSDataTraccar)
+		default:
+			log.Println("error: Failed Communication with Traccar Server with error ", err)
+			traccarQueueAdd(GNSSDataTraccar)
+			traccarDisplayStatus("TRACK ERR1", GNSSDataTraccar)
+		}
+	}
+}
+
+// traccarJSONSend posts one position, the timestamp is the time of the fix so a queued position keeps its own time
+func traccarJSONSend(fix GNSSDataStruct) error {
+	var report traccarJSONStruct
+	report.DeviceID = Config.Global.Hardware.Traccar.ClientId
+	report.Location.Timestamp = fix.DateTime.UTC().Format(time.RFC3339)
+	report.Location.Coords.Latitude = fix.Lattitude
+	report.Location.Coords.Longitude = fix.Longitude
+	report.Location.Coords.Speed = fix.Speed / knotsPerMetreSec
+	report.Location.Coords.Heading = fix.Course
+	report.Location.Coords.Altitude = fix.Altitude
+
+	settings := Config.Global.Hardware.Traccar.Protocol.OsmandJSON
+	if level, err := readSysfsValue(settings.BatteryFile); err == nil {
+		report.Location.Battery = &traccarJSONBatteryStruct{}
+		if percent, err := strconv.ParseFloat(level, 64); err == nil {
+			report.Location.Battery.Level = percent / 100
+		}
+		if status, err := readSysfsValue(settings.ChargingFile); err == nil {
+			report.Location.Battery.IsCharging = strings.EqualFold(status, "charging") || status == "1"
+		}
+	}
+
+	report.Location.Extras = map[string]interface{}{"hdop": fix.HDOP, "sats": fix.SatsInUse}
+	for _, attribute := range settings.Attribute {
+		if attribute.Enabled {
+			report.Location.Extras[attribute.Name] = attribute.Value
+		}
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	response, err := client.Post(fmt.Sprint(settings.ServerURL)+":"+fmt.Sprint(settings.Port)+"/", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+
+	if response.StatusCode >= 200 && response.StatusCode <= 299 {
+		return nil
+	}
+	if response.StatusCode >= 400 && response.StatusCode <= 499 {
+		return errTraccarRejected
+	}
+	return fmt.Errorf("traccar server responded %v", response.Status)
+}
+
+// readSysfsValue reads a one line value such as a battery capacity from sysfs
+func readSysfsValue(path string) (string, error) {
+	if len(path) == 0 {
+		return "", errors.New("file not configured")
+	}
+	value, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(value)), nil
+}
+
+// traccarDisplayStatus shows the tracking result with the position on the lcd and oled the same way for every protocol
+func traccarDisplayStatus(status string, fix GNSSDataStruct) {
+	tnow := time.Now().Format("15:04:05")
+	if Config.Global.Hardware.LCD.Enabled && Config.Global.Hardware.Traccar.DeviceScreenEnabled {
+		LcdText = [4]string{"nil", status + " " + tnow, "lat:" + fmt.Sprintf("%f", fix.Lattitude) + " c:" + fmt.Sprintf("%f", fix.Course), "lon:" + fmt.Sprintf("%f", fix.Longitude) + " s:" + fmt.Sprintf("%.2f", fix.Speed*1.852)}
+		LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
+	}
+	if Config.Global.Hardware.OLED.Enabled {
+		oledDisplay(false, 4, 1, status+" "+fix.DateTime.Format("15:04:05"))
+		oledDisplay(false, 5, 1, "lat: "+fmt.Sprintf("%f", fix.Lattitude))
+		oledDisplay(false, 6, 1, "lon: "+fmt.Sprintf("%f", fix.Longitude))
+		oledDisplay(false, 7, 1, "s:"+fmt.Sprintf("%.2f", (fix.Speed*1.852))+" c:"+fmt.Sprintf("%f", fix.Course))
+	}
+}
@@ -0,0 +1,193 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * traccarqueue.go talkkonnects function to keep positions on disk while the traccar server cannot be reached and send them in order later
+ */
+
+package talkkonnect
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	traccarQueue      []GNSSDataStruct
+	traccarQueueMutex sync.Mutex
+	traccarQueueOnce  sync.Once
+
+	// errTraccarRejected is a position the server answered with a 4xx, sending it again will not help
+	errTraccarRejected = errors.New("position rejected by traccar server")
+)
+
+func traccarQueueEnabled() bool {
+	return Config.Global.Hardware.Traccar.Queue.Enabled
+}
+
+// traccarQueueLoad reads back the positions left unsent by the last run
+func traccarQueueLoad() {
+	file, err := os.Open(Config.Global.Hardware.Traccar.Queue.File)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Println("error: Traccar Queue Cannot Open ", err)
+		}
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var fix GNSSDataStruct
+		if err := json.Unmarshal(scanner.Bytes(), &fix); err != nil {
+			log.Println("warn: Traccar Queue Skipping Bad Entry ", err)
+			continue
+		}
+		traccarQueue = append(traccarQueue, fix)
+	}
+	traccarQueueTrim()
+	log.Printf("info: Traccar Queue Loaded %v Unsent Positions\n", len(traccarQueue))
+}
+
+// traccarQueuePending reports whether there are positions waiting, a new position must then queue behind them to keep the order
+func traccarQueuePending() bool {
+	if !traccarQueueEnabled() {
+		return false
+	}
+	traccarQueueMutex.Lock()
+	defer traccarQueueMutex.Unlock()
+	traccarQueueOnce.Do(traccarQueueLoad)
+	return len(traccarQueue) > 0
+}
+
+// traccarQueueAdd keeps a position that could not be sent, dropping the oldest beyond the size and age caps
+func traccarQueueAdd(fix GNSSDataStruct) {
+	if !traccarQueueEnabled() {
+		return
+	}
+	traccarQueueMutex.Lock()
+	defer traccarQueueMutex.Unlock()
+	traccarQueueOnce.Do(traccarQueueLoad)
+
+	traccarQueue = append(traccarQueue, fix)
+	if traccarQueueTrim() {
+		traccarQueueSave()
+		return
+	}
+
+	file, err := os.OpenFile(Config.Global.Hardware.Traccar.Queue.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Println("error: Traccar Queue Cannot Write ", err)
+		return
+	}
+	defer file.Close()
+	if err := json.NewEncoder(file).Encode(fix); err != nil {
+		log.Println("error: Traccar Queue Cannot Write ", err)
+	}
+	log.Printf("debug: Traccar Queued Position %v Waiting\n", len(traccarQueue))
+}
+
+// traccarQueueFlush sends the waiting positions oldest first and stops at the first one that fails, a rejected position is dropped
+func traccarQueueFlush(send func(GNSSDataStruct) error) {
+	traccarQueueMutex.Lock()
+	defer traccarQueueMutex.Unlock()
+	traccarQueueOnce.Do(traccarQueueLoad)
+
+	trimmed := traccarQueueTrim()
+	var sent int
+	for len(traccarQueue) > 0 {
+		err := send(traccarQueue[0])
+		if err == errTraccarRejected {
+			log.Printf("warn: Traccar Queue Dropping Position From %v Rejected By Server\n", traccarQueue[0].DateTime.Format(time.RFC3339))
+		} else if err != nil {
+			log.Println("error: Traccar Queue Flush Stopped ", err)
+			break
+		}
+		traccarQueue = traccarQueue[1:]
+		sent++
+	}
+	if sent > 0 {
+		log.Printf("info: Traccar Queue Sent %v Positions %v Still Waiting\n", sent, len(traccarQueue))
+	}
+	if trimmed || sent > 0 {
+		traccarQueueSave()
+	}
+}
+
+// traccarQueueTrim drops positions older than the max age and the oldest beyond max entries, the caller must hold traccarQueueMutex
+func traccarQueueTrim() bool {
+	var dropped int
+	if maxAge := Config.Global.Hardware.Traccar.Queue.MaxAgeSecs; maxAge > 0 {
+		for len(traccarQueue) > 0 && time.Since(traccarQueue[0].DateTime) > time.Duration(maxAge)*time.Second {
+			traccarQueue = traccarQueue[1:]
+			dropped++
+		}
+	}
+	if maxEntries := Config.Global.Hardware.Traccar.Queue.MaxEntries; maxEntries > 0 && len(traccarQueue) > maxEntries {
+		dropped += len(traccarQueue) - maxEntries
+		traccarQueue = traccarQueue[len(traccarQueue)-maxEntries:]
+	}
+	if dropped > 0 {
+		log.Printf("warn: Traccar Queue Dropped %v Old Positions\n", dropped)
+	}
+	return dropped > 0
+}
+
+// traccarQueueSave rewrites the queue file with what is still waiting, the caller must hold traccarQueueMutex
+func traccarQueueSave() {
+	path := Config.Global.Hardware.Traccar.Queue.File
+	if len(traccarQueue) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Println("error: Traccar Queue Cannot Remove ", err)
+		}
+		return
+	}
+
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		log.Println("error: Traccar Queue Cannot Write ", err)
+		return
+	}
+	encoder := json.NewEncoder(file)
+	for _, fix := range traccarQueue {
+		if err := encoder.Encode(fix); err != nil {
+			log.Println("error: Traccar Queue Cannot Write ", err)
+			file.Close()
+			return
+		}
+	}
+	if err := file.Close(); err != nil {
+		log.Println("error: Traccar Queue Cannot Write ", err)
+		return
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		log.Println("error: Traccar Queue Cannot Write ", err)
+	}
+}
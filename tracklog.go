@@ -0,0 +1,254 @@
+/*
+ * talkkonnect headless mumble client/gateway with lcd screen and channel control
+ * Copyright (C) 2018-2019, Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ *
+ * Software distributed under the License is distributed on an "AS IS" basis,
+ * WITHOUT WARRANTY OF ANY KIND, either express or implied. See the License
+ * for the specific language governing rights and limitations under the
+ * License.
+ *
+ * talkkonnect is the based on talkiepi and barnard by Daniel Chote and Tim Cooper
+ *
+ * The Initial Developer of the Original Code is
+ * Suvir Kumar <suvir@talkkonnect.com>
+ * Portions created by the Initial Developer are Copyright (C) Suvir Kumar. All Rights Reserved.
+ *
+ * Contributor(s):
+ *
+ * Suvir Kumar <suvir@talkkonnect.com>
+ *
+ * My Blog is at www.talkkonnect.com
+ * The source code is hosted at github.com/talkkonnect
+ *
+ * tracklog.go talkkonnects function to log the track to daily gpx or csv files and download them over http for a date range
+ */
+
+package talkkonnect
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTrackLogIntervalSecs = 10
+	trackLogDateFormat          = "2006-01-02"
+	trackLogMaxDays             = 366
+	trackLogGPXHeader           = "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<gpx version=\"1.0\" creator=\"talkkonnect\" xmlns=\"http://www.topografix.com/GPX/1/0\">\n <trk>\n  <name>%v</name>\n  <trkseg>\n"
+	trackLogGPXTrailer          = "  </trkseg>\n </trk>\n</gpx>\n"
+	trackLogCSVHeader           = "time,lat,lon,altitude_m,speed_kmh,course,fix_quality,sats\n"
+)
+
+func trackLogFormat() string {
+	if Config.Global.Hardware.TrackLog.Format == "csv" {
+		return "csv"
+	}
+	return "gpx"
+}
+
+// trackLogFile is the file for a day, days follow utc like the times in the files
+func trackLogFile(day time.Time) string {
+	return filepath.Join(Config.Global.Hardware.TrackLog.Directory, "track-"+day.UTC().Format(trackLogDateFormat)+"."+trackLogFormat())
+}
+
+// trackLogger writes a point every interval to the file of the day, a new file starts at midnight utc and files older
+// than the keep days are removed
+func trackLogger() {
+	interval := time.Duration(Config.Global.Hardware.TrackLog.IntervalSecs) * time.Second
+	if interval <= 0 {
+		interval = defaultTrackLogIntervalSecs * time.Second
+	}
+	subscriber := gnssSubscribe("tracklog", interval)
+	log.Printf("info: Track Logging %v To %v Every %v\n", strings.ToUpper(trackLogFormat()), Config.Global.Hardware.TrackLog.Directory, interval)
+
+	var (
+		file     *os.File
+		fileName string
+	)
+	for fix := range subscriber.C {
+		if name := trackLogFile(fix.DateTime); name != fileName {
+			if file != nil {
+				file.Close()
+			}
+			var err error
+			if file, err = trackLogOpen(name, fix.DateTime); err != nil {
+				log.Println("error: Track Log Cannot Open ", err)
+				fileName = ""
+				continue
+			}
+			fileName = name
+			trackLogPrune()
+		}
+		if err := trackLogWrite(file, fix); err != nil {
+			log.Println("error: Track Log Cannot Write ", err)
+		}
+	}
+}
+
+// trackLogOpen opens the file of the day and starts it with the gpx header and trailer or the csv header when it is new
+func trackLogOpen(name string, day time.Time) (*os.File, error) {
+	file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.Size() == 0 {
+		header := trackLogCSVHeader
+		if trackLogFormat() == "gpx" {
+			header = fmt.Sprintf(trackLogGPXHeader, day.UTC().Format(trackLogDateFormat)) + trackLogGPXTrailer
+		}
+		if _, err := file.WriteString(header); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	log.Println("info: Track Log File ", name)
+	return file, nil
+}
+
+// trackLogWrite appends a csv row, or writes a gpx point over the trailer and puts the trailer back so the file is
+// always a complete gpx file
+func trackLogWrite(file *os.File, fix GNSSDataStruct) error {
+	if trackLogFormat() == "csv" {
+		if _, err := file.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(file, "%v,%.6f,%.6f,%.1f,%.1f,%.0f,%v,%v\n", fix.DateTime.UTC().Format(time.RFC3339), fix.Lattitude, fix.Longitude, fix.Altitude, fix.Speed*1.852, fix.Course, fix.FixQuality, fix.SatsInUse)
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	offset := info.Size()
+	tail := make([]byte, len(trackLogGPXTrailer))
+	if offset >= int64(len(tail)) {
+		if _, err := file.ReadAt(tail, offset-int64(len(tail))); err == nil && string(tail) == trackLogGPXTrailer {
+			offset -= int64(len(tail))
+		}
+	}
+	point := fmt.Sprintf("   <trkpt lat=\"%.6f\" lon=\"%.6f\"><ele>%.1f</ele><time>%v</time><course>%.1f</course><speed>%.2f</speed><fix>%v</fix><sat>%v</sat><hdop>%.1f</hdop></trkpt>\n",
+		fix.Lattitude, fix.Longitude, fix.Altitude, fix.DateTime.UTC().Format(time.RFC3339), fix.Course, fix.Speed/knotsPerMetreSec, trackLogGPXFix(fix.FixQuality), fix.SatsInUse, fix.HDOP)
+	_, err = file.WriteAt([]byte(point+trackLogGPXTrailer), offset)
+	return err
+}
+
+// trackLogGPXFix maps the GGA fix quality to the gpx fix type
+func trackLogGPXFix(quality string) string {
+	switch quality {
+	case "0":
+		return "none"
+	case "2":
+		return "dgps"
+	case "3":
+		return "pps"
+	}
+	return "3d"
+}
+
+// trackLogPrune removes the files of days before the keep days, zero keeps every file
+func trackLogPrune() {
+	keepDays := Config.Global.Hardware.TrackLog.KeepDays
+	if keepDays <= 0 {
+		return
+	}
+	oldest := time.Now().UTC().AddDate(0, 0, -keepDays).Format(trackLogDateFormat)
+	files, err := filepath.Glob(filepath.Join(Config.Global.Hardware.TrackLog.Directory, "track-*.*"))
+	if err != nil {
+		return
+	}
+	for _, name := range files {
+		day := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(name), "track-"), filepath.Ext(name))
+		if len(day) == len(trackLogDateFormat) && day < oldest {
+			if err := os.Remove(name); err != nil {
+				log.Println("error: Track Log Cannot Remove ", err)
+				continue
+			}
+			log.Println("info: Track Log Removed ", name)
+		}
+	}
+}
+
+// httpTrackDownload sends the track of the days from and to as one file, /track?from=2006-01-02&to=2006-01-02, both default to today
+func httpTrackDownload(w http.ResponseWriter, r *http.Request) {
+	today := time.Now().UTC().Format(trackLogDateFormat)
+	from, err := time.Parse(trackLogDateFormat, trackLogQuery(r, "from", today))
+	if err != nil {
+		http.Error(w, "400 error: from must be yyyy-mm-dd", http.StatusBadRequest)
+		return
+	}
+	to, err := time.Parse(trackLogDateFormat, trackLogQuery(r, "to", from.Format(trackLogDateFormat)))
+	if err != nil || to.Before(from) || to.Sub(from) > trackLogMaxDays*24*time.Hour {
+		http.Error(w, fmt.Sprintf("400 error: to must be yyyy-mm-dd, not before from and within %v days", trackLogMaxDays), http.StatusBadRequest)
+		return
+	}
+
+	var body bytes.Buffer
+	if trackLogFormat() == "csv" {
+		body.WriteString(trackLogCSVHeader)
+	} else {
+		body.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<gpx version=\"1.0\" creator=\"talkkonnect\" xmlns=\"http://www.topografix.com/GPX/1/0\">\n")
+	}
+
+	var days int
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		contents, err := ioutil.ReadFile(trackLogFile(day))
+		if err != nil {
+			continue
+		}
+		days++
+		text := string(contents)
+		if trackLogFormat() == "csv" {
+			body.WriteString(strings.TrimPrefix(text, trackLogCSVHeader))
+			continue
+		}
+		// each day goes in as its own trk
+		if start := strings.Index(text, " <trk>"); start >= 0 {
+			body.WriteString(strings.TrimSuffix(strings.TrimSuffix(text[start:], "\n"), "</gpx>"))
+			if !strings.HasSuffix(text, trackLogGPXTrailer) {
+				body.WriteString("  </trkseg>\n </trk>\n")
+			}
+		}
+	}
+	if trackLogFormat() == "gpx" {
+		body.WriteString("</gpx>\n")
+	}
+
+	if days == 0 {
+		http.Error(w, "404 error: no track logged for "+from.Format(trackLogDateFormat)+" to "+to.Format(trackLogDateFormat), http.StatusNotFound)
+		return
+	}
+
+	contentType := "application/gpx+xml"
+	if trackLogFormat() == "csv" {
+		contentType = "text/csv"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"track-%v-%v.%v\"", from.Format(trackLogDateFormat), to.Format(trackLogDateFormat), trackLogFormat()))
+	w.Write(body.Bytes())
+	log.Printf("info: Track Download %v To %v %v Days\n", from.Format(trackLogDateFormat), to.Format(trackLogDateFormat), days)
+}
+
+func trackLogQuery(r *http.Request, name string, fallback string) string {
+	if value := r.URL.Query().Get(name); len(value) > 0 {
+		return value
+	}
+	return fallback
+}
@@ -33,7 +33,6 @@ package talkkonnect
 import (
 	"log"
 	"strconv"
-	"strings"
 
 	evdev "github.com/gvalkov/golang-evdev"
 )
@@ -67,21 +66,8 @@ func (b *Talkkonnect) USBKeyboard() {
 				// Functions that we allow Repeating Keys Defined Here
 				if ke.State == evdev.KeyHold {
 					keyPrevStateDown = false
-					if _, ok := USBKeyMap[rune(ke.Scancode)]; ok {
-						switch strings.ToLower(USBKeyMap[rune(ke.Scancode)].Command) {
-						case "channelup":
-							playIOMedia("usbchannelup")
-							b.cmdChannelUp()
-						case "channeldown":
-							playIOMedia("usbchanneldown")
-							b.cmdChannelDown()
-						case "volumeup":
-							playIOMedia("usbvolup")
-							b.cmdVolumeUp()
-						case "volumedown":
-							playIOMedia("usbvoldown")
-							b.cmdVolumeDown()
-						}
+					if key, ok := USBKeyMap[rune(ke.Scancode)]; ok {
+						b.runInputCommand("usb", strconv.Itoa(int(ke.Scancode)), inputCommandStruct{key.Command, key.ParamName, key.ParamValue}, true)
 					} else {
 						if ke.Scancode != uint16(Config.Global.Hardware.USBKeyboard.NumlockScanID) {
 							log.Println("error: Key Not Mapped ASC ", ke.Scancode)
@@ -93,74 +79,8 @@ func (b *Talkkonnect) USBKeyboard() {
 				//Key Up & Down One Shot
 				if keyPrevStateDown && ke.State == evdev.KeyUp {
 					keyPrevStateDown = false
-					if _, ok := USBKeyMap[rune(ke.Scancode)]; ok {
-						switch strings.ToLower(USBKeyMap[rune(ke.Scancode)].Command) {
-						case "channelup":
-							playIOMedia("usbchannelup")
-							b.cmdChannelUp()
-						case "channeldown":
-							playIOMedia("usbchanneldown")
-							b.cmdChannelDown()
-						case "serverup":
-							playIOMedia("usbserverup")
-							b.cmdConnNextServer()
-						case "serverdown":
-							playIOMedia("usbpreviousserver")
-							b.cmdConnPreviousServer()
-						case "mute":
-							playIOMedia("usbmute")
-							b.cmdMuteUnmute("mute")
-						case "unmute":
-							b.cmdMuteUnmute("unmute")
-							playIOMedia("usbunmute")
-						case "mute-toggle":
-							playIOMedia("usbmutetoggle")
-							b.cmdMuteUnmute("toggle")
-							playIOMedia("usbmutetoggle")
-						case "stream-toggle":
-							playIOMedia("usbstreamtoggle")
-							b.cmdPlayback()
-						case "volumeup":
-							playIOMedia("usbvolup")
-							b.cmdVolumeUp()
-						case "volumedown":
-							playIOMedia("usbvoldown")
-							b.cmdVolumeDown()
-						case "setcomment":
-							if USBKeyMap[rune(ke.Scancode)].ParamName == "setcomment" {
-								log.Println("info: Set Commment ", USBKeyMap[rune(ke.Scancode)].ParamValue)
-								playIOMedia("usbsetcomment")
-								b.Client.Self.SetComment(USBKeyMap[rune(ke.Scancode)].ParamValue)
-							}
-						case "transmitstart":
-							playIOMedia("usbstarttx")
-							b.cmdStartTransmitting()
-						case "transmitstop":
-							playIOMedia("usbstoptx")
-							b.cmdStopTransmitting()
-						case "record":
-							playIOMedia("usbrecord")
-							b.cmdAudioTrafficRecord()
-							b.cmdAudioMicRecord()
-						case "voicetargetset":
-							voicetarget, err := strconv.Atoi(USBKeyMap[rune(ke.Scancode)].ParamValue)
-							if err != nil {
-								log.Println("error: Target is Non-Numeric Value")
-							} else {
-								playIOMedia("usbvoicetarget")
-								b.cmdSendVoiceTargets(uint32(voicetarget))
-							}
-						case "mqttpubpayloadset":
-							if USBKeyMap[rune(ke.Scancode)].ParamName == "payloadvalue" {
-								playIOMedia("usbmqttpubpayloadset")
-								MQTTPublish(USBKeyMap[rune(ke.Scancode)].ParamValue)
-							}
-						case "repeatertoneplay":
-							playIOMedia("iorepeatertone")
-							b.cmdPlayRepeaterTone()
-						default:
-							log.Println("error: Command Not Defined ", strings.ToLower(USBKeyMap[rune(ke.Scancode)].Command))
-						}
+					if key, ok := USBKeyMap[rune(ke.Scancode)]; ok {
+						b.runInputCommand("usb", strconv.Itoa(int(ke.Scancode)), inputCommandStruct{key.Command, key.ParamName, key.ParamValue}, false)
 					} else {
 						if ke.Scancode != uint16(Config.Global.Hardware.USBKeyboard.NumlockScanID) {
 							log.Println("error: Key Not Mapped ASC ", ke.Scancode)
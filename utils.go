@@ -55,7 +55,6 @@ import (
 	"github.com/kennygrant/sanitize"
 	"github.com/talkkonnect/gumble/gumble"
 	term "github.com/talkkonnect/termbox-go"
-	"github.com/xackery/gomail"
 )
 
 func reset() {
@@ -154,26 +153,6 @@ func (b *Talkkonnect) pingconnectedserver() {
 	log.Println("info: Server Maximum Bitrate: ", resp.MaximumBitrate)
 }
 
-func sendviagmail(username string, password string, receiver string, subject string, message string) error {
-
-	err := gomail.Send(username, password, []string{receiver}, subject, message)
-	if err != nil {
-		return fmt.Errorf("sending Email Via GMAIL Error")
-	}
-
-	if Config.Global.Hardware.TargetBoard == "rpi" {
-		if LCDEnabled {
-			LcdText = [4]string{"nil", "nil", "nil", "Sending Email"}
-			go LcdDisplay(LcdText, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
-		}
-		if OLEDEnabled {
-			oledDisplay(false, 6, 1, "Sending Email")
-		}
-	}
-
-	return nil
-}
-
 func zipit(source, target string) error {
 	zipfile, err := os.Create(target)
 	if err != nil {
@@ -313,6 +292,22 @@ func before(value string, a string) string { // used for sox time
 	return value[0:pos]
 }
 
+// localIPv4 returns the first ipv4 address that is not a loopback address, empty when there is no network
+func localIPv4() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		log.Print(fmt.Sprintf("error: localIPv4 %v", err.Error()))
+		return ""
+	}
+
+	for _, a := range addrs {
+		if ipnet, ok := a.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+			return ipnet.IP.String()
+		}
+	}
+	return ""
+}
+
 func getMacAddr() ([]string, error) {
 	ifas, err := net.Interfaces()
 	if err != nil {
@@ -463,7 +458,7 @@ func checkGitHubVersion() string {
 }
 
 func checkSBCVersion() string {
-	if Config.Global.Hardware.TargetBoard != "rpi" {
+	if !targetBoardHasGPIO() {
 		return "unknown"
 	}
 
@@ -486,6 +481,7 @@ func findMQTTButton(findMQTTButton string) mqttPubButtonStruct {
 }
 
 func txScreen() {
+	displayWidgetSet("status", "Online/TX")
 	if LCDEnabled {
 		LcdText[0] = "Online/TX"
 		LcdText[3] = "TX at " + time.Now().Format("15:04:05")
@@ -504,13 +500,14 @@ func txScreen() {
 }
 
 func rxScreen(LastSpeaker string) {
-	if LCDEnabled && Config.Global.Hardware.TargetBoard == "rpi" {
+	displayWidgetSet("lastspeaker", LastSpeaker+" "+time.Now().Format("15:04:05"))
+	if LCDEnabled && targetBoardHasGPIO() {
 		GPIOOutPin("backlight", "on")
 		lcdtext = [4]string{"nil", "", "", LastSpeaker + " " + time.Now().Format("15:04:05")}
 		LcdDisplay(lcdtext, LCDRSPin, LCDEPin, LCDD4Pin, LCDD5Pin, LCDD6Pin, LCDD7Pin, LCDInterfaceType, LCDI2CAddress)
 		BackLightTime.Reset(time.Duration(LCDBackLightTimeout) * time.Second)
 	}
-	if OLEDEnabled && Config.Global.Hardware.TargetBoard == "rpi" {
+	if OLEDEnabled && targetBoardHasGPIO() {
 		Oled.DisplayOn()
 		oledDisplay(false, 3, 1, LastSpeaker+" "+time.Now().Format("15:04:05"))
 		oledDisplay(false, 4, 1, "")
@@ -38,6 +38,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -46,7 +47,6 @@ import (
 	goled "github.com/talkkonnect/go-oled-i2c"
 	"github.com/talkkonnect/gumble/gumble"
 	"github.com/talkkonnect/gumble/gumbleffmpeg"
-	"github.com/talkkonnect/sa818"
 	"golang.org/x/sys/unix"
 )
 
@@ -71,6 +71,7 @@ type ConfigStruct struct {
 			Voicetargets struct {
 				ID []struct {
 					Value     uint32 `xml:"value,attr"`
+					Name      string `xml:"name,attr"`
 					IsCurrent bool   `xml:"iscurrent"`
 					Users     struct {
 						User []string `xml:"user"`
@@ -136,15 +137,21 @@ type ConfigStruct struct {
 				} `xml:"sound"`
 			} `xml:"tts"`
 			SMTP struct {
-				Enabled       bool   `xml:"enabled,attr"`
-				Username      string `xml:"username"`
-				Password      string `xml:"password"`
-				Receiver      string `xml:"receiver"`
-				Subject       string `xml:"subject"`
-				Message       string `xml:"message"`
-				GpsDateTime   bool   `xml:"gpsdatetime"`
-				GpsLatLong    bool   `xml:"gpslatlong"`
-				GoogleMapsURL bool   `xml:"googlemapsurl"`
+				Enabled       bool     `xml:"enabled,attr"`
+				Host          string   `xml:"host"`
+				Port          int      `xml:"port"`
+				Security      string   `xml:"security"`
+				SkipVerify    bool     `xml:"skipverify"`
+				Auth          string   `xml:"auth"`
+				Username      string   `xml:"username"`
+				Password      string   `xml:"password"`
+				From          string   `xml:"from"`
+				Receiver      []string `xml:"receiver"`
+				Subject       string   `xml:"subject"`
+				Message       string   `xml:"message"`
+				GpsDateTime   bool     `xml:"gpsdatetime"`
+				GpsLatLong    bool     `xml:"gpslatlong"`
+				GoogleMapsURL bool     `xml:"googlemapsurl"`
 			} `xml:"smtp"`
 			Sounds struct {
 				Sound []struct {
@@ -220,6 +227,20 @@ type ConfigStruct struct {
 						} `xml:"command"`
 					} `xml:"commands"`
 				} `xml:"mqtt"`
+				Mumble struct {
+					Enabled   bool   `xml:"enabled,attr"`
+					Prefix    string `xml:"prefix,attr"`
+					Authorise struct {
+						User     []string `xml:"user"`
+						CertHash []string `xml:"certhash"`
+						Group    []string `xml:"group"`
+					} `xml:"authorise"`
+					Command []struct {
+						Action  string `xml:"action,attr"`
+						Message string `xml:"message,attr"`
+						Enabled bool   `xml:"enabled,attr"`
+					} `xml:"command"`
+				} `xml:"mumble"`
 			}
 			PrintVariables struct {
 				PrintAccount          bool `xml:"printaccount"`
@@ -248,6 +269,7 @@ type ConfigStruct struct {
 				PrintGPS              bool `xml:"printgps"`
 				PrintTraccar          bool `xml:"printtraccar"`
 				PrintPanic            bool `xml:"printpanic"`
+				PrintLoneWorker       bool `xml:"printloneworker"`
 				PrintUSBKeyboard      bool `xml:"printusbkeyboard"`
 				PrintAudioRecord      bool `xml:"printaudiorecord"`
 				PrintKeyboardMap      bool `xml:"printkeyboardmap"`
@@ -282,16 +304,58 @@ type ConfigStruct struct {
 					Enabled bool          `xml:"enabled,attr"`
 				} `xml:"postdelay"`
 			} `xml:"ttsmessages"`
+			MessageStore struct {
+				Size      int    `xml:"size,attr"`
+				UnreadLED string `xml:"unreadled,attr"`
+			} `xml:"messagestore"`
+			MessageForward struct {
+				Enabled   bool `xml:"enabled,attr"`
+				Forwarder []struct {
+					Name        string `xml:"name,attr"`
+					Type        string `xml:"type,attr"`
+					Enabled     bool   `xml:"enabled,attr"`
+					SenderRegex string `xml:"senderregex"`
+					Channel     string `xml:"channel"`
+					Scope       string `xml:"scope"`
+					Topic       string `xml:"topic"`
+					URL         string `xml:"url"`
+					Subject     string `xml:"subject"`
+					Template    string `xml:"template"`
+				} `xml:"forwarder"`
+			} `xml:"messageforward"`
 			IgnoreUser struct {
 				IgnoreUserEnabled bool   `xml:"enabled,attr"`
 				IgnoreUserRegex   string `xml:"ignoreuserregex"`
 			} `xml:"ignoreuser"`
 		} `xml:"software"`
 		Hardware struct {
-			TargetBoard             string        `xml:"targetboard,attr"`
-			LedStripEnabled         bool          `xml:"ledstripenabled"`
+			TargetBoard     string `xml:"targetboard,attr"`
+			LedStripEnabled bool   `xml:"ledstripenabled"`
+			LedStrip        struct {
+				SPIPort     string `xml:"spiport,attr"`
+				Pixels      int    `xml:"pixels,attr"`
+				Brightness  uint8  `xml:"brightness,attr"`
+				Temperature uint16 `xml:"temperature,attr"`
+				State       []struct {
+					Name     string `xml:"name,attr"`
+					Pixels   string `xml:"pixels,attr"`
+					Colour   string `xml:"colour,attr"`
+					Pattern  string `xml:"pattern,attr"`
+					Priority int    `xml:"priority,attr"`
+					Enabled  bool   `xml:"enabled,attr"`
+				} `xml:"state"`
+				Channel []struct {
+					Name   string `xml:"name,attr"`
+					Colour string `xml:"colour,attr"`
+				} `xml:"channel"`
+			} `xml:"ledstrip"`
 			VoiceActivityTimermsecs time.Duration `xml:"voiceactivitytimermsecs"`
 			IO                      struct {
+				GPIOBackend struct {
+					Name          string `xml:"name,attr"`
+					Chip          string `xml:"chip,attr"`
+					DebounceMsecs int    `xml:"debouncemsecs,attr"`
+				} `xml:"gpiobackend"`
 				GPIOExpander struct {
 					Enabled bool `xml:"enabled,attr"`
 					Chip    []struct {
@@ -310,13 +374,18 @@ type ConfigStruct struct {
 				} `xml:"max7219"`
 				Pins struct {
 					Pin []struct {
-						Direction string `xml:"direction,attr"`
-						Device    string `xml:"device,attr"`
-						Name      string `xml:"name,attr"`
-						PinNo     uint   `xml:"pinno,attr"`
-						Type      string `xml:"type,attr"`
-						ID        int    `xml:"chipid,attr"`
-						Enabled   bool   `xml:"enabled,attr"`
+						Direction     string               `xml:"direction,attr"`
+						Device        string               `xml:"device,attr"`
+						Name          string               `xml:"name,attr"`
+						PinNo         uint                 `xml:"pinno,attr"`
+						Type          string               `xml:"type,attr"`
+						ID            int                  `xml:"chipid,attr"`
+						Action        string               `xml:"action,attr"`
+						ParamName     string               `xml:"paramname,attr"`
+						ParamValue    string               `xml:"paramvalue,attr"`
+						DebounceMsecs int                  `xml:"debouncemsecs,attr"`
+						Enabled       bool                 `xml:"enabled,attr"`
+						Gesture       []inputGestureStruct `xml:"gesture"`
 					} `xml:"pin"`
 				} `xml:"pins"`
 				RotaryEncoder struct {
@@ -325,6 +394,10 @@ type ConfigStruct struct {
 						Function string `xml:"function,attr"`
 						Enabled  bool   `xml:"enabled,attr"`
 					} `xml:"control"`
+					Menu struct {
+						Rows         int  `xml:"rows,attr"`
+						VoicePrompts bool `xml:"voiceprompts,attr"`
+					} `xml:"menu"`
 				} `xml:"rotaryencoder"`
 				Pulse struct {
 					Leading  time.Duration `xml:"leadingmsecs,attr"`
@@ -336,6 +409,26 @@ type ConfigStruct struct {
 					VolDownStep int `xml:"voldownstep"`
 				} `xml:"volumebuttonstep"`
 			} `xml:"io"`
+			LEDPatterns struct {
+				Enabled bool `xml:"enabled,attr"`
+				Pattern []struct {
+					Name        string `xml:"name,attr"`
+					Type        string `xml:"type,attr"`
+					Sequence    string `xml:"sequence,attr"`
+					Morse       string `xml:"morse,attr"`
+					UnitMsecs   int    `xml:"unitmsecs,attr"`
+					PeriodMsecs int    `xml:"periodmsecs,attr"`
+				} `xml:"pattern"`
+				State []struct {
+					Name     string `xml:"name,attr"`
+					Output   string `xml:"output,attr"`
+					StripLED string `xml:"stripled,attr"`
+					Colour   string `xml:"colour,attr"`
+					Pattern  string `xml:"pattern,attr"`
+					Priority int    `xml:"priority,attr"`
+					Enabled  bool   `xml:"enabled,attr"`
+				} `xml:"state"`
+			} `xml:"ledpatterns"`
 			HeartBeat struct {
 				Enabled     bool   `xml:"enabled,attr"`
 				LEDPin      string `xml:"heartbeatledpin"`
@@ -376,8 +469,29 @@ type ConfigStruct struct {
 				CharLength              int    `xml:"oledcharlength"`
 				StartColumn             int    `xml:"oledstartcolumn"`
 			} `xml:"oled"`
+			DisplayManager struct {
+				Enabled bool `xml:"enabled,attr"`
+				Device  []struct {
+					Name    string `xml:"name,attr"`
+					Type    string `xml:"type,attr"`
+					Rows    int    `xml:"rows,attr"`
+					Columns int    `xml:"columns,attr"`
+					Enabled bool   `xml:"enabled,attr"`
+				} `xml:"device"`
+				Page []struct {
+					Name   string `xml:"name,attr"`
+					Device string `xml:"device,attr"`
+					Widget []struct {
+						Name  string `xml:"name,attr"`
+						Row   int    `xml:"row,attr"`
+						Rows  int    `xml:"rows,attr"`
+						Label string `xml:"label,attr"`
+					} `xml:"widget"`
+				} `xml:"page"`
+			} `xml:"displaymanager"`
 			GPS struct {
 				Enabled             bool   `xml:"enabled,attr"`
+				Source              string `xml:"source,attr"`
 				Port                string `xml:"port"`
 				Baud                uint   `xml:"baud"`
 				TxData              string `xml:"txdata"`
@@ -394,6 +508,17 @@ type ConfigStruct struct {
 				GpsInfoVerbose      bool   `xml:"gpsinfoverbose"`
 				GpsDiagSounds       bool   `xml:"gpsdiagsounds"`
 				GpsDisplayShow      bool   `xml:"gpsdisplayshow"`
+				StaleSecs           int    `xml:"stalesecs"`
+				MQTTPublishSecs     int    `xml:"mqttpublishsecs"`
+				Gpsd                struct {
+					Host string `xml:"host"`
+					Port int    `xml:"port"`
+				} `xml:"gpsd"`
+				Replay struct {
+					File  string  `xml:"file"`
+					Speed float64 `xml:"speed"`
+					Loop  bool    `xml:"loop"`
+				} `xml:"replay"`
 			} `xml:"gps"`
 			Traccar struct {
 				Enabled             bool   `xml:"enabled,attr"`
@@ -402,7 +527,14 @@ type ConfigStruct struct {
 				DeviceScreenEnabled bool   `xml:"devicescreenenabled"`
 				TraccarDiagSounds   bool   `xml:"traccardiagsounds"`
 				TraccarDisplayShow  bool   `xml:"traccardispayshow"`
-				Protocol            struct {
+				IntervalSecs        int    `xml:"intervalsecs"`
+				Queue               struct {
+					Enabled    bool   `xml:"enabled,attr"`
+					File       string `xml:"file"`
+					MaxEntries int    `xml:"maxentries"`
+					MaxAgeSecs int    `xml:"maxagesecs"`
+				} `xml:"queue"`
+				Protocol struct {
 					Name   string `xml:"name,attr"`
 					Osmand struct {
 						Port      string `xml:"port,attr"`
@@ -416,8 +548,71 @@ type ConfigStruct struct {
 						Port      string `xml:"port,attr"`
 						ServerURL string `xml:"serverurl"`
 					} `xml:"opengts"`
+					OsmandJSON struct {
+						Port         string `xml:"port,attr"`
+						ServerURL    string `xml:"serverurl"`
+						BatteryFile  string `xml:"batteryfile"`
+						ChargingFile string `xml:"chargingfile"`
+						Attribute    []struct {
+							Name    string `xml:"name,attr"`
+							Value   string `xml:"value,attr"`
+							Enabled bool   `xml:"enabled,attr"`
+						} `xml:"attribute"`
+					} `xml:"osmandjson"`
+					Aprs struct {
+						Port           string `xml:"port,attr"`
+						Server         string `xml:"server"`
+						Callsign       string `xml:"callsign"`
+						SSID           int    `xml:"ssid"`
+						Passcode       string `xml:"passcode"`
+						SymbolTable    string `xml:"symboltable"`
+						Symbol         string `xml:"symbol"`
+						Comment        string `xml:"comment"`
+						SmartBeaconing struct {
+							Enabled      bool    `xml:"enabled,attr"`
+							FastSpeed    float64 `xml:"fastspeed"`
+							FastRateSecs int     `xml:"fastratesecs"`
+							SlowSpeed    float64 `xml:"slowspeed"`
+							SlowRateSecs int     `xml:"slowratesecs"`
+							MinTurnAngle float64 `xml:"minturnangle"`
+							TurnSlope    float64 `xml:"turnslope"`
+							MinTurnSecs  int     `xml:"minturnsecs"`
+						} `xml:"smartbeaconing"`
+					} `xml:"aprs"`
 				} `xml:"protocol"`
 			} `xml:"traccar"`
+			Geofence struct {
+				Enabled      bool `xml:"enabled,attr"`
+				ConfirmFixes int  `xml:"confirmfixes,attr"`
+				Fence        []struct {
+					Name    string `xml:"name,attr"`
+					Enabled bool   `xml:"enabled,attr"`
+					Circle  struct {
+						Lat    float64 `xml:"lat,attr"`
+						Lon    float64 `xml:"lon,attr"`
+						Radius float64 `xml:"radius,attr"`
+					} `xml:"circle"`
+					Polygon string `xml:"polygon"`
+					Action  []struct {
+						Event   string `xml:"event,attr"`
+						Type    string `xml:"type,attr"`
+						Value   string `xml:"value,attr"`
+						Enabled bool   `xml:"enabled,attr"`
+					} `xml:"action"`
+				} `xml:"fence"`
+			} `xml:"geofence"`
+			TrackLog struct {
+				Enabled      bool   `xml:"enabled,attr"`
+				Format       string `xml:"format,attr"`
+				Directory    string `xml:"directory"`
+				IntervalSecs int    `xml:"intervalsecs"`
+				KeepDays     int    `xml:"keepdays"`
+			} `xml:"tracklog"`
+			PositionShare struct {
+				Enabled      bool `xml:"enabled,attr"`
+				IntervalSecs int  `xml:"intervalsecs"`
+				Speak        bool `xml:"speak"`
+			} `xml:"positionshare"`
 			PanicFunction struct {
 				Enabled              bool    `xml:"enabled,attr"`
 				FilenameAndPath      string  `xml:"filenameandpath"`
@@ -432,7 +627,28 @@ type ConfigStruct struct {
 				TxLockEnabled        bool    `xml:"txlockenabled"`
 				TxLockTimeOutSecs    uint    `xml:"txlocktimeoutsecs"`
 				PLowProfile          bool    `xml:"lowprofile"`
+				ArmSecs              int     `xml:"armsecs"`
+				RepeatSecs           int     `xml:"repeatsecs"`
+				MaxRepeats           int     `xml:"maxrepeats"`
+				AckKeyword           string  `xml:"ackkeyword"`
+				CancelPIN            string  `xml:"cancelpin"`
+				Escalation           []struct {
+					AfterSecs   int    `xml:"aftersecs,attr"`
+					VoiceTarget uint32 `xml:"voicetarget,attr"`
+					Channel     string `xml:"channel,attr"`
+					Message     string `xml:"message,attr"`
+					Enabled     bool   `xml:"enabled,attr"`
+				} `xml:"escalation"`
 			} `xml:"panicfunction"`
+			LoneWorker struct {
+				Enabled      bool   `xml:"enabled,attr"`
+				IntervalSecs int    `xml:"intervalsecs"`
+				GraceSecs    int    `xml:"gracesecs"`
+				Prompt       string `xml:"prompt"`
+				OpenMicSecs  int    `xml:"openmicsecs"`
+				ReportMQTT   bool   `xml:"reportmqtt"`
+				ReportEmail  bool   `xml:"reportemail"`
+			} `xml:"loneworker"`
 			USBKeyboard struct {
 				Enabled         bool   `xml:"enabled,attr"`
 				USBKeyboardPath string `xml:"usbkeyboarddevpath"`
@@ -475,8 +691,30 @@ type ConfigStruct struct {
 			Radio struct {
 				XMLName          xml.Name `xml:"radio"`
 				Enabled          bool     `xml:"enabled,attr"`
+				Driver           string   `xml:"driver,attr"`
 				ConnectChannelID string   `xml:"connectchannelid"`
-				Sa818            struct {
+				Rigctld          struct {
+					Host string `xml:"host"`
+					Port int    `xml:"port"`
+				} `xml:"rigctld"`
+				Scan struct {
+					Enabled           bool     `xml:"enabled,attr"`
+					StartOnConnect    bool     `xml:"startonconnect,attr"`
+					Detect            string   `xml:"detect,attr"`
+					DwellMsecs        int      `xml:"dwellmsecs"`
+					HangSecs          int      `xml:"hangsecs"`
+					RSSIThreshold     int      `xml:"rssithreshold"`
+					PriorityChannelID string   `xml:"prioritychannelid"`
+					PriorityMsecs     int      `xml:"prioritymsecs"`
+					Lockout           []string `xml:"lockout"`
+				} `xml:"scan"`
+				Import struct {
+					Enabled bool    `xml:"enabled,attr"`
+					Write   bool    `xml:"write,attr"`
+					MinFreq float32 `xml:"minfreq"`
+					MaxFreq float32 `xml:"maxfreq"`
+				} `xml:"import"`
+				Sa818 struct {
 					Enabled   bool `xml:"enabled,attr"`
 					PDEnabled bool `xml:"enabled"`
 					Serial    struct {
@@ -555,6 +793,7 @@ type ConfigStruct struct {
 type VTStruct struct {
 	ID []struct {
 		Value     uint32
+		Name      string
 		IsCurrent bool
 		Users     struct {
 			User []string
@@ -578,6 +817,16 @@ type KBStruct struct {
 	ParamValue string
 }
 
+type inputGestureStruct struct {
+	Type        string `xml:"type,attr"`
+	Action      string `xml:"action,attr"`
+	ParamName   string `xml:"paramname,attr"`
+	ParamValue  string `xml:"paramvalue,attr"`
+	Msecs       int    `xml:"msecs,attr"`
+	RepeatMsecs int    `xml:"repeatmsecs,attr"`
+	Enabled     bool   `xml:"enabled,attr"`
+}
+
 type EventSoundStruct struct {
 	Enabled  bool
 	FileName string
@@ -623,6 +872,7 @@ type radioChannelsStruct struct {
 	Highpass   int
 	Lowpass    int
 	Volume     int
+	TXPower    string
 }
 
 type rotaryFunctionsStruct struct {
@@ -638,18 +888,17 @@ var RotaryFunctions []rotaryFunctionsStruct
 
 // Generic Global State Variables
 var (
-	KillHeartBeat           bool
-	IsPlayStream            bool
-	IsConnected             bool
-	Streaming               bool
-	HTTPServRunning         bool
-	NowStreaming            bool
-	InStreamTalking         bool
-	InStreamSource          bool
-	LCDIsDark               bool
-	GPSDataChannelReceivers int
-	TXLockOut               bool
-	VoiceTargets            []string
+	KillHeartBeat   bool
+	IsPlayStream    bool
+	IsConnected     bool
+	Streaming       bool
+	HTTPServRunning bool
+	NowStreaming    bool
+	InStreamTalking bool
+	InStreamSource  bool
+	LCDIsDark       bool
+	TXLockOut       bool
+	VoiceTargets    []string
 )
 
 // Generic Global Counter Variables
@@ -739,7 +988,6 @@ var (
 )
 
 var StreamTracker = map[uint32]streamTrackerStruct{}
-var DMOSetup sa818.DMOSetupStruct
 
 func readxmlconfig(file string, reloadxml bool) error {
 	var ReConfig ConfigStruct
@@ -877,7 +1125,7 @@ func readxmlconfig(file string, reloadxml bool) error {
 		OLEDCharLength = Config.Global.Hardware.OLED.CharLength
 		OLEDStartColumn = Config.Global.Hardware.OLED.StartColumn
 
-		if Config.Global.Hardware.TargetBoard != "rpi" {
+		if !targetBoardHasGPIO() {
 			LCDBackLightTimerEnabled = false
 		}
 
@@ -1038,18 +1286,24 @@ func printxmlconfig() {
 	}
 
 	if Config.Global.Software.PrintVariables.PrintSMTP {
-		log.Println("info: --------  Gmail SMTP Settings  -------- ")
+		log.Println("info: --------  SMTP Settings  -------- ")
 		log.Println("info: Email Enabled   " + fmt.Sprintf("%t", Config.Global.Software.SMTP.Enabled))
+		log.Println("info: Host            " + Config.Global.Software.SMTP.Host)
+		log.Println("info: Port            " + fmt.Sprintf("%v", Config.Global.Software.SMTP.Port))
+		log.Println("info: Security        " + Config.Global.Software.SMTP.Security)
+		log.Println("info: Skip Verify     " + fmt.Sprintf("%t", Config.Global.Software.SMTP.SkipVerify))
+		log.Println("info: Auth            " + Config.Global.Software.SMTP.Auth)
 		log.Println("info: Username        " + Config.Global.Software.SMTP.Username)
 		log.Println("info: Password        " + Config.Global.Software.SMTP.Password)
-		log.Println("info: Receiver        " + Config.Global.Software.SMTP.Receiver)
+		log.Println("info: From            " + Config.Global.Software.SMTP.From)
+		log.Println("info: Receiver        " + strings.Join(Config.Global.Software.SMTP.Receiver, ", "))
 		log.Println("info: Subject         " + Config.Global.Software.SMTP.Subject)
 		log.Println("info: Message         " + Config.Global.Software.SMTP.Message)
 		log.Println("info: GPS Date/Time   " + fmt.Sprintf("%t", Config.Global.Software.SMTP.GpsDateTime))
 		log.Println("info: GPS Lat/Long    " + fmt.Sprintf("%t", Config.Global.Software.SMTP.GpsLatLong))
 		log.Println("info: Google Maps URL " + fmt.Sprintf("%t", Config.Global.Software.SMTP.GoogleMapsURL))
 	} else {
-		log.Println("info: --------   Gmail SMTP Settings  -------- SKIPPED ")
+		log.Println("info: --------   SMTP Settings  -------- SKIPPED ")
 	}
 
 	if Config.Global.Software.PrintVariables.PrintSounds {
@@ -1107,6 +1361,15 @@ func printxmlconfig() {
 		for _, command := range Config.Global.Software.RemoteControl.MQTT.Commands.Command {
 			log.Printf("info: Enabled=%v Action=%v Message=%v\n", command.Enabled, command.Action, command.Message)
 		}
+		log.Println("info: ------------ Mumble Chat Commands ------- ")
+		log.Println("info: Enabled             " + fmt.Sprintf("%v", Config.Global.Software.RemoteControl.Mumble.Enabled))
+		log.Println("info: Prefix              " + fmt.Sprintf("%v", chatCommandPrefix()))
+		log.Println("info: Authorised Users    " + fmt.Sprintf("%v", Config.Global.Software.RemoteControl.Mumble.Authorise.User))
+		log.Println("info: Authorised Hashes   " + fmt.Sprintf("%v", Config.Global.Software.RemoteControl.Mumble.Authorise.CertHash))
+		log.Println("info: Authorised Groups   " + fmt.Sprintf("%v", Config.Global.Software.RemoteControl.Mumble.Authorise.Group))
+		for _, command := range Config.Global.Software.RemoteControl.Mumble.Command {
+			log.Printf("info: Enabled=%v Action=%v Message=%v\n", command.Enabled, command.Action, command.Message)
+		}
 	} else {
 		log.Println("info: ------------ MQTT Function ------- SKIPPED ")
 	}
@@ -1127,6 +1390,12 @@ func printxmlconfig() {
 		log.Println("info: TTSGPIOName                  " + fmt.Sprintf("%v", Config.Global.Software.TTSMessages.GPIO.Name))
 		log.Println("info: TTSPreDelay                  " + fmt.Sprintf("%v", Config.Global.Software.TTSMessages.PreDelay))
 		log.Println("info: TTSPostDelay                 " + fmt.Sprintf("%v", Config.Global.Software.TTSMessages.PreDelay))
+		log.Println("info: Message Store Size           " + fmt.Sprintf("%v", Config.Global.Software.MessageStore.Size))
+		log.Println("info: Message Store Unread LED     " + fmt.Sprintf("%v", Config.Global.Software.MessageStore.UnreadLED))
+		log.Println("info: Message Forward Enabled      " + fmt.Sprintf("%v", Config.Global.Software.MessageForward.Enabled))
+		for _, forwarder := range Config.Global.Software.MessageForward.Forwarder {
+			log.Printf("info: Forwarder Name=%v Type=%v Enabled=%v SenderRegex=%v Channel=%v Scope=%v Topic=%v URL=%v\n", forwarder.Name, forwarder.Type, forwarder.Enabled, forwarder.SenderRegex, forwarder.Channel, forwarder.Scope, forwarder.Topic, forwarder.URL)
+		}
 	} else {
 		log.Println("info: ------------ TTSMessages Function ------- SKIPPED ")
 	}
@@ -1142,7 +1411,19 @@ func printxmlconfig() {
 	if Config.Global.Software.PrintVariables.PrintHardware {
 		log.Println("info: ------------  Hardware Settings -------------- ")
 		log.Println("info: Target Board                 " + fmt.Sprintf("%v", Config.Global.Hardware.TargetBoard))
+		log.Println("info: GPIO Backend                 " + fmt.Sprintf("%v", Config.Global.Hardware.IO.GPIOBackend.Name))
+		log.Println("info: GPIO Backend Chip            " + fmt.Sprintf("%v", Config.Global.Hardware.IO.GPIOBackend.Chip))
+		log.Println("info: GPIO Backend Debounce msecs  " + fmt.Sprintf("%v", Config.Global.Hardware.IO.GPIOBackend.DebounceMsecs))
 		log.Println("info: LED Strip Enabled            " + fmt.Sprintf("%v", Config.Global.Hardware.LedStripEnabled))
+		log.Println("info: LED Strip SPI Port           " + fmt.Sprintf("%v", Config.Global.Hardware.LedStrip.SPIPort))
+		log.Println("info: LED Strip Pixels             " + fmt.Sprintf("%v", Config.Global.Hardware.LedStrip.Pixels))
+		log.Println("info: LED Strip Brightness         " + fmt.Sprintf("%v", Config.Global.Hardware.LedStrip.Brightness))
+		for _, state := range Config.Global.Hardware.LedStrip.State {
+			log.Printf("info: LED Strip State Name=%v Pixels=%v Colour=%v Pattern=%v Priority=%v Enabled=%v\n", state.Name, state.Pixels, state.Colour, state.Pattern, state.Priority, state.Enabled)
+		}
+		for _, channel := range Config.Global.Hardware.LedStrip.Channel {
+			log.Printf("info: LED Strip Channel Name=%v Colour=%v\n", channel.Name, channel.Colour)
+		}
 		log.Println("info: VoiceActivity LED Timer (ms) " + fmt.Sprintf("%v", Config.Global.Hardware.VoiceActivityTimermsecs))
 	} else {
 		log.Println("info: ------------  Hardware Settings -------------- SKIPPED")
@@ -1172,7 +1453,7 @@ func printxmlconfig() {
 	if Config.Global.Software.PrintVariables.PrintPins {
 		log.Println("info: ------------  PINS -------------- ")
 		for _, pins := range Config.Global.Hardware.IO.Pins.Pin {
-			log.Printf("info: Direction=%v Device%v Name=%v PinNo=%v Type=%v ID=%v Enabled=%v\n", pins.Direction, pins.Device, pins.Name, pins.PinNo, pins.Type, pins.ID, pins.Enabled)
+			log.Printf("info: Direction=%v Device%v Name=%v PinNo=%v Type=%v ID=%v Action=%v ParamName=%v ParamValue=%v Enabled=%v\n", pins.Direction, pins.Device, pins.Name, pins.PinNo, pins.Type, pins.ID, pins.Action, pins.ParamName, pins.ParamValue, pins.Enabled)
 		}
 	} else {
 		log.Println("info: ------------  PINS -------------- SKIPPED")
@@ -1183,6 +1464,7 @@ func printxmlconfig() {
 		for _, control := range Config.Global.Hardware.IO.RotaryEncoder.Control {
 			log.Printf("info: Enabled=%v Fuction=%v\n", control.Enabled, control.Function)
 		}
+		log.Printf("info: Menu Rows=%v VoicePrompts=%v\n", Config.Global.Hardware.IO.RotaryEncoder.Menu.Rows, Config.Global.Hardware.IO.RotaryEncoder.Menu.VoicePrompts)
 	} else {
 		log.Println("info: ------------  PINS -------------- SKIPPED")
 	}
@@ -1205,6 +1487,15 @@ func printxmlconfig() {
 	}
 
 	if Config.Global.Software.PrintVariables.PrintHeartBeat {
+		log.Println("info: ------------ LED Patterns ---------------- ")
+		log.Println("info: LED Patterns Enabled " + fmt.Sprintf("%v", Config.Global.Hardware.LEDPatterns.Enabled))
+		for _, pattern := range Config.Global.Hardware.LEDPatterns.Pattern {
+			log.Printf("info: Pattern Name=%v Type=%v Sequence=%v Morse=%v UnitMsecs=%v PeriodMsecs=%v\n", pattern.Name, pattern.Type, pattern.Sequence, pattern.Morse, pattern.UnitMsecs, pattern.PeriodMsecs)
+		}
+		for _, state := range Config.Global.Hardware.LEDPatterns.State {
+			log.Printf("info: State Name=%v Output=%v StripLED=%v Colour=%v Pattern=%v Priority=%v Enabled=%v\n", state.Name, state.Output, state.StripLED, state.Colour, state.Pattern, state.Priority, state.Enabled)
+		}
+
 		log.Println("info: ---------- HEARTBEAT -------------------- ")
 		log.Println("info: HeartBeat Enabled " + fmt.Sprintf("%v", Config.Global.Hardware.HeartBeat.Enabled))
 		log.Println("info: Period  mSecs     " + fmt.Sprintf("%v", Config.Global.Hardware.HeartBeat.Periodmsecs))
@@ -1214,7 +1505,7 @@ func printxmlconfig() {
 
 	if Config.Global.Software.PrintVariables.PrintComment {
 		log.Println("info: ------------ Comment  ------------------- ")
-		log.Println("info: Comment Button Pin            " + fmt.Sprintf("%v", CommentButtonPin))
+		log.Println("info: Comment Button Pin            " + fmt.Sprintf("%v", Config.Global.Hardware.Comment.CommentButtonPin))
 		log.Println("info: Comment Message State 1 (off) " + fmt.Sprintf("%v", Config.Global.Hardware.Comment.CommentMessageOff))
 		log.Println("info: Comment Message State 2 (on)  " + fmt.Sprintf("%v", Config.Global.Hardware.Comment.CommentMessageOn))
 	} else {
@@ -1256,9 +1547,23 @@ func printxmlconfig() {
 		log.Println("info: ------------ OLED ----------------------- SKIPPED ")
 	}
 
+	if Config.Global.Software.PrintVariables.PrintLCD || Config.Global.Software.PrintVariables.PrintOLED {
+		log.Println("info: ------------ Display Manager ------------ ")
+		log.Println("info: Enabled                 " + fmt.Sprintf("%v", Config.Global.Hardware.DisplayManager.Enabled))
+		for _, device := range Config.Global.Hardware.DisplayManager.Device {
+			log.Printf("info: Display Name=%v Type=%v Rows=%v Columns=%v Enabled=%v\n", device.Name, device.Type, device.Rows, device.Columns, device.Enabled)
+		}
+		for _, page := range Config.Global.Hardware.DisplayManager.Page {
+			for _, widget := range page.Widget {
+				log.Printf("info: Page Name=%v Display=%v Widget=%v Row=%v Rows=%v Label=%v\n", page.Name, page.Device, widget.Name, widget.Row, widget.Rows, widget.Label)
+			}
+		}
+	}
+
 	if Config.Global.Software.PrintVariables.PrintGPS {
 		log.Println("info: ------------ GPS  ------------------------ ")
 		log.Println("info: Enabled                " + fmt.Sprintf("%t", Config.Global.Hardware.GPS.Enabled))
+		log.Println("info: Source                 ", Config.Global.Hardware.GPS.Source)
 		log.Println("info: Port                   ", Config.Global.Hardware.GPS.Port)
 		log.Println("info: Baud                   " + fmt.Sprintf("%v", Config.Global.Hardware.GPS.Baud))
 		log.Println("info: TxData                 ", Config.Global.Hardware.GPS.TxData)
@@ -1272,6 +1577,13 @@ func printxmlconfig() {
 		log.Println("info: Char Time Out          " + fmt.Sprintf("%v", Config.Global.Hardware.GPS.CharTimeOut))
 		log.Println("info: Min Read               " + fmt.Sprintf("%v", Config.Global.Hardware.GPS.MinRead))
 		log.Println("info: Rx                     " + fmt.Sprintf("%t", Config.Global.Hardware.GPS.Rx))
+		log.Println("info: Stale Secs             " + fmt.Sprintf("%v", Config.Global.Hardware.GPS.StaleSecs))
+		log.Println("info: MQTT Publish Secs      " + fmt.Sprintf("%v", Config.Global.Hardware.GPS.MQTTPublishSecs))
+		log.Println("info: gpsd Host              ", Config.Global.Hardware.GPS.Gpsd.Host)
+		log.Println("info: gpsd Port              " + fmt.Sprintf("%v", Config.Global.Hardware.GPS.Gpsd.Port))
+		log.Println("info: Replay File            ", Config.Global.Hardware.GPS.Replay.File)
+		log.Println("info: Replay Speed           " + fmt.Sprintf("%v", Config.Global.Hardware.GPS.Replay.Speed))
+		log.Println("info: Replay Loop            " + fmt.Sprintf("%v", Config.Global.Hardware.GPS.Replay.Loop))
 	} else {
 		log.Println("info: ------------ GPS  ------------------------ SKIPPED ")
 	}
@@ -1282,11 +1594,63 @@ func printxmlconfig() {
 		log.Println("info: Track                 ", Config.Global.Hardware.Traccar.Track)
 		log.Println("info: ClientID              ", Config.Global.Hardware.Traccar.ClientId)
 		log.Println("info: Device Screen Enabled " + fmt.Sprintf("%t", Config.Global.Hardware.Traccar.DeviceScreenEnabled))
+		log.Println("info: Interval Secs         " + fmt.Sprintf("%v", Config.Global.Hardware.Traccar.IntervalSecs))
+		log.Println("info: Queue Enabled         " + fmt.Sprintf("%t", Config.Global.Hardware.Traccar.Queue.Enabled))
+		log.Println("info: Queue File            ", Config.Global.Hardware.Traccar.Queue.File)
+		log.Println("info: Queue Max Entries     " + fmt.Sprintf("%v", Config.Global.Hardware.Traccar.Queue.MaxEntries))
+		log.Println("info: Queue Max Age Secs    " + fmt.Sprintf("%v", Config.Global.Hardware.Traccar.Queue.MaxAgeSecs))
+		log.Println("info: Protocol              ", Config.Global.Hardware.Traccar.Protocol.Name)
+		if Config.Global.Hardware.Traccar.Protocol.Name == "aprs" {
+			log.Println("info: APRS-IS Server        ", Config.Global.Hardware.Traccar.Protocol.Aprs.Server+":"+Config.Global.Hardware.Traccar.Protocol.Aprs.Port)
+			log.Println("info: APRS Callsign SSID    ", Config.Global.Hardware.Traccar.Protocol.Aprs.Callsign, Config.Global.Hardware.Traccar.Protocol.Aprs.SSID)
+			log.Println("info: APRS Symbol           ", Config.Global.Hardware.Traccar.Protocol.Aprs.SymbolTable+Config.Global.Hardware.Traccar.Protocol.Aprs.Symbol)
+			log.Println("info: APRS Comment          ", Config.Global.Hardware.Traccar.Protocol.Aprs.Comment)
+			log.Printf("info: APRS Smart Beaconing   %+v\n", Config.Global.Hardware.Traccar.Protocol.Aprs.SmartBeaconing)
+		}
+		if Config.Global.Hardware.Traccar.Protocol.Name == "osmandjson" {
+			log.Println("info: JSON Server URL       ", Config.Global.Hardware.Traccar.Protocol.OsmandJSON.ServerURL+":"+Config.Global.Hardware.Traccar.Protocol.OsmandJSON.Port)
+			log.Println("info: JSON Battery File     ", Config.Global.Hardware.Traccar.Protocol.OsmandJSON.BatteryFile)
+			log.Printf("info: JSON Attributes       %+v\n", Config.Global.Hardware.Traccar.Protocol.OsmandJSON.Attribute)
+		}
 	} else {
 		log.Println("info: ------------ Traccar  ------------------------ SKIPPED")
 
 	}
 
+	if Config.Global.Software.PrintVariables.PrintGPS {
+		log.Println("info: ------------ Geofence ------------------------ ")
+		log.Println("info: Enabled               " + fmt.Sprintf("%t", Config.Global.Hardware.Geofence.Enabled))
+		log.Println("info: Confirm Fixes         " + fmt.Sprintf("%v", Config.Global.Hardware.Geofence.ConfirmFixes))
+		for _, fence := range Config.Global.Hardware.Geofence.Fence {
+			log.Printf("info: Fence %v Enabled %v Circle %+v Polygon %v\n", fence.Name, fence.Enabled, fence.Circle, fence.Polygon)
+			for _, action := range fence.Action {
+				log.Printf("info: Fence %v Action %+v\n", fence.Name, action)
+			}
+		}
+	} else {
+		log.Println("info: ------------ Geofence ------------------------ SKIPPED")
+	}
+
+	if Config.Global.Software.PrintVariables.PrintGPS {
+		log.Println("info: ------------ Track Log ----------------------- ")
+		log.Println("info: Enabled               " + fmt.Sprintf("%t", Config.Global.Hardware.TrackLog.Enabled))
+		log.Println("info: Format                ", Config.Global.Hardware.TrackLog.Format)
+		log.Println("info: Directory             ", Config.Global.Hardware.TrackLog.Directory)
+		log.Println("info: Interval Secs         " + fmt.Sprintf("%v", Config.Global.Hardware.TrackLog.IntervalSecs))
+		log.Println("info: Keep Days             " + fmt.Sprintf("%v", Config.Global.Hardware.TrackLog.KeepDays))
+	} else {
+		log.Println("info: ------------ Track Log ----------------------- SKIPPED")
+	}
+
+	if Config.Global.Software.PrintVariables.PrintGPS {
+		log.Println("info: ------------ Position Share ------------------ ")
+		log.Println("info: Enabled               " + fmt.Sprintf("%t", Config.Global.Hardware.PositionShare.Enabled))
+		log.Println("info: Interval Secs         " + fmt.Sprintf("%v", Config.Global.Hardware.PositionShare.IntervalSecs))
+		log.Println("info: Speak                 " + fmt.Sprintf("%t", Config.Global.Hardware.PositionShare.Speak))
+	} else {
+		log.Println("info: ------------ Position Share ------------------ SKIPPED")
+	}
+
 	if Config.Global.Software.PrintVariables.PrintPanic {
 		log.Println("info: ------------ PANIC Function -------------- ")
 		log.Println("info: Panic Function Enable          ", fmt.Sprintf("%t", Config.Global.Hardware.PanicFunction.Enabled))
@@ -1300,10 +1664,31 @@ func printxmlconfig() {
 		log.Println("info: Panic TX Lock Enabled          ", fmt.Sprintf("%t", Config.Global.Hardware.PanicFunction.TxLockEnabled))
 		log.Println("info: Panic TX Lock Timeout Secs     ", fmt.Sprintf("%v", Config.Global.Hardware.PanicFunction.TxLockEnabled))
 		log.Println("info: Panic Low Profile Lights Enable", fmt.Sprintf("%v", Config.Global.Hardware.PanicFunction.PLowProfile))
+		log.Println("info: Panic Arm Secs                 ", fmt.Sprintf("%v", Config.Global.Hardware.PanicFunction.ArmSecs))
+		log.Println("info: Panic Repeat Secs              ", fmt.Sprintf("%v", Config.Global.Hardware.PanicFunction.RepeatSecs))
+		log.Println("info: Panic Max Repeats              ", fmt.Sprintf("%v", Config.Global.Hardware.PanicFunction.MaxRepeats))
+		log.Println("info: Panic Ack Keyword              ", Config.Global.Hardware.PanicFunction.AckKeyword)
+		log.Println("info: Panic Cancel PIN               ", Config.Global.Hardware.PanicFunction.CancelPIN)
+		for _, escalation := range Config.Global.Hardware.PanicFunction.Escalation {
+			log.Printf("info: Panic Escalation Enabled=%v AfterSecs=%v VoiceTarget=%v Channel=%v Message=%v\n", escalation.Enabled, escalation.AfterSecs, escalation.VoiceTarget, escalation.Channel, escalation.Message)
+		}
 	} else {
 		log.Println("info: ------------ PANIC Function -------------- SKIPPED ")
 	}
 
+	if Config.Global.Software.PrintVariables.PrintLoneWorker {
+		log.Println("info: ------------ Lone Worker Function -------------- ")
+		log.Println("info: Lone Worker Enabled            ", fmt.Sprintf("%t", Config.Global.Hardware.LoneWorker.Enabled))
+		log.Println("info: Lone Worker Interval Secs      ", fmt.Sprintf("%v", Config.Global.Hardware.LoneWorker.IntervalSecs))
+		log.Println("info: Lone Worker Grace Secs         ", fmt.Sprintf("%v", Config.Global.Hardware.LoneWorker.GraceSecs))
+		log.Println("info: Lone Worker Prompt             ", Config.Global.Hardware.LoneWorker.Prompt)
+		log.Println("info: Lone Worker Open Mic Secs      ", fmt.Sprintf("%v", Config.Global.Hardware.LoneWorker.OpenMicSecs))
+		log.Println("info: Lone Worker Report MQTT        ", fmt.Sprintf("%t", Config.Global.Hardware.LoneWorker.ReportMQTT))
+		log.Println("info: Lone Worker Report Email       ", fmt.Sprintf("%t", Config.Global.Hardware.LoneWorker.ReportEmail))
+	} else {
+		log.Println("info: ------------ Lone Worker Function -------------- SKIPPED ")
+	}
+
 	if Config.Global.Software.PrintVariables.PrintUSBKeyboard {
 		log.Println("info: ------------ USBKeyboard Function -------------- ")
 		log.Println("info: USBKeyboardEnabled", Config.Global.Hardware.USBKeyboard.Enabled)
@@ -1355,6 +1740,20 @@ func printxmlconfig() {
 	if Config.Global.Software.PrintVariables.PrintRadioModule {
 		log.Println("info: ------------ RadioModule Function -------------- ")
 		log.Println("info: Radio  Enabled     " + fmt.Sprintf("%v", Config.Global.Hardware.Radio.Enabled))
+		log.Println("info: Radio  Driver      " + fmt.Sprintf("%v", Config.Global.Hardware.Radio.Driver))
+		log.Println("info: Rigctld Host Port  " + fmt.Sprintf("%v:%v", Config.Global.Hardware.Radio.Rigctld.Host, Config.Global.Hardware.Radio.Rigctld.Port))
+		log.Println("info: Scan   Enabled     " + fmt.Sprintf("%v", Config.Global.Hardware.Radio.Scan.Enabled))
+		log.Println("info: Scan   On Connect  " + fmt.Sprintf("%v", Config.Global.Hardware.Radio.Scan.StartOnConnect))
+		log.Println("info: Scan   Detect      " + fmt.Sprintf("%v", Config.Global.Hardware.Radio.Scan.Detect))
+		log.Println("info: Scan   Dwell Msecs " + fmt.Sprintf("%v", Config.Global.Hardware.Radio.Scan.DwellMsecs))
+		log.Println("info: Scan   Hang Secs   " + fmt.Sprintf("%v", Config.Global.Hardware.Radio.Scan.HangSecs))
+		log.Println("info: Scan   RSSI Thresh " + fmt.Sprintf("%v", Config.Global.Hardware.Radio.Scan.RSSIThreshold))
+		log.Println("info: Scan   Priority ID " + fmt.Sprintf("%v", Config.Global.Hardware.Radio.Scan.PriorityChannelID))
+		log.Println("info: Scan   Priority ms " + fmt.Sprintf("%v", Config.Global.Hardware.Radio.Scan.PriorityMsecs))
+		log.Println("info: Scan   Lockout     " + fmt.Sprintf("%v", Config.Global.Hardware.Radio.Scan.Lockout))
+		log.Println("info: Import Enabled     " + fmt.Sprintf("%v", Config.Global.Hardware.Radio.Import.Enabled))
+		log.Println("info: Import Write       " + fmt.Sprintf("%v", Config.Global.Hardware.Radio.Import.Write))
+		log.Println("info: Import Freq Range  " + fmt.Sprintf("%v-%vMhz", Config.Global.Hardware.Radio.Import.MinFreq, Config.Global.Hardware.Radio.Import.MaxFreq))
 		log.Println("info: SA818  Enabled     " + fmt.Sprintf("%v", Config.Global.Hardware.Radio.Sa818.Enabled))
 		log.Println("info: SA818  PD Enabled  " + fmt.Sprintf("%v", Config.Global.Hardware.Radio.Sa818.PDEnabled))
 		log.Println("info: Connect Channel ID " + fmt.Sprintf("%v", Config.Global.Hardware.Radio.ConnectChannelID))
@@ -1499,12 +1898,63 @@ func CheckConfigSanity(reloadxml bool) {
 		}
 	}
 
+	if Config.Global.Hardware.PanicFunction.Enabled {
+		if Config.Global.Hardware.PanicFunction.RepeatSecs > 0 && Config.Global.Hardware.PanicFunction.RepeatSecs < 10 {
+			log.Print("warn: Config Error [Section PanicFunction] RepeatSecs < 10 setting to 10")
+			Config.Global.Hardware.PanicFunction.RepeatSecs = 10
+			Warnings++
+		}
+		if Config.Global.Hardware.PanicFunction.RepeatSecs > 0 && len(Config.Global.Hardware.PanicFunction.AckKeyword) == 0 {
+			log.Print("warn: Config Error [Section PanicFunction] Repeating Alerts Without An AckKeyword Can Only Be Acknowledged Over MQTT Or HTTP")
+			Warnings++
+		}
+		for index, escalation := range Config.Global.Hardware.PanicFunction.Escalation {
+			if escalation.Enabled && escalation.VoiceTarget == 0 && len(escalation.Channel) == 0 && len(escalation.Message) == 0 {
+				log.Printf("warn: Config Error [Section PanicFunction] Escalation %v Has No VoiceTarget, Channel Or Message\n", index+1)
+				Config.Global.Hardware.PanicFunction.Escalation[index].Enabled = false
+				Warnings++
+			}
+			if escalation.VoiceTarget > 31 {
+				log.Printf("warn: Config Error [Section PanicFunction] Escalation %v VoiceTarget %v Not In Range (0-31)\n", index+1, escalation.VoiceTarget)
+				Config.Global.Hardware.PanicFunction.Escalation[index].Enabled = false
+				Warnings++
+			}
+		}
+	}
+
+	if Config.Global.Hardware.LoneWorker.Enabled {
+		if Config.Global.Hardware.LoneWorker.IntervalSecs < 60 {
+			log.Print("warn: Config Error [Section LoneWorker] IntervalSecs < 60 setting to 60")
+			Config.Global.Hardware.LoneWorker.IntervalSecs = 60
+			Warnings++
+		}
+		if Config.Global.Hardware.LoneWorker.GraceSecs < 10 {
+			log.Print("warn: Config Error [Section LoneWorker] GraceSecs < 10 setting to 10")
+			Config.Global.Hardware.LoneWorker.GraceSecs = 10
+			Warnings++
+		}
+		if !Config.Global.Hardware.PanicFunction.Enabled {
+			log.Print("warn: Config Error [Section LoneWorker] Panic Function Disabled, Missed Check Ins Will Only Be Reported")
+			Warnings++
+		}
+	}
+
 	if Config.Global.Software.SMTP.Enabled {
-		if len(Config.Global.Software.SMTP.Username) == 0 || len(Config.Global.Software.SMTP.Password) == 0 || len(Config.Global.Software.SMTP.Receiver) == 0 {
+		if (Config.Global.Software.SMTP.Auth != "none" && (len(Config.Global.Software.SMTP.Username) == 0 || len(Config.Global.Software.SMTP.Password) == 0)) || len(emailRecipients()) == 0 {
 			log.Print("warn: Config Error [Section SMTP] Some Parameters Not Defined Disabling SMTP")
 			Config.Global.Software.SMTP.Enabled = false
 			Warnings++
 		}
+		if !(Config.Global.Software.SMTP.Security == "" || Config.Global.Software.SMTP.Security == "starttls" || Config.Global.Software.SMTP.Security == "tls" || Config.Global.Software.SMTP.Security == "none") {
+			log.Printf("warn: Config Error [Section SMTP] Security %v Invalid Disabling SMTP\n", Config.Global.Software.SMTP.Security)
+			Config.Global.Software.SMTP.Enabled = false
+			Warnings++
+		}
+		if !(Config.Global.Software.SMTP.Auth == "" || Config.Global.Software.SMTP.Auth == "plain" || Config.Global.Software.SMTP.Auth == "login" || Config.Global.Software.SMTP.Auth == "crammd5" || Config.Global.Software.SMTP.Auth == "none") {
+			log.Printf("warn: Config Error [Section SMTP] Auth %v Invalid Disabling SMTP\n", Config.Global.Software.SMTP.Auth)
+			Config.Global.Software.SMTP.Enabled = false
+			Warnings++
+		}
 	}
 
 	if Config.Global.Hardware.VoiceActivityTimermsecs < 200 {
@@ -1513,6 +1963,18 @@ func CheckConfigSanity(reloadxml bool) {
 		Warnings++
 	}
 
+	if !(Config.Global.Hardware.IO.GPIOBackend.Name == "" || Config.Global.Hardware.IO.GPIOBackend.Name == "sysfs" || Config.Global.Hardware.IO.GPIOBackend.Name == "chardev" || Config.Global.Hardware.IO.GPIOBackend.Name == "simulated") {
+		log.Printf("warn: Config Error [Section GPIO] GPIO Backend %v Invalid Setting to sysfs\n", Config.Global.Hardware.IO.GPIOBackend.Name)
+		Config.Global.Hardware.IO.GPIOBackend.Name = "sysfs"
+		Warnings++
+	}
+
+	if Config.Global.Hardware.IO.GPIOBackend.Name == "chardev" && len(Config.Global.Hardware.IO.GPIOBackend.Chip) == 0 {
+		log.Print("warn: Config Error [Section GPIO] GPIO Backend chardev Has No Chip Setting to gpiochip0")
+		Config.Global.Hardware.IO.GPIOBackend.Chip = "gpiochip0"
+		Warnings++
+	}
+
 	for index, gpio := range Config.Global.Hardware.IO.Pins.Pin {
 		if gpio.Enabled {
 			if !(gpio.Direction == "input" || gpio.Direction == "output") {
@@ -1531,13 +1993,19 @@ func CheckConfigSanity(reloadxml bool) {
 				Warnings++
 			}
 
-			if !(gpio.Name == "voiceactivity" || gpio.Name == "participants" || gpio.Name == "transmit" || gpio.Name == "online" || gpio.Name == "attention" || gpio.Name == "voicetarget" || gpio.Name == "heartbeat" || gpio.Name == "backlight" || gpio.Name == "relay0" || gpio.Name == "txptt" || gpio.Name == "txtoggle" || gpio.Name == "channelup" || gpio.Name == "channeldown" || gpio.Name == "panic" || gpio.Name == "streamtoggle" || gpio.Name == "comment" || gpio.Name == "rotarya" || gpio.Name == "rotaryb" || gpio.Name == "rotarybutton" || gpio.Name == "volup" || gpio.Name == "voldown") {
+			if gpio.Direction == "input" && len(gpio.Action) > 0 {
+				if _, found := findInputCommand(gpio.Name, gpio.Action, gpio.ParamName, gpio.ParamValue); !found {
+					log.Printf("warn: Config Error [Section GPIO] Enabled Input GPIO Name %v Pin Number %v Invalid Action %v\n", gpio.Name, gpio.PinNo, gpio.Action)
+					Config.Global.Hardware.IO.Pins.Pin[index].Enabled = false
+					Warnings++
+				}
+			} else if !(gpio.Name == "voiceactivity" || gpio.Name == "participants" || gpio.Name == "transmit" || gpio.Name == "online" || gpio.Name == "attention" || gpio.Name == "voicetarget" || gpio.Name == "heartbeat" || gpio.Name == "backlight" || gpio.Name == "relay0" || gpio.Name == "txptt" || gpio.Name == "txtoggle" || gpio.Name == "channelup" || gpio.Name == "channeldown" || gpio.Name == "panic" || gpio.Name == "streamtoggle" || gpio.Name == "comment" || gpio.Name == "rotarya" || gpio.Name == "rotaryb" || gpio.Name == "rotarybutton" || gpio.Name == "volup" || gpio.Name == "voldown" || gpio.Name == "tracking" || gpio.Name == "mqtt0" || gpio.Name == "mqtt1" || gpio.Name == "nextserver" || gpio.Name == "repeatertone" || gpio.Name == "unread") {
 				log.Printf("warn: Config Error [Section GPIO] Enabled GPIO Name %v Pin Number %v Invalid Name\n", gpio.Name, gpio.PinNo)
 				Config.Global.Hardware.IO.Pins.Pin[index].Enabled = false
 				Warnings++
 			}
 
-			if gpio.PinNo < 2 || gpio.PinNo > 27 {
+			if Config.Global.Hardware.TargetBoard == "rpi" && (gpio.PinNo < 2 || gpio.PinNo > 27) {
 				log.Printf("warn: Config Error [Section GPIO] Enabled GPIO Name %v Pin Number %v Invalid GPIO Number\n", gpio.Name, gpio.PinNo)
 				Config.Global.Hardware.IO.Pins.Pin[index].Enabled = false
 				Warnings++
@@ -1634,7 +2102,50 @@ func CheckConfigSanity(reloadxml bool) {
 		}
 	}
 
-	if Config.Global.Hardware.GPS.Enabled {
+	if Config.Global.Hardware.DisplayManager.Enabled {
+		for index, device := range Config.Global.Hardware.DisplayManager.Device {
+			if device.Enabled && !(device.Type == "hd44780" || device.Type == "ssd1306" || device.Type == "max7219" || device.Type == "console") {
+				log.Printf("warn: Config Error [Section DisplayManager] Enabled Display %v Invalid Type %v\n", device.Name, device.Type)
+				Config.Global.Hardware.DisplayManager.Device[index].Enabled = false
+				Warnings++
+			}
+		}
+		for _, page := range Config.Global.Hardware.DisplayManager.Page {
+			for _, widget := range page.Widget {
+				var found bool
+				for _, name := range displayWidgetNames {
+					if widget.Name == name {
+						found = true
+					}
+				}
+				if !found {
+					log.Printf("warn: Config Error [Section DisplayManager] Page %v Invalid Widget %v\n", page.Name, widget.Name)
+					Warnings++
+				}
+			}
+		}
+	}
+
+	if Config.Global.Hardware.GPS.Enabled && !(Config.Global.Hardware.GPS.Source == "" || Config.Global.Hardware.GPS.Source == "serial" || Config.Global.Hardware.GPS.Source == "gpsd" || Config.Global.Hardware.GPS.Source == "replay") {
+		log.Printf("warn: Config Error [Section GPS] Enabled GPS Source %v Invalid\n", Config.Global.Hardware.GPS.Source)
+		Config.Global.Hardware.GPS.Enabled = false
+		Warnings++
+	}
+
+	if Config.Global.Hardware.GPS.Enabled && Config.Global.Hardware.GPS.Source == "replay" {
+		if !FileExists(Config.Global.Hardware.GPS.Replay.File) {
+			log.Printf("warn: Config Error [Section GPS] Enabled GPS Replay File %v Not Found\n", Config.Global.Hardware.GPS.Replay.File)
+			Config.Global.Hardware.GPS.Enabled = false
+			Warnings++
+		}
+		if Config.Global.Hardware.GPS.Replay.Speed < 0 {
+			log.Printf("warn: Config Error [Section GPS] Enabled GPS Replay Speed %v Invalid Defaulting to 1\n", Config.Global.Hardware.GPS.Replay.Speed)
+			Config.Global.Hardware.GPS.Replay.Speed = 1
+			Warnings++
+		}
+	}
+
+	if Config.Global.Hardware.GPS.Enabled && (Config.Global.Hardware.GPS.Source == "" || Config.Global.Hardware.GPS.Source == "serial") {
 		if !FileExists(Config.Global.Hardware.GPS.Port) {
 			log.Printf("warn: Config Error [Section GPS] Enabled GPS Port %v Invalid\n", Config.Global.Hardware.GPS.Port)
 			Config.Global.Hardware.GPS.Enabled = false
@@ -1665,6 +2176,164 @@ func CheckConfigSanity(reloadxml bool) {
 		}
 	}
 
+	if Config.Global.Hardware.Traccar.Enabled {
+		switch Config.Global.Hardware.Traccar.Protocol.Name {
+		case "osmand", "opengts", "t55", "osmandjson":
+		case "aprs":
+			if len(Config.Global.Hardware.Traccar.Protocol.Aprs.Server) == 0 || len(Config.Global.Hardware.Traccar.Protocol.Aprs.Callsign) == 0 {
+				log.Println("warn: Config Error [Section Traccar] Enabled APRS With Empty Server Or Callsign")
+				Config.Global.Hardware.Traccar.Enabled = false
+				Warnings++
+			}
+			if len(Config.Global.Hardware.Traccar.Protocol.Aprs.Passcode) == 0 {
+				log.Println("warn: Config Error [Section Traccar] APRS Passcode Empty Logging In Receive Only")
+				Warnings++
+			}
+			smart := Config.Global.Hardware.Traccar.Protocol.Aprs.SmartBeaconing
+			if smart.Enabled && (smart.FastSpeed <= smart.SlowSpeed || smart.SlowSpeed <= 0 || smart.FastRateSecs <= 0 || smart.SlowRateSecs <= 0) {
+				log.Println("warn: Config Error [Section Traccar] APRS Smart Beaconing Speeds Or Rates Invalid Disabling Smart Beaconing")
+				Config.Global.Hardware.Traccar.Protocol.Aprs.SmartBeaconing.Enabled = false
+				Warnings++
+			}
+		default:
+			log.Printf("warn: Config Error [Section Traccar] Enabled Traccar Protocol %v Invalid\n", Config.Global.Hardware.Traccar.Protocol.Name)
+			Config.Global.Hardware.Traccar.Enabled = false
+			Warnings++
+		}
+	}
+
+	if Config.Global.Hardware.Traccar.Enabled && Config.Global.Hardware.Traccar.Queue.Enabled {
+		if len(Config.Global.Hardware.Traccar.Queue.File) == 0 {
+			log.Println("warn: Config Error [Section Traccar] Enabled Queue With Empty File")
+			Config.Global.Hardware.Traccar.Queue.Enabled = false
+			Warnings++
+		} else if info, err := os.Stat(filepath.Dir(Config.Global.Hardware.Traccar.Queue.File)); err != nil || !info.IsDir() {
+			log.Printf("warn: Config Error [Section Traccar] Enabled Queue Directory %v Not Found\n", filepath.Dir(Config.Global.Hardware.Traccar.Queue.File))
+			Config.Global.Hardware.Traccar.Queue.Enabled = false
+			Warnings++
+		}
+		if Config.Global.Hardware.Traccar.Queue.MaxEntries < 0 || Config.Global.Hardware.Traccar.Queue.MaxAgeSecs < 0 {
+			log.Println("warn: Config Error [Section Traccar] Queue Max Entries And Max Age Secs Cannot Be Negative Setting No Limit")
+			Config.Global.Hardware.Traccar.Queue.MaxEntries = 0
+			Config.Global.Hardware.Traccar.Queue.MaxAgeSecs = 0
+			Warnings++
+		}
+	}
+
+	if Config.Global.Hardware.TrackLog.Enabled {
+		if !Config.Global.Hardware.GPS.Enabled {
+			log.Println("warn: Config Error [Section TrackLog] Enabled Track Log Needs GPS Enabled")
+			Config.Global.Hardware.TrackLog.Enabled = false
+			Warnings++
+		} else if info, err := os.Stat(Config.Global.Hardware.TrackLog.Directory); err != nil || !info.IsDir() {
+			log.Printf("warn: Config Error [Section TrackLog] Enabled Track Log Directory %v Not Found\n", Config.Global.Hardware.TrackLog.Directory)
+			Config.Global.Hardware.TrackLog.Enabled = false
+			Warnings++
+		}
+		if !(Config.Global.Hardware.TrackLog.Format == "gpx" || Config.Global.Hardware.TrackLog.Format == "csv") {
+			log.Printf("warn: Config Error [Section TrackLog] Track Log Format %v Invalid Defaulting to gpx\n", Config.Global.Hardware.TrackLog.Format)
+			Config.Global.Hardware.TrackLog.Format = "gpx"
+			Warnings++
+		}
+	}
+
+	if Config.Global.Hardware.Radio.Enabled && !(Config.Global.Hardware.Radio.Driver == "" || Config.Global.Hardware.Radio.Driver == "sa818" || Config.Global.Hardware.Radio.Driver == "sa868" || Config.Global.Hardware.Radio.Driver == "dra818" || Config.Global.Hardware.Radio.Driver == "rigctld") {
+		log.Printf("warn: Config Error [Section Radio] Enabled Radio Driver %v Invalid\n", Config.Global.Hardware.Radio.Driver)
+		Config.Global.Hardware.Radio.Enabled = false
+		Warnings++
+	}
+
+	if Config.Global.Hardware.Radio.Import.Enabled {
+		if !Config.Global.Software.RemoteControl.HTTP.Enabled {
+			log.Println("warn: Config Error [Section Radio] Enabled Radio Import Upload Needs HTTP Remote Control Enabled")
+			Config.Global.Hardware.Radio.Import.Enabled = false
+			Warnings++
+		}
+		if Config.Global.Hardware.Radio.Import.MaxFreq > 0 && Config.Global.Hardware.Radio.Import.MinFreq >= Config.Global.Hardware.Radio.Import.MaxFreq {
+			log.Println("warn: Config Error [Section Radio] Radio Import Min Freq Must Be Below Max Freq Defaulting To The Module Range")
+			Config.Global.Hardware.Radio.Import.MinFreq = 0
+			Config.Global.Hardware.Radio.Import.MaxFreq = 0
+			Warnings++
+		}
+	}
+
+	if Config.Global.Hardware.Radio.Scan.Enabled {
+		if !Config.Global.Hardware.Radio.Enabled {
+			log.Println("warn: Config Error [Section Radio] Enabled Radio Scan Needs Radio Enabled")
+			Config.Global.Hardware.Radio.Scan.Enabled = false
+			Warnings++
+		}
+		if !(Config.Global.Hardware.Radio.Scan.Detect == "rssi" || Config.Global.Hardware.Radio.Scan.Detect == "squelch") {
+			log.Printf("warn: Config Error [Section Radio] Radio Scan Detect %v Invalid Defaulting to rssi\n", Config.Global.Hardware.Radio.Scan.Detect)
+			Config.Global.Hardware.Radio.Scan.Detect = "rssi"
+			Warnings++
+		}
+		if Config.Global.Hardware.Radio.Scan.DwellMsecs <= 0 {
+			Config.Global.Hardware.Radio.Scan.DwellMsecs = defaultRadioScanDwellMsecs
+		}
+		if Config.Global.Hardware.Radio.Scan.HangSecs <= 0 {
+			Config.Global.Hardware.Radio.Scan.HangSecs = defaultRadioScanHangSecs
+		}
+		if Config.Global.Hardware.Radio.Scan.PriorityMsecs <= 0 {
+			Config.Global.Hardware.Radio.Scan.PriorityMsecs = defaultRadioScanPriorityMsecs
+		}
+		if len(Config.Global.Hardware.Radio.Scan.PriorityChannelID) > 0 {
+			if _, found := findChannelByID(Config.Global.Hardware.Radio.Scan.PriorityChannelID); !found {
+				log.Printf("warn: Config Error [Section Radio] Radio Scan Priority Channel ID %v Not An Enabled Channel\n", Config.Global.Hardware.Radio.Scan.PriorityChannelID)
+				Config.Global.Hardware.Radio.Scan.PriorityChannelID = ""
+				Warnings++
+			}
+		}
+	}
+
+	if Config.Global.Hardware.PositionShare.Enabled && !Config.Global.Hardware.GPS.Enabled {
+		log.Println("warn: Config Error [Section PositionShare] Enabled Position Share Needs GPS Enabled")
+		Config.Global.Hardware.PositionShare.Enabled = false
+		Warnings++
+	}
+
+	if Config.Global.Hardware.Geofence.Enabled && !Config.Global.Hardware.GPS.Enabled {
+		log.Println("warn: Config Error [Section Geofence] Enabled Geofence Needs GPS Enabled")
+		Config.Global.Hardware.Geofence.Enabled = false
+		Warnings++
+	}
+
+	for index, fence := range Config.Global.Hardware.Geofence.Fence {
+		if !Config.Global.Hardware.Geofence.Enabled || !fence.Enabled {
+			continue
+		}
+		polygon, err := geofenceParsePolygon(fence.Polygon)
+		if err != nil {
+			log.Printf("warn: Config Error [Section Geofence] Fence %v Polygon %v\n", fence.Name, err)
+			Config.Global.Hardware.Geofence.Fence[index].Enabled = false
+			Warnings++
+			continue
+		}
+		if len(polygon) == 0 && fence.Circle.Radius <= 0 {
+			log.Printf("warn: Config Error [Section Geofence] Fence %v Needs A Polygon Or A Circle With A Radius\n", fence.Name)
+			Config.Global.Hardware.Geofence.Fence[index].Enabled = false
+			Warnings++
+			continue
+		}
+		for actionIndex, action := range fence.Action {
+			if !action.Enabled {
+				continue
+			}
+			if !(action.Event == "" || action.Event == geofenceEnter || action.Event == geofenceExit) {
+				log.Printf("warn: Config Error [Section Geofence] Fence %v Action Event %v Invalid\n", fence.Name, action.Event)
+				Config.Global.Hardware.Geofence.Fence[index].Action[actionIndex].Enabled = false
+				Warnings++
+			}
+			switch action.Type {
+			case "channel", "voicetarget", "radiochannel", "tts", "mqtt", "webhook":
+			default:
+				log.Printf("warn: Config Error [Section Geofence] Fence %v Action Type %v Invalid\n", fence.Name, action.Type)
+				Config.Global.Hardware.Geofence.Fence[index].Action[actionIndex].Enabled = false
+				Warnings++
+			}
+		}
+	}
+
 	if Config.Global.Software.RemoteControl.MQTT.Enabled {
 
 		if len(Config.Global.Software.RemoteControl.MQTT.Settings.MQTTSubTopic) == 0 {
@@ -1695,6 +2364,55 @@ func CheckConfigSanity(reloadxml bool) {
 
 	}
 
+	if Config.Global.Software.RemoteControl.Mumble.Enabled {
+		authorise := Config.Global.Software.RemoteControl.Mumble.Authorise
+		if len(authorise.User)+len(authorise.CertHash)+len(authorise.Group) == 0 {
+			log.Println("warn: Config Error [Section Mumble Chat Commands] Enabled Chat Commands Without Any Authorised User, CertHash Or Group")
+			Config.Global.Software.RemoteControl.Mumble.Enabled = false
+			Warnings++
+		}
+		for index, command := range Config.Global.Software.RemoteControl.Mumble.Command {
+			var valid bool
+			for _, action := range chatCommands {
+				if command.Action == action {
+					valid = true
+				}
+			}
+			if command.Enabled && !valid {
+				log.Printf("warn: Config Error [Section Mumble Chat Commands] Enabled Command %v Invalid\n", command.Action)
+				Config.Global.Software.RemoteControl.Mumble.Command[index].Enabled = false
+				Warnings++
+			}
+		}
+	}
+
+	for index, forwarder := range Config.Global.Software.MessageForward.Forwarder {
+		if !forwarder.Enabled {
+			continue
+		}
+		if !(forwarder.Type == "mqtt" || forwarder.Type == "webhook" || forwarder.Type == "email") {
+			log.Printf("warn: Config Error [Section MessageForward] Forwarder %v Type %v Invalid\n", forwarder.Name, forwarder.Type)
+			Config.Global.Software.MessageForward.Forwarder[index].Enabled = false
+			Warnings++
+			continue
+		}
+		if forwarder.Type == "webhook" && len(forwarder.URL) == 0 {
+			log.Printf("warn: Config Error [Section MessageForward] Webhook Forwarder %v With Empty URL\n", forwarder.Name)
+			Config.Global.Software.MessageForward.Forwarder[index].Enabled = false
+			Warnings++
+		}
+		if !(forwarder.Scope == "" || forwarder.Scope == "all" || forwarder.Scope == "private" || forwarder.Scope == "channel") {
+			log.Printf("warn: Config Error [Section MessageForward] Forwarder %v Scope %v Invalid\n", forwarder.Name, forwarder.Scope)
+			Config.Global.Software.MessageForward.Forwarder[index].Enabled = false
+			Warnings++
+		}
+		if _, err := regexp.Compile(forwarder.SenderRegex); err != nil {
+			log.Printf("warn: Config Error [Section MessageForward] Forwarder %v SenderRegex %v Invalid\n", forwarder.Name, forwarder.SenderRegex)
+			Config.Global.Software.MessageForward.Forwarder[index].Enabled = false
+			Warnings++
+		}
+	}
+
 	if Config.Global.Software.IgnoreUser.IgnoreUserEnabled {
 		if len(Config.Global.Software.IgnoreUser.IgnoreUserRegex) < 4 {
 			log.Printf("warn: Config Error [Section ignoreuser]  %v Invalid Regex\n", Config.Global.Software.IgnoreUser.IgnoreUserRegex)
@@ -1704,7 +2422,7 @@ func CheckConfigSanity(reloadxml bool) {
 
 	for index, keyboard := range Config.Global.Hardware.Keyboard.Command {
 		if keyboard.Enabled {
-			if !(keyboard.Action == "channelup" || keyboard.Action == "channeldown" || keyboard.Action == "serverup" || keyboard.Action == "serverdown" || keyboard.Action == "mute" || keyboard.Action == "unmute" || keyboard.Action == "mute-toggle" || keyboard.Action == "stream-toggle" || keyboard.Action == "volumeup" || keyboard.Action == "volumedown" || keyboard.Action == "setcomment" || keyboard.Action == "transmitstart" || keyboard.Action == "transmitstop" || keyboard.Action == "record" || keyboard.Action == "voicetargetset" || keyboard.Action == "volup" || keyboard.Action == "voldown" || keyboard.Action == "mqttpubpayloadset") {
+			if !(keyboard.Action == "channelup" || keyboard.Action == "channeldown" || keyboard.Action == "serverup" || keyboard.Action == "serverdown" || keyboard.Action == "mute" || keyboard.Action == "unmute" || keyboard.Action == "mute-toggle" || keyboard.Action == "stream-toggle" || keyboard.Action == "volumeup" || keyboard.Action == "volumedown" || keyboard.Action == "setcomment" || keyboard.Action == "transmitstart" || keyboard.Action == "transmitstop" || keyboard.Action == "record" || keyboard.Action == "voicetargetset" || keyboard.Action == "volup" || keyboard.Action == "voldown" || keyboard.Action == "mqttpubpayloadset" || keyboard.Action == "messageolder" || keyboard.Action == "messagenewer" || keyboard.Action == "messagescroll") {
 				log.Printf("warn: Config Error [Section Keyboard] Enabled Keyboard Action %v Invalid\n", keyboard.Action)
 				Config.Global.Hardware.Keyboard.Command[index].Enabled = false
 				Warnings++